@@ -0,0 +1,401 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"pr-reviewer-service/internal/domain"
+	"pr-reviewer-service/internal/snapshot"
+)
+
+// teamFile is the seed format. Members and settings use the same field names
+// as the HTTP API.
+type teamFile struct {
+	Teams []teamRecord `json:"teams"`
+}
+
+type teamRecord struct {
+	TeamName string          `json:"team_name"`
+	Members  []memberRecord  `json:"members"`
+	Settings *settingsRecord `json:"settings,omitempty"`
+}
+
+type memberRecord struct {
+	UserID         string `json:"user_id"`
+	Username       string `json:"username"`
+	IsActive       bool   `json:"is_active"`
+	SlackHandle    string `json:"slack_handle,omitempty"`
+	MaxOpenReviews int    `json:"max_open_reviews,omitempty"`
+}
+
+type settingsRecord struct {
+	ReviewersCount    int      `json:"reviewers_count"`
+	Strategy          string   `json:"strategy"`
+	MinPoolSize       int      `json:"min_pool_size"`
+	FallbackTeams     []string `json:"fallback_teams,omitempty"`
+	SlackWebhookURL   string   `json:"slack_webhook_url,omitempty"`
+	RequiredApprovals int      `json:"required_approvals,omitempty"`
+}
+
+func runMigrate(args []string) error {
+	if len(args) != 1 || (args[0] != "up" && args[0] != "status") {
+		return errors.New("usage: migrate up|status")
+	}
+
+	db, err := openDB()
+	if err != nil {
+		return err
+	}
+	defer func() {
+		_ = db.Close()
+	}()
+
+	ctx, cancel := commandContext()
+	defer cancel()
+
+	if args[0] == "up" {
+		if err := db.migrate(ctx); err != nil {
+			return err
+		}
+	}
+
+	statuses, err := db.migrationStatus(ctx)
+	if err != nil {
+		return err
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 4, 2, ' ', 0)
+	_, _ = fmt.Fprintln(w, "VERSION\tNAME\tAPPLIED")
+	for _, s := range statuses {
+		applied := "pending"
+		if s.AppliedAt != nil {
+			applied = s.AppliedAt.UTC().Format(time.RFC3339)
+		}
+		_, _ = fmt.Fprintf(w, "%03d\t%s\t%s\n", s.Version, s.Name, applied)
+	}
+	return w.Flush()
+}
+
+func runSeed(args []string) error {
+	fs := flag.NewFlagSet("seed", flag.ExitOnError)
+	path := fs.String("file", "", "JSON file with teams to create")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if *path == "" {
+		return errors.New("--file is required")
+	}
+
+	file, err := readTeamFile(*path)
+	if err != nil {
+		return err
+	}
+
+	db, err := openDB()
+	if err != nil {
+		return err
+	}
+	defer func() {
+		_ = db.Close()
+	}()
+
+	ctx, cancel := commandContext()
+	defer cancel()
+
+	svc := newServices(db)
+	var created []teamRecord
+	for _, t := range file.Teams {
+		_, err := svc.teams.AddTeam(ctx, domain.TeamName(t.TeamName), toUsers(t.Members))
+		if errors.Is(err, domain.ErrTeamExists) {
+			log.Printf("team %s already exists, skipped", t.TeamName)
+			continue
+		}
+		if err != nil {
+			return fmt.Errorf("team %s: %w", t.TeamName, err)
+		}
+		created = append(created, t)
+	}
+
+	// Settings go last so fallback teams defined later in the file exist.
+	if err := applySettings(ctx, svc, created); err != nil {
+		return err
+	}
+
+	log.Printf("seeded %d of %d teams", len(created), len(file.Teams))
+	return nil
+}
+
+func runExport(args []string) error {
+	fs := flag.NewFlagSet("export", flag.ExitOnError)
+	path := fs.String("out", "", "output file (default stdout)")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	db, err := openDB()
+	if err != nil {
+		return err
+	}
+	defer func() {
+		_ = db.Close()
+	}()
+
+	ctx, cancel := commandContext()
+	defer cancel()
+
+	snap, err := newServices(db).snapshots.Export(ctx)
+	if err != nil {
+		return err
+	}
+
+	if *path == "" {
+		return snapshot.Encode(os.Stdout, snap)
+	}
+
+	f, err := os.Create(*path)
+	if err != nil {
+		return err
+	}
+	if err := snapshot.Encode(f, snap); err != nil {
+		_ = f.Close()
+		return err
+	}
+	// A backup that did not reach the disk must fail the command.
+	if err := f.Sync(); err != nil {
+		_ = f.Close()
+		return fmt.Errorf("sync %s: %w", *path, err)
+	}
+	if err := f.Close(); err != nil {
+		return fmt.Errorf("close %s: %w", *path, err)
+	}
+	return nil
+}
+
+func runImport(args []string) error {
+	fs := flag.NewFlagSet("import", flag.ExitOnError)
+	path := fs.String("file", "", "snapshot produced by export")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if *path == "" {
+		return errors.New("--file is required")
+	}
+
+	f, err := os.Open(*path)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		_ = f.Close()
+	}()
+
+	snap, err := snapshot.Decode(f)
+	if err != nil {
+		return fmt.Errorf("%s: %w", *path, err)
+	}
+
+	db, err := openDB()
+	if err != nil {
+		return err
+	}
+	defer func() {
+		_ = db.Close()
+	}()
+
+	ctx, cancel := commandContext()
+	defer cancel()
+
+	report, err := newServices(db).snapshots.Restore(ctx, snap)
+	if errors.Is(err, snapshot.ErrConflict) {
+		for _, c := range report.Conflicts {
+			_, _ = fmt.Fprintln(os.Stderr, c)
+		}
+		return fmt.Errorf("%d conflicts, nothing imported", len(report.Conflicts))
+	}
+	if err != nil {
+		return err
+	}
+
+	log.Printf("imported %d teams (%d new), %d users and %d pull requests",
+		report.TeamsCreated+report.TeamsUpdated, report.TeamsCreated, report.Users, report.PullRequests)
+	return nil
+}
+
+func runReassign(args []string) error {
+	fs := flag.NewFlagSet("reassign", flag.ExitOnError)
+	users := fs.String("user", "", "comma-separated user ids to deactivate and reassign")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	var ids []domain.UserID
+	for _, id := range strings.Split(*users, ",") {
+		if id = strings.TrimSpace(id); id != "" {
+			ids = append(ids, domain.UserID(id))
+		}
+	}
+	if len(ids) == 0 {
+		return errors.New("--user is required")
+	}
+
+	db, err := openDB()
+	if err != nil {
+		return err
+	}
+	defer func() {
+		_ = db.Close()
+	}()
+
+	ctx, cancel := commandContext()
+	defer cancel()
+
+	if err := newServices(db).prs.BulkDeactivateAndReassign(ctx, ids); err != nil {
+		return err
+	}
+
+	log.Printf("deactivated %d users and reassigned their open reviews", len(ids))
+	return nil
+}
+
+func readTeamFile(path string) (teamFile, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return teamFile{}, err
+	}
+
+	var file teamFile
+	if err := json.Unmarshal(data, &file); err != nil {
+		return teamFile{}, fmt.Errorf("parse %s: %w", path, err)
+	}
+	for _, t := range file.Teams {
+		if t.TeamName == "" {
+			return teamFile{}, fmt.Errorf("parse %s: team_name is required", path)
+		}
+	}
+	return file, nil
+}
+
+func applySettings(ctx context.Context, svc services, teams []teamRecord) error {
+	for _, t := range teams {
+		if t.Settings == nil {
+			continue
+		}
+
+		fallbacks := make([]domain.TeamName, 0, len(t.Settings.FallbackTeams))
+		for _, f := range t.Settings.FallbackTeams {
+			fallbacks = append(fallbacks, domain.TeamName(f))
+		}
+
+		if _, err := svc.teams.UpdateSettings(ctx, domain.TeamSettings{
+			TeamName:          domain.TeamName(t.TeamName),
+			ReviewersCount:    t.Settings.ReviewersCount,
+			Strategy:          domain.ReviewerStrategy(t.Settings.Strategy),
+			MinPoolSize:       t.Settings.MinPoolSize,
+			FallbackTeams:     fallbacks,
+			SlackWebhookURL:   t.Settings.SlackWebhookURL,
+			RequiredApprovals: t.Settings.RequiredApprovals,
+		}); err != nil {
+			return fmt.Errorf("team %s settings: %w", t.TeamName, err)
+		}
+	}
+	return nil
+}
+
+func toUsers(members []memberRecord) []domain.User {
+	users := make([]domain.User, 0, len(members))
+	for _, m := range members {
+		users = append(users, domain.User{
+			ID:             domain.UserID(m.UserID),
+			Username:       m.Username,
+			IsActive:       m.IsActive,
+			SlackHandle:    m.SlackHandle,
+			MaxOpenReviews: m.MaxOpenReviews,
+		})
+	}
+	return users
+}
+
+func runAPIKey(args []string) error {
+	if len(args) == 0 {
+		return errors.New("usage: apikey create|list|revoke [flags]")
+	}
+	sub, args := args[0], args[1:]
+
+	fs := flag.NewFlagSet("apikey "+sub, flag.ExitOnError)
+	name := fs.String("name", "", "what the key is for")
+	role := fs.String("role", "", "admin, team_lead or read_only")
+	team := fs.String("team", "", "team a team_lead key manages")
+	id := fs.Int64("id", 0, "key to revoke")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	db, err := openDB()
+	if err != nil {
+		return err
+	}
+	defer func() {
+		_ = db.Close()
+	}()
+
+	ctx, cancel := commandContext()
+	defer cancel()
+
+	keys := newServices(db).apiKeys
+	switch sub {
+	case "create":
+		key, secret, err := keys.Create(ctx, *name, domain.Role(*role), domain.TeamName(*team))
+		if errors.Is(err, domain.ErrInvalidAPIKey) {
+			return errors.New("--name and a valid --role are required; --team goes with team_lead only")
+		}
+		if err != nil {
+			return err
+		}
+		log.Printf("created api key %d (%s); store the secret now, it is not shown again", key.ID, key.Role)
+		fmt.Println(secret)
+		return nil
+	case "list":
+		list, err := keys.List(ctx)
+		if err != nil {
+			return err
+		}
+
+		w := tabwriter.NewWriter(os.Stdout, 0, 4, 2, ' ', 0)
+		_, _ = fmt.Fprintln(w, "ID\tNAME\tROLE\tTEAM\tCREATED\tREVOKED")
+		for _, k := range list {
+			revoked := "-"
+			if k.RevokedAt != nil {
+				revoked = k.RevokedAt.UTC().Format(time.RFC3339)
+			}
+			team := string(k.TeamName)
+			if team == "" {
+				team = "-"
+			}
+			_, _ = fmt.Fprintf(w, "%d\t%s\t%s\t%s\t%s\t%s\n",
+				k.ID, k.Name, k.Role, team, k.CreatedAt.UTC().Format(time.RFC3339), revoked)
+		}
+		return w.Flush()
+	case "revoke":
+		if *id == 0 {
+			return errors.New("--id is required")
+		}
+		if err := keys.Revoke(ctx, *id); err != nil {
+			if errors.Is(err, domain.ErrNotFound) {
+				return fmt.Errorf("no active api key %d", *id)
+			}
+			return err
+		}
+		log.Printf("revoked api key %d", *id)
+		return nil
+	default:
+		return fmt.Errorf("unknown apikey command %q", sub)
+	}
+}
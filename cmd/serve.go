@@ -0,0 +1,211 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"log"
+	"log/slog"
+	"net/http"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"pr-reviewer-service/internal/domain"
+	apphttp "pr-reviewer-service/internal/http"
+	"pr-reviewer-service/internal/metrics"
+	"pr-reviewer-service/internal/notify"
+	"pr-reviewer-service/internal/service"
+	"pr-reviewer-service/internal/tracing"
+	"pr-reviewer-service/internal/webhooks"
+)
+
+func runServe(args []string) error {
+	fs := flag.NewFlagSet("serve", flag.ExitOnError)
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	// Everything, including plain log.Printf calls, goes out as JSON lines.
+	logger := slog.New(slog.NewJSONHandler(os.Stderr, nil))
+	slog.SetDefault(logger)
+
+	shutdownTracing, err := tracing.Setup(context.Background(), tracing.Config{
+		Exporter:    os.Getenv("OTEL_TRACES_EXPORTER"),
+		File:        os.Getenv("TRACES_FILE"),
+		ServiceName: "pr-reviewer-service",
+	})
+	if err != nil {
+		return fmt.Errorf("set up tracing: %w", err)
+	}
+	defer func() {
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		if err := shutdownTracing(ctx); err != nil {
+			log.Printf("flush traces: %v", err)
+		}
+	}()
+
+	var drainDelay time.Duration
+	if v := os.Getenv("SHUTDOWN_DRAIN_DELAY"); v != "" {
+		if drainDelay, err = time.ParseDuration(v); err != nil {
+			return fmt.Errorf("parse SHUTDOWN_DRAIN_DELAY: %w", err)
+		}
+	}
+
+	port := os.Getenv("HTTP_PORT")
+	if port == "" {
+		port = "8080"
+	}
+
+	svc, closeStorage, err := openStorage()
+	if err != nil {
+		return err
+	}
+	defer closeStorage()
+
+	slackNotifier := notify.NewSlackNotifier(256)
+	svc.prs.Notifier = slackNotifier
+
+	reg := metrics.NewRegistry()
+	svc.prs.Metrics = metrics.NewPRMetrics(reg, svc.prs.Prs)
+	if svc.db != nil {
+		metrics.RegisterDBStats(reg, svc.db.conn)
+	}
+
+	mux := http.NewServeMux()
+	handler := apphttp.NewHandler(svc.teams, svc.users, svc.prs)
+	handler.EnableMetrics(reg)
+	if svc.db != nil {
+		handler.EnableReadiness(
+			apphttp.ReadinessCheck{Name: "database", Check: svc.db.conn.PingContext},
+			apphttp.ReadinessCheck{Name: "migrations", Check: svc.db.checkSchema},
+		)
+	}
+	if svc.webhookRepo != nil {
+		handler.EnableOutgoingWebhooks(service.NewWebhookService(svc.webhookRepo))
+	} else {
+		log.Println("outgoing webhooks are disabled: they need postgres storage")
+	}
+
+	identities, err := loadIdentities()
+	if err != nil {
+		return fmt.Errorf("load vcs identities: %w", err)
+	}
+	if secret := os.Getenv("GITHUB_WEBHOOK_SECRET"); secret != "" {
+		handler.EnableGitHubWebhook([]byte(secret), identities)
+	}
+	if token := os.Getenv("GITLAB_WEBHOOK_TOKEN"); token != "" {
+		handler.EnableGitLabWebhook([]byte(token), identities)
+	}
+	sso, err := loadSSO()
+	if err != nil {
+		return fmt.Errorf("load sso settings: %w", err)
+	}
+	switch {
+	case os.Getenv("AUTH_DISABLED") == "true":
+		log.Println("authentication is disabled by AUTH_DISABLED; every endpoint is open")
+	default:
+		if svc.db == nil {
+			if err := printBootstrapKey(svc.apiKeys); err != nil {
+				return fmt.Errorf("issue bootstrap api key: %w", err)
+			}
+		}
+		handler.EnableAuth(svc.apiKeys)
+		if sso != nil {
+			handler.EnableSSO(sso)
+		}
+	}
+	handler.RegisterRoutes(mux)
+
+	server := &http.Server{
+		Addr:              ":" + port,
+		Handler:           apphttp.LogRequests(logger, apphttp.InstrumentRequests(reg, apphttp.TraceRequests(mux))),
+		ReadHeaderTimeout: 5 * time.Second,
+	}
+
+	dispatchCtx, stopDispatch := context.WithCancel(context.Background())
+	defer stopDispatch()
+	if svc.webhookRepo != nil {
+		go webhooks.NewDispatcher(svc.webhookRepo).Run(dispatchCtx, 2*time.Second)
+	}
+	go slackNotifier.Run(dispatchCtx)
+	go runPeriodically(dispatchCtx, time.Minute, "unavailability reassignment", svc.prs.ReassignUnavailable)
+
+	go func() {
+		log.Printf("starting pr-reviewer-service on :%s\n", port)
+		if err := server.ListenAndServe(); err != nil && err != http.ErrServerClosed {
+			log.Fatalf("server failed: %v", err)
+		}
+	}()
+
+	stop := make(chan os.Signal, 1)
+	signal.Notify(stop, syscall.SIGINT, syscall.SIGTERM)
+	<-stop
+
+	// Fail readiness first and give load balancers time to notice before
+	// the listener closes.
+	handler.Drain()
+	if drainDelay > 0 {
+		log.Printf("draining for %s before shutdown", drainDelay)
+		time.Sleep(drainDelay)
+	}
+
+	shutdownCtx, shutdownCancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer shutdownCancel()
+
+	stopDispatch()
+
+	if err := server.Shutdown(shutdownCtx); err != nil {
+		log.Printf("server shutdown error: %v", err)
+	}
+
+	log.Println("server stopped")
+	return nil
+}
+
+// openStorage returns services on the backend named by STORAGE: the database
+// from DB_DSN (the default) or memory, which needs no database and loses
+// everything on exit.
+// printBootstrapKey issues an admin key for in-memory storage, whose keys
+// cannot be created by the apikey command running in another process.
+func printBootstrapKey(keys *service.APIKeyService) error {
+	key, secret, err := keys.Create(context.Background(), "bootstrap", domain.RoleAdmin, "")
+	if err != nil {
+		return err
+	}
+	log.Printf("issued bootstrap api key %d (%s) for in-memory storage; it is printed to stdout", key.ID, key.Role)
+	fmt.Println(secret)
+	return nil
+}
+
+func openStorage() (services, func(), error) {
+	switch storage := os.Getenv("STORAGE"); storage {
+	case "", "postgres":
+		db, err := openDB()
+		if err != nil {
+			return services{}, nil, err
+		}
+
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		defer cancel()
+
+		if err := db.migrate(ctx); err != nil {
+			_ = db.Close()
+			return services{}, nil, fmt.Errorf("run migrations: %w", err)
+		}
+
+		closeDB := func() {
+			if err := db.Close(); err != nil {
+				log.Printf("failed to close db: %v", err)
+			}
+		}
+		return newServices(db), closeDB, nil
+	case "memory":
+		log.Println("using in-memory storage; data is lost when the process exits")
+		return newMemoryServices(), func() {}, nil
+	default:
+		return services{}, nil, fmt.Errorf("unknown STORAGE %q, want postgres or memory", storage)
+	}
+}
@@ -0,0 +1,122 @@
+package codeowners
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+var ErrSyntax = errors.New("invalid CODEOWNERS syntax")
+
+type Rule struct {
+	Line    int
+	Pattern string
+	Owners  []string
+	re      *regexp.Regexp
+}
+
+// Ruleset is a parsed CODEOWNERS file. As on GitHub, the last matching rule
+// for a path wins.
+type Ruleset struct {
+	Rules []Rule
+}
+
+func Parse(content string) (Ruleset, error) {
+	var rs Ruleset
+
+	sc := bufio.NewScanner(strings.NewReader(content))
+	line := 0
+	for sc.Scan() {
+		line++
+		text := strings.TrimSpace(sc.Text())
+		if text == "" || strings.HasPrefix(text, "#") {
+			continue
+		}
+		if i := strings.Index(text, " #"); i >= 0 {
+			text = strings.TrimSpace(text[:i])
+		}
+
+		fields := strings.Fields(text)
+		pattern, owners := fields[0], fields[1:]
+
+		re, err := compile(pattern)
+		if err != nil {
+			return Ruleset{}, fmt.Errorf("line %d: %w", line, err)
+		}
+		for _, o := range owners {
+			if !strings.Contains(o, "@") {
+				return Ruleset{}, fmt.Errorf("line %d: owner %q: %w", line, o, ErrSyntax)
+			}
+		}
+
+		rs.Rules = append(rs.Rules, Rule{
+			Line:    line,
+			Pattern: pattern,
+			Owners:  owners,
+			re:      re,
+		})
+	}
+	if err := sc.Err(); err != nil {
+		return Ruleset{}, err
+	}
+
+	return rs, nil
+}
+
+func (rs Ruleset) Match(path string) (Rule, bool) {
+	path = strings.TrimPrefix(path, "/")
+	for i := len(rs.Rules) - 1; i >= 0; i-- {
+		if rs.Rules[i].re.MatchString(path) {
+			return rs.Rules[i], true
+		}
+	}
+	return Rule{}, false
+}
+
+// compile turns a gitignore-style pattern into a regexp over slash-separated
+// paths relative to the repository root.
+func compile(pattern string) (*regexp.Regexp, error) {
+	if strings.HasPrefix(pattern, "!") || strings.ContainsAny(pattern, "[]\\") {
+		return nil, fmt.Errorf("pattern %q: %w", pattern, ErrSyntax)
+	}
+
+	dirOnly := strings.HasSuffix(pattern, "/")
+	p := strings.TrimSuffix(pattern, "/")
+	anchored := strings.Contains(p, "/")
+	p = strings.TrimPrefix(p, "/")
+	if p == "" {
+		return nil, fmt.Errorf("pattern %q: %w", pattern, ErrSyntax)
+	}
+
+	var b strings.Builder
+	b.WriteString("^")
+	if !anchored {
+		b.WriteString("(?:.*/)?")
+	}
+	for i := 0; i < len(p); i++ {
+		switch {
+		case strings.HasPrefix(p[i:], "**/"):
+			b.WriteString("(?:.*/)?")
+			i += 2
+		case strings.HasPrefix(p[i:], "/**") && i+3 == len(p):
+			b.WriteString("/.*")
+			i += 2
+		case p[i] == '*':
+			b.WriteString("[^/]*")
+		case p[i] == '?':
+			b.WriteString("[^/]")
+		default:
+			b.WriteString(regexp.QuoteMeta(string(p[i])))
+		}
+	}
+	// A pattern naming a directory also covers everything below it.
+	if dirOnly {
+		b.WriteString("/.*$")
+	} else {
+		b.WriteString("(?:/.*)?$")
+	}
+
+	return regexp.Compile(b.String())
+}
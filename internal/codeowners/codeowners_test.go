@@ -0,0 +1,59 @@
+package codeowners
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestRuleset_Match(t *testing.T) {
+	rs, err := Parse(`
+# default owners
+*                 @lead
+*.go              @gopher
+/docs/            @writer
+internal/billing/ @alice @org/payments
+**/testdata/**    @qa # fixtures
+`)
+	if err != nil {
+		t.Fatalf("Parse returned error: %v", err)
+	}
+
+	cases := []struct {
+		path    string
+		pattern string
+	}{
+		{"README.md", "*"},
+		{"cmd/main.go", "*.go"},
+		{"docs/intro.md", "/docs/"},
+		{"pkg/docs/intro.md", "*"},
+		{"internal/billing/invoice.go", "internal/billing/"},
+		{"internal/billing/sub/plan.sql", "internal/billing/"},
+		{"internal/http/testdata/a.json", "**/testdata/**"},
+	}
+	for _, c := range cases {
+		rule, ok := rs.Match(c.path)
+		if !ok {
+			t.Fatalf("%s: expected a match", c.path)
+		}
+		if rule.Pattern != c.pattern {
+			t.Fatalf("%s: expected rule %q, got %q", c.path, c.pattern, rule.Pattern)
+		}
+	}
+
+	rule, _ := rs.Match("internal/billing/invoice.go")
+	if len(rule.Owners) != 2 || rule.Owners[1] != "@org/payments" {
+		t.Fatalf("unexpected owners %v", rule.Owners)
+	}
+}
+
+func TestParse_RejectsInvalidLines(t *testing.T) {
+	for _, content := range []string{
+		"*.go alice",
+		"!vendor/ @alice",
+		"src/[ab].go @alice",
+	} {
+		if _, err := Parse(content); !errors.Is(err, ErrSyntax) {
+			t.Fatalf("%q: expected ErrSyntax, got %v", content, err)
+		}
+	}
+}
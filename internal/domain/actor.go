@@ -0,0 +1,16 @@
+package domain
+
+import "context"
+
+type actorKey struct{}
+
+func ContextWithActor(ctx context.Context, actor UserID) context.Context {
+	return context.WithValue(ctx, actorKey{}, actor)
+}
+
+// ActorFromContext returns the user performing the current action, or an
+// empty ID when the action is not attributed to anyone.
+func ActorFromContext(ctx context.Context) UserID {
+	actor, _ := ctx.Value(actorKey{}).(UserID)
+	return actor
+}
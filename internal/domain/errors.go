@@ -6,7 +6,21 @@ var (
 	ErrTeamExists        = errors.New("team already exists")
 	ErrPullRequestExists = errors.New("pull request already exists")
 	ErrPullRequestMerged = errors.New("pull request already merged")
+	ErrPullRequestClosed = errors.New("pull request is closed")
 	ErrNotAssigned       = errors.New("reviewer is not assigned to this pull request")
 	ErrNoCandidate       = errors.New("no active replacement candidate in team")
 	ErrNotFound          = errors.New("resource not found")
+	ErrInvalidSettings   = errors.New("invalid team settings")
+	ErrPoolTooSmall      = errors.New("reviewer pool is smaller than team minimum")
+	ErrInvalidWebhook    = errors.New("invalid webhook subscription")
+	ErrInvalidPeriod     = errors.New("unavailability must end after it starts")
+	ErrInvalidCapacity   = errors.New("max open reviews must not be negative")
+	ErrInvalidCodeowners = errors.New("invalid CODEOWNERS ruleset")
+	ErrInvalidReview     = errors.New("invalid review state")
+	ErrInvalidAPIKey     = errors.New("invalid api key")
+	ErrUnauthenticated   = errors.New("unknown or revoked api key")
+
+	ErrAllReviewersAtCapacity = errors.New("all candidate reviewers are at capacity")
+	ErrNotEnoughApprovals     = errors.New("pull request lacks required approvals")
+	ErrInvalidTransition      = errors.New("pull request status transition is not allowed")
 )
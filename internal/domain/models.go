@@ -9,15 +9,121 @@ type PullRequestID string
 type PRStatus string
 
 const (
+	PRStatusDraft  PRStatus = "DRAFT"
 	PRStatusOpen   PRStatus = "OPEN"
 	PRStatusMerged PRStatus = "MERGED"
+	PRStatusClosed PRStatus = "CLOSED"
 )
 
+var prTransitions = map[PRStatus][]PRStatus{
+	PRStatusDraft:  {PRStatusOpen, PRStatusClosed},
+	PRStatusOpen:   {PRStatusMerged, PRStatusClosed},
+	PRStatusClosed: {PRStatusOpen, PRStatusDraft},
+}
+
+// CanTransitionTo reports whether a PR in status s may move to next.
+// MERGED is terminal.
+func (s PRStatus) CanTransitionTo(next PRStatus) bool {
+	for _, t := range prTransitions[s] {
+		if t == next {
+			return true
+		}
+	}
+	return false
+}
+
+type ReviewState string
+
+const (
+	ReviewStatePending          ReviewState = "PENDING"
+	ReviewStateApproved         ReviewState = "APPROVED"
+	ReviewStateChangesRequested ReviewState = "CHANGES_REQUESTED"
+	ReviewStateCommented        ReviewState = "COMMENTED"
+)
+
+// Valid reports whether s can be submitted by a reviewer; PENDING is only
+// the initial state.
+func (s ReviewState) Valid() bool {
+	switch s {
+	case ReviewStateApproved, ReviewStateChangesRequested, ReviewStateCommented:
+		return true
+	default:
+		return false
+	}
+}
+
+type ReviewerStrategy string
+
+const (
+	ReviewerStrategyRandom      ReviewerStrategy = "random"
+	ReviewerStrategyLeastLoaded ReviewerStrategy = "least_loaded"
+)
+
+func (s ReviewerStrategy) Valid() bool {
+	switch s {
+	case ReviewerStrategyRandom, ReviewerStrategyLeastLoaded:
+		return true
+	default:
+		return false
+	}
+}
+
 type User struct {
 	ID       UserID
 	Username string
 	TeamName TeamName
 	IsActive bool
+	// SlackHandle is the Slack member ID used to mention the user.
+	SlackHandle string
+	// MaxOpenReviews caps reviews on OPEN PRs; zero means unlimited.
+	MaxOpenReviews int
+}
+
+func (u User) AtCapacity(openReviews int) bool {
+	return u.MaxOpenReviews > 0 && openReviews >= u.MaxOpenReviews
+}
+
+// UserStatusChange records who activated or deactivated a user, and when.
+type UserStatusChange struct {
+	ID       int64
+	UserID   UserID
+	IsActive bool
+	// Actor is empty when the change was made by the system.
+	Actor     UserID
+	ChangedAt time.Time
+}
+
+// Unavailability is a period during which a user is not picked as a reviewer.
+type Unavailability struct {
+	ID       int64
+	UserID   UserID
+	StartsAt time.Time
+	EndsAt   time.Time
+	Reason   string
+	// ReassignOpenReviews hands the user's open reviews over once the period starts.
+	ReassignOpenReviews bool
+	ReassignedAt        *time.Time
+}
+
+func (pr PullRequest) ReviewState(id UserID) ReviewState {
+	if s, ok := pr.ReviewStates[id]; ok {
+		return s
+	}
+	return ReviewStatePending
+}
+
+func (pr PullRequest) Approvals() int {
+	n := 0
+	for _, id := range pr.AssignedReviewers {
+		if pr.ReviewState(id) == ReviewStateApproved {
+			n++
+		}
+	}
+	return n
+}
+
+func (u Unavailability) Covers(t time.Time) bool {
+	return !t.Before(u.StartsAt) && t.Before(u.EndsAt)
 }
 
 type Team struct {
@@ -25,14 +131,54 @@ type Team struct {
 	Members []User
 }
 
+const DefaultReviewersCount = 2
+
+type TeamSettings struct {
+	TeamName       TeamName
+	ReviewersCount int
+	Strategy       ReviewerStrategy
+	MinPoolSize    int
+	FallbackTeams  []TeamName
+	// SlackWebhookURL is the incoming webhook assignment notices are posted to.
+	SlackWebhookURL string
+	// RequiredApprovals gates merging; zero disables the gate.
+	RequiredApprovals int
+}
+
+func DefaultTeamSettings(name TeamName) TeamSettings {
+	return TeamSettings{
+		TeamName:       name,
+		ReviewersCount: DefaultReviewersCount,
+		Strategy:       ReviewerStrategyRandom,
+		MinPoolSize:    0,
+	}
+}
+
 type PullRequest struct {
 	ID                PullRequestID
 	Name              string
 	AuthorID          UserID
 	Status            PRStatus
 	AssignedReviewers []UserID
-	CreatedAt         time.Time
-	MergedAt          *time.Time
+	// FallbackReviewers maps reviewers picked outside the author's team
+	// to the fallback team they were taken from.
+	FallbackReviewers map[UserID]TeamName
+	// MatchedRules maps reviewers picked as code owners to the CODEOWNERS
+	// pattern that selected them.
+	MatchedRules map[UserID]string
+	// ReviewStates holds submitted reviews; reviewers missing from it are PENDING.
+	ReviewStates map[UserID]ReviewState
+	CreatedAt    time.Time
+	MergedAt     *time.Time
+	ClosedAt     *time.Time
+	// ClosedFrom is the status a CLOSED PR had before it was closed.
+	ClosedFrom PRStatus
+}
+
+type Codeowners struct {
+	TeamName  TeamName
+	Content   string
+	UpdatedAt time.Time
 }
 
 type PullRequestShort struct {
@@ -41,3 +187,153 @@ type PullRequestShort struct {
 	AuthorID UserID
 	Status   PRStatus
 }
+
+type AssignmentEventType string
+
+const (
+	AssignmentEventAssigned   AssignmentEventType = "ASSIGNED"
+	AssignmentEventUnassigned AssignmentEventType = "UNASSIGNED"
+	AssignmentEventReplaced   AssignmentEventType = "REPLACED"
+)
+
+type AssignmentReason string
+
+const (
+	AssignmentReasonCreate           AssignmentReason = "create"
+	AssignmentReasonManualReassign   AssignmentReason = "manual_reassign"
+	AssignmentReasonBulkDeactivation AssignmentReason = "bulk_deactivation"
+	AssignmentReasonUnavailable      AssignmentReason = "unavailable"
+	AssignmentReasonReadyForReview   AssignmentReason = "ready_for_review"
+)
+
+type AssignmentEvent struct {
+	ID            int64
+	PullRequestID PullRequestID
+	Type          AssignmentEventType
+	UserID        UserID
+	// ReplacedBy is set for REPLACED events only.
+	ReplacedBy UserID
+	// Actor is empty when the change was made by the system.
+	Actor     UserID
+	Reason    AssignmentReason
+	CreatedAt time.Time
+}
+
+type OutboxEventType string
+
+const (
+	OutboxPRCreated         OutboxEventType = "pr.created"
+	OutboxReviewerAssigned  OutboxEventType = "reviewer.assigned"
+	OutboxReviewerReplaced  OutboxEventType = "reviewer.replaced"
+	OutboxPRMerged          OutboxEventType = "pr.merged"
+	OutboxPRStatusChanged   OutboxEventType = "pr.status_changed"
+	OutboxUserStatusChanged OutboxEventType = "user.status_changed"
+)
+
+func (t OutboxEventType) Valid() bool {
+	switch t {
+	case OutboxPRCreated, OutboxReviewerAssigned, OutboxReviewerReplaced, OutboxPRMerged, OutboxPRStatusChanged,
+		OutboxUserStatusChanged:
+		return true
+	default:
+		return false
+	}
+}
+
+type OutboxEvent struct {
+	ID        int64
+	Type      OutboxEventType
+	Payload   []byte
+	CreatedAt time.Time
+}
+
+type WebhookSubscription struct {
+	ID     int64
+	URL    string
+	Secret string
+	// Events limits deliveries to the listed types; empty means all events.
+	Events    []OutboxEventType
+	CreatedAt time.Time
+}
+
+type DeliveryStatus string
+
+const (
+	DeliveryPending   DeliveryStatus = "PENDING"
+	DeliveryDelivered DeliveryStatus = "DELIVERED"
+	DeliveryDead      DeliveryStatus = "DEAD"
+)
+
+type WebhookDelivery struct {
+	ID             int64
+	SubscriptionID int64
+	URL            string
+	Secret         string
+	Event          OutboxEvent
+	Status         DeliveryStatus
+	Attempts       int
+	NextAttemptAt  time.Time
+	LastError      string
+}
+
+// ReviewerNotice tells a reviewer they were assigned to a pull request.
+type ReviewerNotice struct {
+	WebhookURL      string
+	PullRequestID   PullRequestID
+	PullRequestName string
+	AuthorID        UserID
+	Reviewer        User
+	Replaced        UserID
+	Reason          AssignmentReason
+}
+
+type Role string
+
+const (
+	RoleAdmin    Role = "admin"
+	RoleTeamLead Role = "team_lead"
+	RoleReadOnly Role = "read_only"
+)
+
+func (r Role) Valid() bool {
+	switch r {
+	case RoleAdmin, RoleTeamLead, RoleReadOnly:
+		return true
+	default:
+		return false
+	}
+}
+
+// APIKey authenticates HTTP callers. Only the SHA-256 hash of the secret is
+// stored; TeamName is set for team leads and limits them to that team.
+type APIKey struct {
+	ID        int64
+	Name      string
+	Role      Role
+	TeamName  TeamName
+	Hash      string
+	CreatedAt time.Time
+	RevokedAt *time.Time
+}
+
+func (k APIKey) Principal() Principal {
+	return Principal{Role: k.Role, TeamName: k.TeamName}
+}
+
+// Principal is an authenticated caller. Actor is set for people signed in
+// through SSO and empty for API keys, which act on nobody's behalf.
+type Principal struct {
+	Actor    UserID
+	Role     Role
+	TeamName TeamName
+}
+
+// CanWrite reports whether the caller may change anything at all; team leads
+// still need CanManageTeam for the team concerned.
+func (p Principal) CanWrite() bool {
+	return p.Role == RoleAdmin || p.Role == RoleTeamLead
+}
+
+func (p Principal) CanManageTeam(team TeamName) bool {
+	return p.Role == RoleAdmin || (p.Role == RoleTeamLead && p.TeamName == team)
+}
@@ -8,22 +8,101 @@ import (
 type TeamRepository interface {
 	CreateTeam(ctx context.Context, name TeamName) error
 	GetTeam(ctx context.Context, name TeamName) (Team, error)
+	ListTeams(ctx context.Context) ([]TeamName, error)
 	TeamExists(ctx context.Context, name TeamName) (bool, error)
+	GetSettings(ctx context.Context, name TeamName) (TeamSettings, error)
+	// GetCodeowners returns ErrNotFound when the team has not uploaded a ruleset.
+	GetCodeowners(ctx context.Context, name TeamName) (Codeowners, error)
+	PutCodeowners(ctx context.Context, c Codeowners) error
+	UpsertSettings(ctx context.Context, settings TeamSettings) error
 }
 
 type UserRepository interface {
 	UpsertUsers(ctx context.Context, users []User) error
 	GetByID(ctx context.Context, id UserID) (User, error)
-	SetIsActive(ctx context.Context, id UserID, isActive bool) (User, error)
+	// SetIsActive records the change and its actor alongside the new status.
+	SetIsActive(ctx context.Context, id UserID, isActive bool, actor UserID) (User, error)
+	// ListStatusChanges returns the user's status changes, oldest first.
+	ListStatusChanges(ctx context.Context, id UserID) ([]UserStatusChange, error)
+	SetMaxOpenReviews(ctx context.Context, id UserID, maxOpenReviews int) (User, error)
+	// ListActiveByTeam skips users with an unavailability period covering now.
 	ListActiveByTeam(ctx context.Context, teamName TeamName) ([]User, error)
+	AddUnavailability(ctx context.Context, u Unavailability) (Unavailability, error)
+	ListUnavailability(ctx context.Context, id UserID) ([]Unavailability, error)
+	GetUnavailability(ctx context.Context, id int64) (Unavailability, error)
+	DeleteUnavailability(ctx context.Context, id int64) error
+	// ListDueReassignments returns started periods whose open reviews still
+	// have to be handed over.
+	ListDueReassignments(ctx context.Context, now time.Time) ([]Unavailability, error)
+	MarkReassigned(ctx context.Context, id int64, at time.Time) error
 }
 
 type PullRequestRepository interface {
 	Create(ctx context.Context, pr PullRequest) error
 	Exists(ctx context.Context, id PullRequestID) (bool, error)
 	Get(ctx context.Context, id PullRequestID) (PullRequest, error)
+	// List returns every PR with its reviewers, oldest first.
+	List(ctx context.Context) ([]PullRequest, error)
+	// GetForUpdate locks the PR until the surrounding unit of work finishes.
+	GetForUpdate(ctx context.Context, id PullRequestID) (PullRequest, error)
+	SetReviewState(ctx context.Context, prID PullRequestID, reviewerID UserID, state ReviewState) error
 	MarkMerged(ctx context.Context, id PullRequestID, mergedAt time.Time) error
-	ReplaceReviewer(ctx context.Context, prID PullRequestID, oldUserID, newUserID UserID) error
+	// SetStatus moves a PR between DRAFT, OPEN and CLOSED; closedAt is
+	// cleared when nil. Closing remembers the previous status as ClosedFrom.
+	SetStatus(ctx context.Context, id PullRequestID, status PRStatus, closedAt *time.Time) error
+	// AddReviewers assigns pr.AssignedReviewers together with their fallback
+	// teams and matched rules.
+	AddReviewers(ctx context.Context, pr PullRequest) error
+	// ReplaceReviewer swaps oldUserID for newUserID and reports whether
+	// newUserID was added; if already assigned, oldUserID is only removed.
+	ReplaceReviewer(ctx context.Context, prID PullRequestID, oldUserID, newUserID UserID, fallbackTeam TeamName) (bool, error)
 	ListByReviewer(ctx context.Context, reviewerID UserID) ([]PullRequestShort, error)
 	StatsAssignmentsByUser(ctx context.Context) (map[UserID]int, error)
+	OpenAssignmentsByUser(ctx context.Context) (map[UserID]int, error)
+	// OpenByTeam counts OPEN PRs by their author's team.
+	OpenByTeam(ctx context.Context) (map[TeamName]int, error)
+	AppendAssignmentEvents(ctx context.Context, events []AssignmentEvent) error
+	ListAssignmentEvents(ctx context.Context, prID PullRequestID) ([]AssignmentEvent, error)
+}
+
+type OutboxRepository interface {
+	AppendOutbox(ctx context.Context, events []OutboxEvent) error
+}
+
+type WebhookRepository interface {
+	OutboxRepository
+	CreateSubscription(ctx context.Context, sub WebhookSubscription) (WebhookSubscription, error)
+	ListSubscriptions(ctx context.Context) ([]WebhookSubscription, error)
+	DeleteSubscription(ctx context.Context, id int64) error
+	// EnqueueDeliveries fans undispatched outbox events out to matching
+	// subscriptions and returns the number of events processed.
+	EnqueueDeliveries(ctx context.Context, limit int) (int, error)
+	// ClaimDueDeliveries returns pending deliveries due at now and hides them
+	// from other workers until leaseUntil.
+	ClaimDueDeliveries(ctx context.Context, now, leaseUntil time.Time, limit int) ([]WebhookDelivery, error)
+	MarkDelivered(ctx context.Context, id int64) error
+	MarkFailed(ctx context.Context, id int64, attempts int, nextAttemptAt time.Time, lastError string, dead bool) error
+	ListDeadLetters(ctx context.Context) ([]WebhookDelivery, error)
+	Redrive(ctx context.Context, id int64, at time.Time) error
+}
+
+type APIKeyRepository interface {
+	Create(ctx context.Context, key APIKey) (APIKey, error)
+	// GetByHash returns ErrNotFound for unknown and revoked keys alike.
+	GetByHash(ctx context.Context, hash string) (APIKey, error)
+	List(ctx context.Context) ([]APIKey, error)
+	Revoke(ctx context.Context, id int64, at time.Time) error
+}
+
+type Repositories struct {
+	Teams  TeamRepository
+	Users  UserRepository
+	Prs    PullRequestRepository
+	Outbox OutboxRepository
+}
+
+// UnitOfWork runs fn with repositories bound to a single transaction,
+// committing when fn returns nil and rolling back otherwise.
+type UnitOfWork interface {
+	Do(ctx context.Context, fn func(ctx context.Context, repos Repositories) error) error
 }
@@ -0,0 +1,228 @@
+package http
+
+import (
+	"context"
+	"errors"
+	stdhttp "net/http"
+	"strings"
+
+	"pr-reviewer-service/internal/domain"
+	"pr-reviewer-service/internal/oidc"
+	"pr-reviewer-service/internal/service"
+)
+
+// access is what a route asks of the caller.
+type access int
+
+const (
+	accessPublic access = iota
+	accessRead
+	// accessWrite admits admins and team leads; handlers then check the team
+	// a lead is touching with authorizeTeam and friends.
+	accessWrite
+	accessAdmin
+)
+
+type principalContextKey struct{}
+
+// EnableAuth makes every non-public route require an API key. Without it
+// the API stays open, which is what tests and local setups rely on.
+func (h *Handler) EnableAuth(keys *service.APIKeyService) {
+	h.apiKeys = keys
+}
+
+// EnableSSO also accepts JWTs from the SSO provider. API keys keep working
+// alongside, told apart by their prefix.
+func (h *Handler) EnableSSO(v *oidc.Verifier) {
+	h.sso = v
+}
+
+// guard authenticates the caller and checks read access for GET and HEAD
+// requests and write access for everything else. SSO callers are also put
+// in the context as the actor services record.
+func (h *Handler) guard(read, write access, next stdhttp.HandlerFunc) stdhttp.HandlerFunc {
+	return func(w stdhttp.ResponseWriter, r *stdhttp.Request) {
+		need := write
+		if r.Method == stdhttp.MethodGet || r.Method == stdhttp.MethodHead {
+			need = read
+		}
+		if (h.apiKeys == nil && h.sso == nil) || need == accessPublic {
+			next(w, r)
+			return
+		}
+
+		token, ok := bearerToken(r)
+		if !ok {
+			writeUnauthorized(w)
+			return
+		}
+		p, err := h.authenticate(r.Context(), token)
+		if err != nil {
+			if errors.Is(err, domain.ErrUnauthenticated) {
+				writeUnauthorized(w)
+			} else {
+				writeInternalError(w, r, err)
+			}
+			return
+		}
+
+		if !allows(p, need) {
+			writeForbidden(w)
+			return
+		}
+		ctx := context.WithValue(r.Context(), principalContextKey{}, p)
+		if p.Actor != "" {
+			ctx = domain.ContextWithActor(ctx, p.Actor)
+		}
+		next(w, r.WithContext(ctx))
+	}
+}
+
+func (h *Handler) authenticate(ctx context.Context, token string) (domain.Principal, error) {
+	if h.apiKeys != nil && strings.HasPrefix(token, service.APIKeyPrefix) {
+		key, err := h.apiKeys.Authenticate(ctx, token)
+		if err != nil {
+			return domain.Principal{}, err
+		}
+		return key.Principal(), nil
+	}
+	if h.sso == nil {
+		return domain.Principal{}, domain.ErrUnauthenticated
+	}
+
+	id, err := h.sso.Verify(ctx, token)
+	if err != nil {
+		if errors.Is(err, oidc.ErrInvalidToken) {
+			return domain.Principal{}, domain.ErrUnauthenticated
+		}
+		return domain.Principal{}, err
+	}
+
+	// A lead's team is the one the user belongs to here, not a claim.
+	p := domain.Principal{Actor: id.User, Role: id.Role}
+	user, err := h.userService.Get(ctx, id.User)
+	switch {
+	case err == nil:
+		p.TeamName = user.TeamName
+	case !errors.Is(err, domain.ErrNotFound):
+		return domain.Principal{}, err
+	}
+	return p, nil
+}
+
+func allows(p domain.Principal, need access) bool {
+	switch need {
+	case accessAdmin:
+		return p.Role == domain.RoleAdmin
+	case accessWrite:
+		return p.CanWrite()
+	default:
+		return true
+	}
+}
+
+func bearerToken(r *stdhttp.Request) (string, bool) {
+	scheme, token, ok := strings.Cut(r.Header.Get("Authorization"), " ")
+	if !ok || !strings.EqualFold(scheme, "Bearer") {
+		return "", false
+	}
+	token = strings.TrimSpace(token)
+	return token, token != ""
+}
+
+// principalFromContext returns the caller; ok is false when auth is off.
+func principalFromContext(ctx context.Context) (domain.Principal, bool) {
+	p, ok := ctx.Value(principalContextKey{}).(domain.Principal)
+	return p, ok
+}
+
+// isSelf reports whether the caller signed in as user id. People may manage
+// their own availability and reviews even with a read-only role.
+func isSelf(r *stdhttp.Request, id domain.UserID) bool {
+	p, ok := principalFromContext(r.Context())
+	return ok && p.Actor != "" && p.Actor == id
+}
+
+// authorizeAdmin writes 403 and returns false unless the caller is an admin.
+func (h *Handler) authorizeAdmin(w stdhttp.ResponseWriter, r *stdhttp.Request) bool {
+	p, ok := principalFromContext(r.Context())
+	if ok && p.Role != domain.RoleAdmin {
+		writeForbidden(w)
+		return false
+	}
+	return true
+}
+
+// authorizeTeam writes 403 and returns false unless the caller may manage
+// team.
+func (h *Handler) authorizeTeam(w stdhttp.ResponseWriter, r *stdhttp.Request, team domain.TeamName) bool {
+	p, ok := principalFromContext(r.Context())
+	if ok && !p.CanManageTeam(team) {
+		writeForbidden(w)
+		return false
+	}
+	return true
+}
+
+// authorizeUser checks the caller may manage the user's team.
+func (h *Handler) authorizeUser(w stdhttp.ResponseWriter, r *stdhttp.Request, id domain.UserID) bool {
+	p, ok := principalFromContext(r.Context())
+	if !ok || p.Role == domain.RoleAdmin {
+		return true
+	}
+
+	user, err := h.userService.Get(r.Context(), id)
+	if err != nil {
+		writeLookupError(w, r, err)
+		return false
+	}
+	return h.authorizeTeam(w, r, user.TeamName)
+}
+
+// authorizePR checks the caller may manage the team of the PR's author.
+func (h *Handler) authorizePR(w stdhttp.ResponseWriter, r *stdhttp.Request, id domain.PullRequestID) bool {
+	p, ok := principalFromContext(r.Context())
+	if !ok || p.Role == domain.RoleAdmin {
+		return true
+	}
+
+	pr, err := h.prService.Get(r.Context(), id)
+	if err != nil {
+		writeLookupError(w, r, err)
+		return false
+	}
+	return h.authorizeUser(w, r, pr.AuthorID)
+}
+
+// authorizeUnavailability checks the caller is the period's user or may
+// manage them.
+func (h *Handler) authorizeUnavailability(w stdhttp.ResponseWriter, r *stdhttp.Request, id int64) bool {
+	p, ok := principalFromContext(r.Context())
+	if !ok || p.Role == domain.RoleAdmin {
+		return true
+	}
+
+	period, err := h.userService.GetUnavailability(r.Context(), id)
+	if err != nil {
+		writeLookupError(w, r, err)
+		return false
+	}
+	return isSelf(r, period.UserID) || h.authorizeUser(w, r, period.UserID)
+}
+
+func writeLookupError(w stdhttp.ResponseWriter, r *stdhttp.Request, err error) {
+	if errors.Is(err, domain.ErrNotFound) {
+		writeError(w, stdhttp.StatusNotFound, "NOT_FOUND", "resource not found")
+		return
+	}
+	writeInternalError(w, r, err)
+}
+
+func writeUnauthorized(w stdhttp.ResponseWriter) {
+	w.Header().Set("WWW-Authenticate", "Bearer")
+	writeError(w, stdhttp.StatusUnauthorized, "UNAUTHORIZED", "missing or invalid credentials")
+}
+
+func writeForbidden(w stdhttp.ResponseWriter) {
+	writeError(w, stdhttp.StatusForbidden, "FORBIDDEN", "caller is not allowed to do this")
+}
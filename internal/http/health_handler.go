@@ -1,16 +1,53 @@
 package http
 
 import (
+	"context"
 	stdhttp "net/http"
 	"time"
 )
 
+// readinessTimeout bounds each readiness check so a hung dependency fails
+// the probe instead of stalling it.
+const readinessTimeout = 2 * time.Second
+
+// ReadinessCheck reports whether a dependency the service needs is usable.
+type ReadinessCheck struct {
+	Name  string
+	Check func(ctx context.Context) error
+}
+
+// EnableReadiness makes /readyz run checks in order on every probe.
+func (h *Handler) EnableReadiness(checks ...ReadinessCheck) {
+	h.readiness = append(h.readiness, checks...)
+}
+
+// Drain makes /readyz report not ready from now on, so load balancers stop
+// sending traffic before the server shuts down. /livez is unaffected.
+func (h *Handler) Drain() {
+	h.draining.Store(true)
+}
+
 type healthResponse struct {
 	Status string    `json:"status"`
 	Time   time.Time `json:"time"`
 }
 
-func (h *Handler) handleHealth(w stdhttp.ResponseWriter, r *stdhttp.Request) {
+type readinessResponse struct {
+	Status string        `json:"status"`
+	Checks []checkResult `json:"checks"`
+	Time   time.Time     `json:"time"`
+}
+
+type checkResult struct {
+	Name      string  `json:"name"`
+	Status    string  `json:"status"`
+	LatencyMS float64 `json:"latency_ms"`
+	Error     string  `json:"error,omitempty"`
+}
+
+// handleLive answers as long as the process can serve HTTP; it checks no
+// dependencies, so a database outage never gets the pod restarted.
+func (h *Handler) handleLive(w stdhttp.ResponseWriter, r *stdhttp.Request) {
 	if r.Method != stdhttp.MethodGet {
 		w.Header().Set("Allow", stdhttp.MethodGet)
 		writeError(w, stdhttp.StatusMethodNotAllowed, "METHOD_NOT_ALLOWED", "method not allowed")
@@ -24,3 +61,54 @@ func (h *Handler) handleHealth(w stdhttp.ResponseWriter, r *stdhttp.Request) {
 
 	writeJSON(w, stdhttp.StatusOK, resp)
 }
+
+func (h *Handler) handleReady(w stdhttp.ResponseWriter, r *stdhttp.Request) {
+	if r.Method != stdhttp.MethodGet {
+		w.Header().Set("Allow", stdhttp.MethodGet)
+		writeError(w, stdhttp.StatusMethodNotAllowed, "METHOD_NOT_ALLOWED", "method not allowed")
+		return
+	}
+
+	resp := readinessResponse{
+		Status: "ok",
+		Checks: []checkResult{},
+		Time:   time.Now().UTC(),
+	}
+	if h.draining.Load() {
+		resp.Status = "draining"
+		writeJSON(w, stdhttp.StatusServiceUnavailable, resp)
+		return
+	}
+
+	for _, c := range h.readiness {
+		res := runCheck(r.Context(), c)
+		if res.Error != "" {
+			resp.Status = "unavailable"
+		}
+		resp.Checks = append(resp.Checks, res)
+	}
+
+	status := stdhttp.StatusOK
+	if resp.Status != "ok" {
+		status = stdhttp.StatusServiceUnavailable
+	}
+	writeJSON(w, status, resp)
+}
+
+func runCheck(ctx context.Context, c ReadinessCheck) checkResult {
+	ctx, cancel := context.WithTimeout(ctx, readinessTimeout)
+	defer cancel()
+
+	start := time.Now()
+	err := c.Check(ctx)
+	res := checkResult{
+		Name:      c.Name,
+		Status:    "ok",
+		LatencyMS: float64(time.Since(start).Microseconds()) / 1000,
+	}
+	if err != nil {
+		res.Status = "failed"
+		res.Error = err.Error()
+	}
+	return res
+}
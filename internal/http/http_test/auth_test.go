@@ -0,0 +1,147 @@
+package http_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"testing"
+
+	"pr-reviewer-service/internal/domain"
+	httphandler "pr-reviewer-service/internal/http"
+	"pr-reviewer-service/internal/repository/memory"
+	"pr-reviewer-service/internal/service"
+)
+
+func (e *testEnv) requestAs(t *testing.T, apiKey, method, path string, body any) *http.Response {
+	t.Helper()
+	var r io.Reader
+	if body != nil {
+		data, err := json.Marshal(body)
+		if err != nil {
+			t.Fatalf("marshal body: %v", err)
+		}
+		r = bytes.NewReader(data)
+	}
+	req, err := http.NewRequest(method, e.server.URL+path, r)
+	if err != nil {
+		t.Fatalf("new request: %v", err)
+	}
+	if apiKey != "" {
+		req.Header.Set("Authorization", "Bearer "+apiKey)
+	}
+	resp, err := e.client.Do(req)
+	if err != nil {
+		t.Fatalf("do request: %v", err)
+	}
+	return resp
+}
+
+func expectStatus(t *testing.T, resp *http.Response, status int, code string) {
+	t.Helper()
+	if resp.StatusCode != status {
+		t.Fatalf("%s %s: expected %d, got %d", resp.Request.Method, resp.Request.URL.Path, status, resp.StatusCode)
+	}
+	if code == "" {
+		_ = resp.Body.Close()
+		return
+	}
+	var body errorResponse
+	decodeBody(t, resp, &body)
+	if body.Error.Code != code {
+		t.Fatalf("%s %s: expected code %s, got %q", resp.Request.Method, resp.Request.URL.Path, code, body.Error.Code)
+	}
+}
+
+func TestAPIKeyRoles(t *testing.T) {
+	var handler *httphandler.Handler
+	env := newTestEnv(t, func(h *httphandler.Handler) { handler = h })
+	keys := service.NewAPIKeyService(memory.NewAPIKeyRepo(env.store), memory.NewTeamRepo(env.store))
+	handler.EnableAuth(keys)
+
+	ctx := context.Background()
+	_, admin, err := keys.Create(ctx, "ops", domain.RoleAdmin, "")
+	if err != nil {
+		t.Fatalf("create admin key: %v", err)
+	}
+
+	for _, team := range []map[string]any{
+		{"team_name": "backend", "members": []map[string]any{
+			{"user_id": "u1", "username": "Alice", "is_active": true},
+			{"user_id": "u2", "username": "Bob", "is_active": true},
+		}},
+		{"team_name": "platform", "members": []map[string]any{
+			{"user_id": "p1", "username": "Pat", "is_active": true},
+			{"user_id": "p2", "username": "Quinn", "is_active": true},
+		}},
+	} {
+		expectStatus(t, env.requestAs(t, admin, http.MethodPost, "/team/add", team), http.StatusCreated, "")
+	}
+
+	leadKey, lead, err := keys.Create(ctx, "backend lead", domain.RoleTeamLead, "backend")
+	if err != nil {
+		t.Fatalf("create lead key: %v", err)
+	}
+	_, reader, err := keys.Create(ctx, "dashboard", domain.RoleReadOnly, "")
+	if err != nil {
+		t.Fatalf("create read-only key: %v", err)
+	}
+
+	expectStatus(t, env.get(t, "/health"), http.StatusOK, "")
+
+	resp := env.get(t, "/team/get?team_name=backend")
+	if resp.Header.Get("WWW-Authenticate") != "Bearer" {
+		t.Fatalf("401 must carry WWW-Authenticate, got %q", resp.Header.Get("WWW-Authenticate"))
+	}
+	expectStatus(t, resp, http.StatusUnauthorized, "UNAUTHORIZED")
+	expectStatus(t, env.requestAs(t, "prk_nope", http.MethodGet, "/team/get?team_name=backend", nil),
+		http.StatusUnauthorized, "UNAUTHORIZED")
+
+	// Read-only keys can read but not write.
+	expectStatus(t, env.requestAs(t, reader, http.MethodGet, "/team/settings?team_name=backend", nil), http.StatusOK, "")
+	expectStatus(t, env.requestAs(t, reader, http.MethodPost, "/team/settings",
+		map[string]any{"team_name": "backend", "reviewers_count": 1, "strategy": "random"}),
+		http.StatusForbidden, "FORBIDDEN")
+
+	// Team leads manage their own team only.
+	expectStatus(t, env.requestAs(t, lead, http.MethodPost, "/team/settings",
+		map[string]any{"team_name": "backend", "reviewers_count": 1, "strategy": "random"}),
+		http.StatusOK, "")
+	expectStatus(t, env.requestAs(t, lead, http.MethodPost, "/team/settings",
+		map[string]any{"team_name": "platform", "reviewers_count": 1, "strategy": "random"}),
+		http.StatusForbidden, "FORBIDDEN")
+	expectStatus(t, env.requestAs(t, lead, http.MethodPost, "/team/deactivateMembers",
+		map[string]any{"team_name": "platform", "user_ids": []string{"p1"}}),
+		http.StatusForbidden, "FORBIDDEN")
+	expectStatus(t, env.requestAs(t, lead, http.MethodPost, "/team/deactivateMembers",
+		map[string]any{"team_name": "backend", "user_ids": []string{"p1"}}),
+		http.StatusForbidden, "FORBIDDEN")
+	expectStatus(t, env.requestAs(t, lead, http.MethodPost, "/users/setIsActive",
+		map[string]any{"user_id": "p2", "is_active": false}),
+		http.StatusForbidden, "FORBIDDEN")
+	expectStatus(t, env.requestAs(t, lead, http.MethodPost, "/team/add",
+		map[string]any{"team_name": "mobile"}),
+		http.StatusForbidden, "FORBIDDEN")
+
+	expectStatus(t, env.requestAs(t, lead, http.MethodPost, "/pullRequest/create",
+		map[string]any{"pull_request_id": "pr-1", "pull_request_name": "Search", "author_id": "u1"}),
+		http.StatusCreated, "")
+	expectStatus(t, env.requestAs(t, lead, http.MethodPost, "/pullRequest/create",
+		map[string]any{"pull_request_id": "pr-2", "pull_request_name": "Infra", "author_id": "p1"}),
+		http.StatusForbidden, "FORBIDDEN")
+
+	// Only admins may skip required approvals.
+	expectStatus(t, env.requestAs(t, lead, http.MethodPost, "/pullRequest/merge",
+		map[string]any{"pull_request_id": "pr-1", "override": true}),
+		http.StatusForbidden, "FORBIDDEN")
+	expectStatus(t, env.requestAs(t, admin, http.MethodPost, "/pullRequest/merge",
+		map[string]any{"pull_request_id": "pr-1", "override": true}),
+		http.StatusOK, "")
+
+	if err := keys.Revoke(ctx, leadKey.ID); err != nil {
+		t.Fatalf("revoke: %v", err)
+	}
+	expectStatus(t, env.requestAs(t, lead, http.MethodGet, "/team/get?team_name=backend", nil),
+		http.StatusUnauthorized, "UNAUTHORIZED")
+}
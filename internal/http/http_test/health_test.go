@@ -0,0 +1,78 @@
+package http_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"testing"
+
+	httphandler "pr-reviewer-service/internal/http"
+)
+
+type readinessBody struct {
+	Status string `json:"status"`
+	Checks []struct {
+		Name      string  `json:"name"`
+		Status    string  `json:"status"`
+		LatencyMS float64 `json:"latency_ms"`
+		Error     string  `json:"error"`
+	} `json:"checks"`
+}
+
+func getReadiness(t *testing.T, env *testEnv, wantStatus int) readinessBody {
+	t.Helper()
+	resp := env.get(t, "/readyz")
+	defer func() {
+		_ = resp.Body.Close()
+	}()
+	if resp.StatusCode != wantStatus {
+		t.Fatalf("expected /readyz status %d, got %d", wantStatus, resp.StatusCode)
+	}
+	var body readinessBody
+	if err := json.NewDecoder(resp.Body).Decode(&body); err != nil {
+		t.Fatalf("decode /readyz: %v", err)
+	}
+	return body
+}
+
+func TestReadiness(t *testing.T) {
+	var dbErr error
+	var h *httphandler.Handler
+	env := newTestEnv(t, func(handler *httphandler.Handler) {
+		h = handler
+		h.EnableReadiness(
+			httphandler.ReadinessCheck{Name: "database", Check: func(ctx context.Context) error { return dbErr }},
+			httphandler.ReadinessCheck{Name: "migrations", Check: func(ctx context.Context) error { return nil }},
+		)
+	})
+
+	expectStatus(t, env.get(t, "/livez"), http.StatusOK, "")
+	expectStatus(t, env.get(t, "/health"), http.StatusOK, "")
+
+	body := getReadiness(t, env, http.StatusOK)
+	if body.Status != "ok" || len(body.Checks) != 2 || body.Checks[0].Name != "database" || body.Checks[1].Status != "ok" {
+		t.Fatalf("unexpected readiness: %+v", body)
+	}
+
+	dbErr = errors.New("connection refused")
+	body = getReadiness(t, env, http.StatusServiceUnavailable)
+	if body.Status != "unavailable" || body.Checks[0].Status != "failed" ||
+		body.Checks[0].Error != "connection refused" || body.Checks[1].Status != "ok" {
+		t.Fatalf("unexpected readiness with the database down: %+v", body)
+	}
+
+	dbErr = nil
+	h.Drain()
+	if body = getReadiness(t, env, http.StatusServiceUnavailable); body.Status != "draining" {
+		t.Fatalf("expected draining, got %+v", body)
+	}
+	expectStatus(t, env.get(t, "/livez"), http.StatusOK, "")
+}
+
+func TestReadinessWithoutChecks(t *testing.T) {
+	env := newTestEnv(t)
+	if body := getReadiness(t, env, http.StatusOK); body.Status != "ok" || len(body.Checks) != 0 {
+		t.Fatalf("unexpected readiness: %+v", body)
+	}
+}
@@ -2,247 +2,40 @@ package http_test
 
 import (
 	"bytes"
-	"context"
 	"encoding/json"
 	"net/http"
 	"net/http/httptest"
 	"pr-reviewer-service/internal/service"
-	"sync"
 	"testing"
 	"time"
 
-	domain "pr-reviewer-service/internal/domain"
 	httphandler "pr-reviewer-service/internal/http"
+	"pr-reviewer-service/internal/repository/memory"
 )
 
-type inMemoryTeamRepo struct {
-	mu    sync.RWMutex
-	teams map[domain.TeamName]struct{}
-}
-
-func newInMemoryTeamRepo() *inMemoryTeamRepo {
-	return &inMemoryTeamRepo{
-		teams: make(map[domain.TeamName]struct{}),
-	}
-}
-
-type inMemoryPRRepo struct {
-	mu  sync.RWMutex
-	prs map[domain.PullRequestID]domain.PullRequest
-}
-
-func (r *inMemoryTeamRepo) CreateTeam(ctx context.Context, name domain.TeamName) error {
-	r.mu.Lock()
-	defer r.mu.Unlock()
-	if _, ok := r.teams[name]; ok {
-		return domain.ErrTeamExists
-	}
-	r.teams[name] = struct{}{}
-	return nil
-}
-
-func (r *inMemoryTeamRepo) GetTeam(ctx context.Context, name domain.TeamName) (domain.Team, error) {
-	r.mu.RLock()
-	defer r.mu.RUnlock()
-
-	if _, ok := r.teams[name]; !ok {
-		return domain.Team{}, domain.ErrNotFound
-	}
-
-	var t domain.Team
-	return t, nil
-}
-
-func (r *inMemoryTeamRepo) TeamExists(ctx context.Context, name domain.TeamName) (bool, error) {
-	r.mu.RLock()
-	defer r.mu.RUnlock()
-	_, ok := r.teams[name]
-	return ok, nil
-}
-
-type inMemoryUserRepo struct {
-	mu    sync.RWMutex
-	users map[domain.UserID]domain.User
-}
-
-func newInMemoryUserRepo() *inMemoryUserRepo {
-	return &inMemoryUserRepo{
-		users: make(map[domain.UserID]domain.User),
-	}
-}
-
-func (r *inMemoryUserRepo) UpsertUsers(ctx context.Context, users []domain.User) error {
-	r.mu.Lock()
-	defer r.mu.Unlock()
-	for _, u := range users {
-		r.users[u.ID] = u
-	}
-	return nil
-}
-
-func (r *inMemoryUserRepo) GetByID(ctx context.Context, id domain.UserID) (domain.User, error) {
-	r.mu.RLock()
-	defer r.mu.RUnlock()
-	u, ok := r.users[id]
-	if !ok {
-		return domain.User{}, domain.ErrNotFound
-	}
-	return u, nil
-}
-
-func (r *inMemoryUserRepo) SetIsActive(ctx context.Context, id domain.UserID, isActive bool) (domain.User, error) {
-	r.mu.Lock()
-	defer r.mu.Unlock()
-	u, ok := r.users[id]
-	if !ok {
-		return domain.User{}, domain.ErrNotFound
-	}
-	u.IsActive = isActive
-	r.users[id] = u
-	return u, nil
-}
-
-func (r *inMemoryUserRepo) ListActiveByTeam(ctx context.Context, teamName domain.TeamName) ([]domain.User, error) {
-	r.mu.RLock()
-	defer r.mu.RUnlock()
-	res := make([]domain.User, 0)
-	for _, u := range r.users {
-		if u.TeamName == teamName && u.IsActive {
-			res = append(res, u)
-		}
-	}
-	return res, nil
-}
-
-func newInMemoryPRRepo() *inMemoryPRRepo {
-	return &inMemoryPRRepo{
-		prs: make(map[domain.PullRequestID]domain.PullRequest),
-	}
-}
-
-func (r *inMemoryPRRepo) Create(ctx context.Context, pr domain.PullRequest) error {
-	r.mu.Lock()
-	defer r.mu.Unlock()
-	if _, exists := r.prs[pr.ID]; exists {
-		return domain.ErrPullRequestExists
-	}
-	r.prs[pr.ID] = pr
-	return nil
-}
-
-func (r *inMemoryPRRepo) Exists(ctx context.Context, id domain.PullRequestID) (bool, error) {
-	r.mu.RLock()
-	defer r.mu.RUnlock()
-	_, ok := r.prs[id]
-	return ok, nil
-}
-
-func (r *inMemoryPRRepo) Get(ctx context.Context, id domain.PullRequestID) (domain.PullRequest, error) {
-	r.mu.RLock()
-	defer r.mu.RUnlock()
-	pr, ok := r.prs[id]
-	if !ok {
-		return domain.PullRequest{}, domain.ErrNotFound
-	}
-	return pr, nil
-}
-
-func (r *inMemoryPRRepo) MarkMerged(ctx context.Context, id domain.PullRequestID, mergedAt time.Time) error {
-	r.mu.Lock()
-	defer r.mu.Unlock()
-	pr, ok := r.prs[id]
-	if !ok {
-		return domain.ErrNotFound
-	}
-	if pr.Status == domain.PRStatusMerged {
-		return nil
-	}
-	pr.Status = domain.PRStatusMerged
-	pr.MergedAt = &mergedAt
-	r.prs[id] = pr
-	return nil
-}
-
-func (r *inMemoryPRRepo) StatsAssignmentsByUser(
-	ctx context.Context,
-) (map[domain.UserID]int, error) {
-	r.mu.RLock()
-	defer r.mu.RUnlock()
-
-	res := make(map[domain.UserID]int)
-
-	for _, pr := range r.prs {
-		for _, rid := range pr.AssignedReviewers {
-			res[rid]++
-		}
-	}
-
-	return res, nil
-}
-
-func (r *inMemoryPRRepo) ReplaceReviewer(ctx context.Context, prID domain.PullRequestID, oldUserID, newUserID domain.UserID) error {
-	r.mu.Lock()
-	defer r.mu.Unlock()
-
-	pr, ok := r.prs[prID]
-	if !ok {
-		return domain.ErrNotFound
-	}
-
-	found := false
-	for i, id := range pr.AssignedReviewers {
-		if id == oldUserID {
-			pr.AssignedReviewers[i] = newUserID
-			found = true
-			break
-		}
-	}
-	if !found {
-		return domain.ErrNotAssigned
-	}
-
-	r.prs[prID] = pr
-	return nil
-}
-
-func (r *inMemoryPRRepo) ListByReviewer(ctx context.Context, reviewerID domain.UserID) ([]domain.PullRequestShort, error) {
-	r.mu.RLock()
-	defer r.mu.RUnlock()
-
-	result := make([]domain.PullRequestShort, 0)
-	for _, pr := range r.prs {
-		for _, rid := range pr.AssignedReviewers {
-			if rid == reviewerID {
-				result = append(result, domain.PullRequestShort{
-					ID:       pr.ID,
-					Name:     pr.Name,
-					AuthorID: pr.AuthorID,
-					Status:   pr.Status,
-				})
-				break
-			}
-		}
-	}
-	return result, nil
-}
-
 type testEnv struct {
 	server *httptest.Server
 	client *http.Client
+	store  *memory.Store
 }
 
-func newTestEnv(t *testing.T) *testEnv {
+func newTestEnv(t *testing.T, configure ...func(h *httphandler.Handler)) *testEnv {
 	t.Helper()
 
-	teamRepo := newInMemoryTeamRepo()
-	userRepo := newInMemoryUserRepo()
-	prRepo := newInMemoryPRRepo()
+	store := memory.NewStore()
+	teamRepo := memory.NewTeamRepo(store)
+	userRepo := memory.NewUserRepo(store)
+	prRepo := memory.NewPullRequestRepo(store)
 
 	teamSvc := service.NewTeamService(teamRepo, userRepo)
 	userSvc := service.NewUserService(userRepo)
-	prSvc := service.NewPRService(userRepo, prRepo)
+	prSvc := service.NewPRService(teamRepo, userRepo, prRepo)
+	prSvc.Tx = memory.NewUnitOfWork(store)
 
 	h := httphandler.NewHandler(teamSvc, userSvc, prSvc)
+	for _, c := range configure {
+		c(h)
+	}
 	mux := http.NewServeMux()
 	h.RegisterRoutes(mux)
 
@@ -252,6 +45,7 @@ func newTestEnv(t *testing.T) *testEnv {
 	return &testEnv{
 		server: srv,
 		client: srv.Client(),
+		store:  store,
 	}
 }
 
@@ -489,6 +283,27 @@ func TestReassignReviewerAndValidation(t *testing.T) {
 		}
 	}
 
+	resp = env.get(t, "/pullRequest/history?pull_request_id=pr-2001")
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("expected status 200 on /pullRequest/history, got %d", resp.StatusCode)
+	}
+	var historyResp struct {
+		Events []struct {
+			Type       string `json:"type"`
+			UserID     string `json:"user_id"`
+			ReplacedBy string `json:"replaced_by"`
+			Reason     string `json:"reason"`
+		} `json:"events"`
+	}
+	decodeBody(t, resp, &historyResp)
+	last := historyResp.Events[len(historyResp.Events)-1]
+	if last.Type != "REPLACED" || last.UserID != oldReviewer || last.ReplacedBy != reassignResp.ReplacedBy {
+		t.Fatalf("unexpected last history event: %+v", last)
+	}
+	if last.Reason != "manual_reassign" {
+		t.Fatalf("expected reason manual_reassign, got %s", last.Reason)
+	}
+
 	mergeReq := map[string]any{
 		"pull_request_id": "pr-2001",
 	}
@@ -508,3 +323,516 @@ func TestReassignReviewerAndValidation(t *testing.T) {
 		t.Fatalf("expected error code PR_MERGED, got %s", errResp.Error.Code)
 	}
 }
+
+func TestTeamSettingsControlReviewerCount(t *testing.T) {
+	env := newTestEnv(t)
+
+	teamReq := map[string]any{
+		"team_name": "docs",
+		"members": []map[string]any{
+			{"user_id": "d1", "username": "Alice", "is_active": true},
+			{"user_id": "d2", "username": "Bob", "is_active": true},
+			{"user_id": "d3", "username": "Charlie", "is_active": true},
+		},
+	}
+	resp := env.postJSON(t, "/team/add", teamReq)
+	if resp.StatusCode != http.StatusCreated {
+		t.Fatalf("expected status 201 on /team/add, got %d", resp.StatusCode)
+	}
+	_ = resp.Body.Close()
+
+	settingsReq := map[string]any{
+		"team_name":       "docs",
+		"reviewers_count": 1,
+		"strategy":        "least_loaded",
+		"min_pool_size":   0,
+	}
+	resp = env.postJSON(t, "/team/settings", settingsReq)
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("expected status 200 on /team/settings, got %d", resp.StatusCode)
+	}
+	_ = resp.Body.Close()
+
+	resp = env.get(t, "/team/settings?team_name=docs")
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("expected status 200 on GET /team/settings, got %d", resp.StatusCode)
+	}
+	var settingsResp struct {
+		ReviewersCount int    `json:"reviewers_count"`
+		Strategy       string `json:"strategy"`
+	}
+	decodeBody(t, resp, &settingsResp)
+	if settingsResp.ReviewersCount != 1 || settingsResp.Strategy != "least_loaded" {
+		t.Fatalf("unexpected settings: %+v", settingsResp)
+	}
+
+	resp = env.postJSON(t, "/pullRequest/create", map[string]any{
+		"pull_request_id":   "pr-docs-1",
+		"pull_request_name": "Fix typos",
+		"author_id":         "d1",
+	})
+	if resp.StatusCode != http.StatusCreated {
+		t.Fatalf("expected status 201 on /pullRequest/create, got %d", resp.StatusCode)
+	}
+	var prResp prResponse
+	decodeBody(t, resp, &prResp)
+	if len(prResp.PR.AssignedReviewers) != 1 {
+		t.Fatalf("expected exactly 1 reviewer, got %v", prResp.PR.AssignedReviewers)
+	}
+
+	settingsReq["strategy"] = "round_robin"
+	resp = env.postJSON(t, "/team/settings", settingsReq)
+	if resp.StatusCode != http.StatusBadRequest {
+		t.Fatalf("expected status 400 on invalid strategy, got %d", resp.StatusCode)
+	}
+	_ = resp.Body.Close()
+}
+
+func TestUserUnavailabilityExcludesFromSelection(t *testing.T) {
+	env := newTestEnv(t)
+
+	teamReq := map[string]any{
+		"team_name": "infra",
+		"members": []map[string]any{
+			{"user_id": "i1", "username": "Alice", "is_active": true},
+			{"user_id": "i2", "username": "Bob", "is_active": true},
+			{"user_id": "i3", "username": "Charlie", "is_active": true},
+		},
+	}
+	resp := env.postJSON(t, "/team/add", teamReq)
+	if resp.StatusCode != http.StatusCreated {
+		t.Fatalf("expected status 201 on /team/add, got %d", resp.StatusCode)
+	}
+	_ = resp.Body.Close()
+
+	now := time.Now().UTC()
+	resp = env.postJSON(t, "/users/unavailability", map[string]any{
+		"user_id":   "i2",
+		"starts_at": now.Add(-time.Hour).Format(time.RFC3339),
+		"ends_at":   now.Add(24 * time.Hour).Format(time.RFC3339),
+		"reason":    "vacation",
+	})
+	if resp.StatusCode != http.StatusCreated {
+		t.Fatalf("expected status 201 on /users/unavailability, got %d", resp.StatusCode)
+	}
+	var addResp struct {
+		Unavailability struct {
+			UnavailabilityID int64  `json:"unavailability_id"`
+			Reason           string `json:"reason"`
+		} `json:"unavailability"`
+	}
+	decodeBody(t, resp, &addResp)
+	if addResp.Unavailability.UnavailabilityID == 0 || addResp.Unavailability.Reason != "vacation" {
+		t.Fatalf("unexpected unavailability response: %+v", addResp)
+	}
+
+	resp = env.postJSON(t, "/pullRequest/create", map[string]any{
+		"pull_request_id":   "pr-infra-1",
+		"pull_request_name": "Bump terraform",
+		"author_id":         "i1",
+	})
+	if resp.StatusCode != http.StatusCreated {
+		t.Fatalf("expected status 201 on /pullRequest/create, got %d", resp.StatusCode)
+	}
+	var prResp prResponse
+	decodeBody(t, resp, &prResp)
+	if len(prResp.PR.AssignedReviewers) != 1 || prResp.PR.AssignedReviewers[0] != "i3" {
+		t.Fatalf("expected only i3 to be assigned, got %v", prResp.PR.AssignedReviewers)
+	}
+
+	resp = env.get(t, "/users/unavailability?user_id=i2")
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("expected status 200 on GET /users/unavailability, got %d", resp.StatusCode)
+	}
+	var listResp struct {
+		Unavailability []struct {
+			UnavailabilityID int64 `json:"unavailability_id"`
+		} `json:"unavailability"`
+	}
+	decodeBody(t, resp, &listResp)
+	if len(listResp.Unavailability) != 1 {
+		t.Fatalf("expected 1 unavailability period, got %d", len(listResp.Unavailability))
+	}
+
+	resp = env.postJSON(t, "/users/unavailability", map[string]any{
+		"user_id":   "i2",
+		"starts_at": now.Format(time.RFC3339),
+		"ends_at":   now.Add(-time.Hour).Format(time.RFC3339),
+	})
+	if resp.StatusCode != http.StatusBadRequest {
+		t.Fatalf("expected status 400 on inverted period, got %d", resp.StatusCode)
+	}
+	_ = resp.Body.Close()
+
+	resp = env.postJSON(t, "/users/unavailability/delete", map[string]any{
+		"unavailability_id": addResp.Unavailability.UnavailabilityID,
+	})
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("expected status 200 on /users/unavailability/delete, got %d", resp.StatusCode)
+	}
+	_ = resp.Body.Close()
+
+	resp = env.postJSON(t, "/users/unavailability/delete", map[string]any{
+		"unavailability_id": addResp.Unavailability.UnavailabilityID,
+	})
+	if resp.StatusCode != http.StatusNotFound {
+		t.Fatalf("expected status 404 on repeated delete, got %d", resp.StatusCode)
+	}
+	_ = resp.Body.Close()
+}
+
+func TestReviewerCapacity(t *testing.T) {
+	env := newTestEnv(t)
+
+	teamReq := map[string]any{
+		"team_name": "mobile",
+		"members": []map[string]any{
+			{"user_id": "m1", "username": "Alice", "is_active": true},
+			{"user_id": "m2", "username": "Bob", "is_active": true},
+		},
+	}
+	resp := env.postJSON(t, "/team/add", teamReq)
+	if resp.StatusCode != http.StatusCreated {
+		t.Fatalf("expected status 201 on /team/add, got %d", resp.StatusCode)
+	}
+	_ = resp.Body.Close()
+
+	resp = env.postJSON(t, "/users/setMaxOpenReviews", map[string]any{
+		"user_id":          "m2",
+		"max_open_reviews": 1,
+	})
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("expected status 200 on /users/setMaxOpenReviews, got %d", resp.StatusCode)
+	}
+	var userResp struct {
+		User struct {
+			MaxOpenReviews int `json:"max_open_reviews"`
+		} `json:"user"`
+	}
+	decodeBody(t, resp, &userResp)
+	if userResp.User.MaxOpenReviews != 1 {
+		t.Fatalf("expected max_open_reviews 1, got %d", userResp.User.MaxOpenReviews)
+	}
+
+	resp = env.postJSON(t, "/users/setMaxOpenReviews", map[string]any{
+		"user_id":          "m2",
+		"max_open_reviews": -1,
+	})
+	if resp.StatusCode != http.StatusBadRequest {
+		t.Fatalf("expected status 400 on negative capacity, got %d", resp.StatusCode)
+	}
+	_ = resp.Body.Close()
+
+	createReq := map[string]any{
+		"pull_request_id":   "pr-mobile-1",
+		"pull_request_name": "Dark mode",
+		"author_id":         "m1",
+	}
+	resp = env.postJSON(t, "/pullRequest/create", createReq)
+	if resp.StatusCode != http.StatusCreated {
+		t.Fatalf("expected status 201 on /pullRequest/create, got %d", resp.StatusCode)
+	}
+	_ = resp.Body.Close()
+
+	createReq["pull_request_id"] = "pr-mobile-2"
+	resp = env.postJSON(t, "/pullRequest/create", createReq)
+	if resp.StatusCode != http.StatusUnprocessableEntity {
+		t.Fatalf("expected status 422 when everyone is at capacity, got %d", resp.StatusCode)
+	}
+	var errResp errorResponse
+	decodeBody(t, resp, &errResp)
+	if errResp.Error.Code != "ALL_AT_CAPACITY" {
+		t.Fatalf("expected ALL_AT_CAPACITY, got %q", errResp.Error.Code)
+	}
+}
+
+func TestCodeownersPreferredOnCreate(t *testing.T) {
+	env := newTestEnv(t)
+
+	teamReq := map[string]any{
+		"team_name": "search",
+		"members": []map[string]any{
+			{"user_id": "s1", "username": "alice", "is_active": true},
+			{"user_id": "s2", "username": "bob", "is_active": true},
+			{"user_id": "s3", "username": "carol", "is_active": true},
+			{"user_id": "s4", "username": "dave", "is_active": true},
+		},
+	}
+	resp := env.postJSON(t, "/team/add", teamReq)
+	if resp.StatusCode != http.StatusCreated {
+		t.Fatalf("expected status 201 on /team/add, got %d", resp.StatusCode)
+	}
+	_ = resp.Body.Close()
+
+	resp = env.postJSON(t, "/team/codeowners", map[string]any{
+		"team_name":  "search",
+		"codeowners": "src/[a-z].go @bob\n",
+	})
+	if resp.StatusCode != http.StatusBadRequest {
+		t.Fatalf("expected status 400 on invalid CODEOWNERS, got %d", resp.StatusCode)
+	}
+	_ = resp.Body.Close()
+
+	resp = env.postJSON(t, "/team/codeowners", map[string]any{
+		"team_name":  "search",
+		"codeowners": "# search\n/indexer/ @carol\n",
+	})
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("expected status 200 on /team/codeowners, got %d", resp.StatusCode)
+	}
+	_ = resp.Body.Close()
+
+	resp = env.get(t, "/team/codeowners?team_name=search")
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("expected status 200 on GET /team/codeowners, got %d", resp.StatusCode)
+	}
+	var ownersResp struct {
+		Rules []struct {
+			Line    int      `json:"line"`
+			Pattern string   `json:"pattern"`
+			Owners  []string `json:"owners"`
+		} `json:"rules"`
+	}
+	decodeBody(t, resp, &ownersResp)
+	if len(ownersResp.Rules) != 1 || ownersResp.Rules[0].Line != 2 || ownersResp.Rules[0].Pattern != "/indexer/" {
+		t.Fatalf("unexpected rules: %+v", ownersResp.Rules)
+	}
+
+	resp = env.postJSON(t, "/pullRequest/create", map[string]any{
+		"pull_request_id":   "pr-search-1",
+		"pull_request_name": "Faster indexing",
+		"author_id":         "s1",
+		"files":             []string{"indexer/segment.go", "README.md"},
+	})
+	if resp.StatusCode != http.StatusCreated {
+		t.Fatalf("expected status 201 on /pullRequest/create, got %d", resp.StatusCode)
+	}
+	var prResp struct {
+		PR struct {
+			AssignedReviewers []string `json:"assigned_reviewers"`
+			MatchedRules      []struct {
+				UserID string `json:"user_id"`
+				Rule   string `json:"rule"`
+			} `json:"matched_rules"`
+		} `json:"pr"`
+	}
+	decodeBody(t, resp, &prResp)
+	if len(prResp.PR.AssignedReviewers) != 2 || prResp.PR.AssignedReviewers[0] != "s3" {
+		t.Fatalf("expected code owner s3 first, got %v", prResp.PR.AssignedReviewers)
+	}
+	if len(prResp.PR.MatchedRules) != 1 || prResp.PR.MatchedRules[0].UserID != "s3" || prResp.PR.MatchedRules[0].Rule != "/indexer/" {
+		t.Fatalf("unexpected matched rules: %+v", prResp.PR.MatchedRules)
+	}
+}
+
+func TestReviewApprovalsGateMerge(t *testing.T) {
+	env := newTestEnv(t)
+
+	teamReq := map[string]any{
+		"team_name": "payments",
+		"members": []map[string]any{
+			{"user_id": "p1", "username": "Alice", "is_active": true},
+			{"user_id": "p2", "username": "Bob", "is_active": true},
+		},
+	}
+	resp := env.postJSON(t, "/team/add", teamReq)
+	if resp.StatusCode != http.StatusCreated {
+		t.Fatalf("expected status 201 on /team/add, got %d", resp.StatusCode)
+	}
+	_ = resp.Body.Close()
+
+	resp = env.postJSON(t, "/team/settings", map[string]any{
+		"team_name":          "payments",
+		"reviewers_count":    1,
+		"strategy":           "random",
+		"min_pool_size":      0,
+		"required_approvals": 1,
+	})
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("expected status 200 on /team/settings, got %d", resp.StatusCode)
+	}
+	_ = resp.Body.Close()
+
+	resp = env.postJSON(t, "/pullRequest/create", map[string]any{
+		"pull_request_id":   "pr-pay-1",
+		"pull_request_name": "Refund flow",
+		"author_id":         "p1",
+	})
+	if resp.StatusCode != http.StatusCreated {
+		t.Fatalf("expected status 201 on /pullRequest/create, got %d", resp.StatusCode)
+	}
+	_ = resp.Body.Close()
+
+	resp = env.postJSON(t, "/pullRequest/merge", map[string]any{"pull_request_id": "pr-pay-1"})
+	if resp.StatusCode != http.StatusConflict {
+		t.Fatalf("expected status 409 on merge without approvals, got %d", resp.StatusCode)
+	}
+	var errResp errorResponse
+	decodeBody(t, resp, &errResp)
+	if errResp.Error.Code != "NOT_ENOUGH_APPROVALS" {
+		t.Fatalf("expected NOT_ENOUGH_APPROVALS, got %s", errResp.Error.Code)
+	}
+
+	resp = env.postJSON(t, "/pullRequest/review", map[string]any{
+		"pull_request_id": "pr-pay-1",
+		"reviewer_id":     "p1",
+		"state":           "APPROVED",
+	})
+	if resp.StatusCode != http.StatusConflict {
+		t.Fatalf("expected status 409 when author reviews, got %d", resp.StatusCode)
+	}
+	_ = resp.Body.Close()
+
+	resp = env.postJSON(t, "/pullRequest/review", map[string]any{
+		"pull_request_id": "pr-pay-1",
+		"reviewer_id":     "p2",
+		"state":           "LGTM",
+	})
+	if resp.StatusCode != http.StatusBadRequest {
+		t.Fatalf("expected status 400 on invalid state, got %d", resp.StatusCode)
+	}
+	_ = resp.Body.Close()
+
+	resp = env.postJSON(t, "/pullRequest/review", map[string]any{
+		"pull_request_id": "pr-pay-1",
+		"reviewer_id":     "p2",
+		"state":           "APPROVED",
+	})
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("expected status 200 on /pullRequest/review, got %d", resp.StatusCode)
+	}
+	var reviewResp struct {
+		PR struct {
+			Reviews []struct {
+				UserID string `json:"user_id"`
+				State  string `json:"state"`
+			} `json:"reviews"`
+		} `json:"pr"`
+	}
+	decodeBody(t, resp, &reviewResp)
+	if len(reviewResp.PR.Reviews) != 1 || reviewResp.PR.Reviews[0].UserID != "p2" || reviewResp.PR.Reviews[0].State != "APPROVED" {
+		t.Fatalf("unexpected reviews: %+v", reviewResp.PR.Reviews)
+	}
+
+	resp = env.postJSON(t, "/pullRequest/merge", map[string]any{"pull_request_id": "pr-pay-1"})
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("expected status 200 on merge after approval, got %d", resp.StatusCode)
+	}
+	_ = resp.Body.Close()
+
+	resp = env.postJSON(t, "/pullRequest/create", map[string]any{
+		"pull_request_id":   "pr-pay-2",
+		"pull_request_name": "Hotfix",
+		"author_id":         "p1",
+	})
+	if resp.StatusCode != http.StatusCreated {
+		t.Fatalf("expected status 201 on /pullRequest/create, got %d", resp.StatusCode)
+	}
+	_ = resp.Body.Close()
+
+	resp = env.postJSON(t, "/pullRequest/merge", map[string]any{"pull_request_id": "pr-pay-2", "override": true})
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("expected status 200 on overridden merge, got %d", resp.StatusCode)
+	}
+	_ = resp.Body.Close()
+}
+
+func TestDraftCloseReopenEndpoints(t *testing.T) {
+	env := newTestEnv(t)
+
+	teamReq := map[string]any{
+		"team_name": "mobile",
+		"members": []map[string]any{
+			{"user_id": "m1", "username": "Alice", "is_active": true},
+			{"user_id": "m2", "username": "Bob", "is_active": true},
+		},
+	}
+	resp := env.postJSON(t, "/team/add", teamReq)
+	if resp.StatusCode != http.StatusCreated {
+		t.Fatalf("expected status 201 on /team/add, got %d", resp.StatusCode)
+	}
+	_ = resp.Body.Close()
+
+	resp = env.postJSON(t, "/pullRequest/create", map[string]any{
+		"pull_request_id":   "pr-m-1",
+		"pull_request_name": "Offline mode",
+		"author_id":         "m1",
+		"draft":             true,
+	})
+	if resp.StatusCode != http.StatusCreated {
+		t.Fatalf("expected status 201 on draft /pullRequest/create, got %d", resp.StatusCode)
+	}
+	var prResp prResponse
+	decodeBody(t, resp, &prResp)
+	if prResp.PR.Status != "DRAFT" || len(prResp.PR.AssignedReviewers) != 0 {
+		t.Fatalf("expected reviewerless DRAFT, got %+v", prResp.PR)
+	}
+
+	resp = env.postJSON(t, "/pullRequest/merge", map[string]any{"pull_request_id": "pr-m-1"})
+	if resp.StatusCode != http.StatusConflict {
+		t.Fatalf("expected status 409 merging a draft, got %d", resp.StatusCode)
+	}
+	var errResp errorResponse
+	decodeBody(t, resp, &errResp)
+	if errResp.Error.Code != "INVALID_TRANSITION" {
+		t.Fatalf("expected INVALID_TRANSITION, got %s", errResp.Error.Code)
+	}
+
+	resp = env.postJSON(t, "/pullRequest/ready", map[string]any{"pull_request_id": "pr-m-1"})
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("expected status 200 on /pullRequest/ready, got %d", resp.StatusCode)
+	}
+	decodeBody(t, resp, &prResp)
+	if prResp.PR.Status != "OPEN" || len(prResp.PR.AssignedReviewers) != 1 || prResp.PR.AssignedReviewers[0] != "m2" {
+		t.Fatalf("expected OPEN with reviewer m2, got %+v", prResp.PR)
+	}
+
+	resp = env.postJSON(t, "/pullRequest/close", map[string]any{"pull_request_id": "pr-m-1"})
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("expected status 200 on /pullRequest/close, got %d", resp.StatusCode)
+	}
+	var closeResp struct {
+		PR struct {
+			Status   string `json:"status"`
+			ClosedAt string `json:"closedAt"`
+		} `json:"pr"`
+	}
+	decodeBody(t, resp, &closeResp)
+	if closeResp.PR.Status != "CLOSED" || closeResp.PR.ClosedAt == "" {
+		t.Fatalf("expected CLOSED with closedAt, got %+v", closeResp.PR)
+	}
+
+	resp = env.postJSON(t, "/pullRequest/review", map[string]any{
+		"pull_request_id": "pr-m-1",
+		"reviewer_id":     "m2",
+		"state":           "APPROVED",
+	})
+	if resp.StatusCode != http.StatusConflict {
+		t.Fatalf("expected status 409 reviewing a closed PR, got %d", resp.StatusCode)
+	}
+	decodeBody(t, resp, &errResp)
+	if errResp.Error.Code != "PR_CLOSED" {
+		t.Fatalf("expected PR_CLOSED, got %s", errResp.Error.Code)
+	}
+
+	resp = env.postJSON(t, "/pullRequest/reopen", map[string]any{"pull_request_id": "pr-m-1"})
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("expected status 200 on /pullRequest/reopen, got %d", resp.StatusCode)
+	}
+	decodeBody(t, resp, &prResp)
+	if prResp.PR.Status != "OPEN" {
+		t.Fatalf("expected OPEN after reopen, got %s", prResp.PR.Status)
+	}
+
+	resp = env.postJSON(t, "/pullRequest/merge", map[string]any{"pull_request_id": "pr-m-1"})
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("expected status 200 on merge, got %d", resp.StatusCode)
+	}
+	_ = resp.Body.Close()
+
+	resp = env.postJSON(t, "/pullRequest/reopen", map[string]any{"pull_request_id": "pr-m-1"})
+	if resp.StatusCode != http.StatusConflict {
+		t.Fatalf("expected status 409 reopening a merged PR, got %d", resp.StatusCode)
+	}
+	_ = resp.Body.Close()
+}
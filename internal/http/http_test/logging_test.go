@@ -0,0 +1,103 @@
+package http_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"pr-reviewer-service/internal/domain"
+	httphandler "pr-reviewer-service/internal/http"
+	"pr-reviewer-service/internal/repository/memory"
+	"pr-reviewer-service/internal/service"
+)
+
+type brokenUserRepo struct {
+	domain.UserRepository
+}
+
+func (brokenUserRepo) SetIsActive(ctx context.Context, id domain.UserID, isActive bool, actor domain.UserID) (domain.User, error) {
+	return domain.User{}, errors.New("connection reset by peer")
+}
+
+func TestRequestLogging(t *testing.T) {
+	store := memory.NewStore()
+	users := brokenUserRepo{memory.NewUserRepo(store)}
+	h := httphandler.NewHandler(
+		service.NewTeamService(memory.NewTeamRepo(store), users),
+		service.NewUserService(users),
+		service.NewPRService(memory.NewTeamRepo(store), users, memory.NewPullRequestRepo(store)),
+	)
+	mux := http.NewServeMux()
+	h.RegisterRoutes(mux)
+
+	var logs bytes.Buffer
+	srv := httptest.NewServer(httphandler.LogRequests(slog.New(slog.NewJSONHandler(&logs, nil)), mux))
+	t.Cleanup(srv.Close)
+
+	req, _ := http.NewRequest(http.MethodPost, srv.URL+"/users/setIsActive",
+		strings.NewReader(`{"user_id":"u1","is_active":false}`))
+	req.Header.Set(httphandler.RequestIDHeader, "req-42")
+	resp, err := srv.Client().Do(req)
+	if err != nil {
+		t.Fatalf("do request: %v", err)
+	}
+	if got := resp.Header.Get(httphandler.RequestIDHeader); got != "req-42" {
+		t.Fatalf("expected the caller's request id back, got %q", got)
+	}
+	var body struct {
+		Error struct {
+			Code      string `json:"code"`
+			RequestID string `json:"request_id"`
+		} `json:"error"`
+	}
+	decodeBody(t, resp, &body)
+	if body.Error.Code != "INTERNAL" || body.Error.RequestID != "req-42" {
+		t.Fatalf("unexpected error body: %+v", body)
+	}
+
+	resp, err = srv.Client().Get(srv.URL + "/health")
+	if err != nil {
+		t.Fatalf("get health: %v", err)
+	}
+	_ = resp.Body.Close()
+	generated := resp.Header.Get(httphandler.RequestIDHeader)
+	if generated == "" {
+		t.Fatalf("expected a generated request id")
+	}
+
+	var lines []map[string]any
+	for _, line := range strings.Split(strings.TrimSpace(logs.String()), "\n") {
+		var entry map[string]any
+		if err := json.Unmarshal([]byte(line), &entry); err != nil {
+			t.Fatalf("log line is not JSON: %q", line)
+		}
+		lines = append(lines, entry)
+	}
+	if len(lines) != 2 {
+		t.Fatalf("expected 2 log lines, got %d: %s", len(lines), logs.String())
+	}
+
+	failed := lines[0]
+	if failed["level"] != "ERROR" || failed["request_id"] != "req-42" || failed["method"] != "POST" ||
+		failed["route"] != "/users/setIsActive" || failed["status"] != float64(500) ||
+		failed["error"] != "connection reset by peer" {
+		t.Fatalf("unexpected log line: %v", failed)
+	}
+	if _, ok := failed["latency_ms"]; !ok {
+		t.Fatalf("log line lacks latency: %v", failed)
+	}
+
+	ok := lines[1]
+	if ok["level"] != "INFO" || ok["request_id"] != generated || ok["status"] != float64(200) {
+		t.Fatalf("unexpected log line: %v", ok)
+	}
+	if _, hasErr := ok["error"]; hasErr {
+		t.Fatalf("successful request logged an error: %v", ok)
+	}
+}
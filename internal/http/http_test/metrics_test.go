@@ -0,0 +1,74 @@
+package http_test
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	httphandler "pr-reviewer-service/internal/http"
+	"pr-reviewer-service/internal/metrics"
+	"pr-reviewer-service/internal/repository/memory"
+	"pr-reviewer-service/internal/service"
+)
+
+func TestMetricsEndpoint(t *testing.T) {
+	store := memory.NewStore()
+	teamRepo := memory.NewTeamRepo(store)
+	userRepo := memory.NewUserRepo(store)
+	prRepo := memory.NewPullRequestRepo(store)
+
+	reg := metrics.NewRegistry()
+	prSvc := service.NewPRService(teamRepo, userRepo, prRepo)
+	prSvc.Metrics = metrics.NewPRMetrics(reg, prRepo)
+
+	h := httphandler.NewHandler(service.NewTeamService(teamRepo, userRepo), service.NewUserService(userRepo), prSvc)
+	h.EnableMetrics(reg)
+	mux := http.NewServeMux()
+	h.RegisterRoutes(mux)
+	srv := httptest.NewServer(httphandler.InstrumentRequests(reg, mux))
+	t.Cleanup(srv.Close)
+	env := &testEnv{server: srv, client: srv.Client(), store: store}
+
+	expectStatus(t, env.postJSON(t, "/team/add", map[string]any{
+		"team_name": "backend",
+		"members": []map[string]any{
+			{"user_id": "u1", "username": "Alice", "is_active": true},
+			{"user_id": "u2", "username": "Bob", "is_active": true},
+		},
+	}), http.StatusCreated, "")
+	expectStatus(t, env.postJSON(t, "/pullRequest/create",
+		map[string]any{"pull_request_id": "pr-1", "pull_request_name": "Search", "author_id": "u1"}),
+		http.StatusCreated, "")
+	expectStatus(t, env.postJSON(t, "/pullRequest/reassign",
+		map[string]any{"pull_request_id": "pr-1", "old_user_id": "u2"}),
+		http.StatusConflict, "NO_CANDIDATE")
+	expectStatus(t, env.get(t, "/no/such/route"), http.StatusNotFound, "")
+
+	resp := env.get(t, "/metrics")
+	defer func() {
+		_ = resp.Body.Close()
+	}()
+	if resp.StatusCode != http.StatusOK || !strings.HasPrefix(resp.Header.Get("Content-Type"), "text/plain") {
+		t.Fatalf("unexpected /metrics response: %d %s", resp.StatusCode, resp.Header.Get("Content-Type"))
+	}
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		t.Fatalf("read body: %v", err)
+	}
+
+	for _, line := range []string{
+		`pr_reviewer_http_requests_total{method="POST",route="/pullRequest/create",status="201"} 1`,
+		`pr_reviewer_http_requests_total{method="GET",route="unmatched",status="404"} 1`,
+		`pr_reviewer_http_request_duration_seconds_count{method="POST",route="/team/add"} 1`,
+		`pr_reviewer_open_pull_requests{team="backend"} 1`,
+		`pr_reviewer_open_assignments{reviewer="u2"} 1`,
+		`pr_reviewer_understaffed_pull_requests_total{team="backend"} 1`,
+		`pr_reviewer_reassignment_no_candidate_total{reason="manual_reassign"} 1`,
+	} {
+		if !strings.Contains(string(body), line+"\n") {
+			t.Errorf("metrics lack %q:\n%s", line, body)
+		}
+	}
+}
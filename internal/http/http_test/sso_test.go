@@ -0,0 +1,140 @@
+package http_test
+
+import (
+	"crypto"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"math/big"
+	"net/http"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	httphandler "pr-reviewer-service/internal/http"
+	"pr-reviewer-service/internal/oidc"
+)
+
+// ssoSigner issues RS256 tokens trusted through a JWKS file.
+type ssoSigner struct {
+	key *rsa.PrivateKey
+}
+
+func newSSO(t *testing.T) (*ssoSigner, *oidc.Verifier) {
+	t.Helper()
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatalf("generate key: %v", err)
+	}
+	doc, _ := json.Marshal(map[string]any{"keys": []map[string]string{{
+		"kid": "test",
+		"kty": "RSA",
+		"n":   base64.RawURLEncoding.EncodeToString(key.N.Bytes()),
+		"e":   base64.RawURLEncoding.EncodeToString(big.NewInt(int64(key.E)).Bytes()),
+	}}})
+	path := filepath.Join(t.TempDir(), "jwks.json")
+	if err := os.WriteFile(path, doc, 0o600); err != nil {
+		t.Fatalf("write jwks: %v", err)
+	}
+
+	return &ssoSigner{key: key}, &oidc.Verifier{
+		Keys:     oidc.NewKeySource(path, ""),
+		Issuer:   "https://sso.test",
+		Audience: "pr-reviewer",
+	}
+}
+
+func (s *ssoSigner) token(t *testing.T, user, role string) string {
+	t.Helper()
+	claims := map[string]any{
+		"iss": "https://sso.test",
+		"aud": "pr-reviewer",
+		"sub": user,
+		"exp": time.Now().Add(time.Hour).Unix(),
+	}
+	if role != "" {
+		claims["role"] = role
+	}
+	header, _ := json.Marshal(map[string]string{"alg": "RS256", "kid": "test"})
+	payload, _ := json.Marshal(claims)
+	signed := base64.RawURLEncoding.EncodeToString(header) + "." + base64.RawURLEncoding.EncodeToString(payload)
+
+	sum := sha256.Sum256([]byte(signed))
+	sig, err := rsa.SignPKCS1v15(rand.Reader, s.key, crypto.SHA256, sum[:])
+	if err != nil {
+		t.Fatalf("sign: %v", err)
+	}
+	return signed + "." + base64.RawURLEncoding.EncodeToString(sig)
+}
+
+func TestSSORecordsActor(t *testing.T) {
+	signer, verifier := newSSO(t)
+	env := newTestEnv(t, func(h *httphandler.Handler) { h.EnableSSO(verifier) })
+
+	admin := signer.token(t, "ops", "admin")
+	expectStatus(t, env.requestAs(t, admin, http.MethodPost, "/team/add", map[string]any{
+		"team_name": "backend",
+		"members": []map[string]any{
+			{"user_id": "u1", "username": "Alice", "is_active": true},
+			{"user_id": "u2", "username": "Bob", "is_active": true},
+			{"user_id": "u3", "username": "Carol", "is_active": true},
+			{"user_id": "u4", "username": "Dave", "is_active": true},
+		},
+	}), http.StatusCreated, "")
+	expectStatus(t, env.requestAs(t, admin, http.MethodPost, "/team/settings",
+		map[string]any{"team_name": "backend", "reviewers_count": 1, "strategy": "random"}),
+		http.StatusOK, "")
+
+	forged, _ := newSSO(t)
+	expectStatus(t, env.requestAs(t, forged.token(t, "ops", "admin"), http.MethodGet, "/team/get?team_name=backend", nil),
+		http.StatusUnauthorized, "UNAUTHORIZED")
+
+	// Leads get their team from the user record, so u1 leads backend.
+	lead := signer.token(t, "u1", "team_lead")
+	resp := env.requestAs(t, lead, http.MethodPost, "/pullRequest/create",
+		map[string]any{"pull_request_id": "pr-1", "pull_request_name": "Search", "author_id": "u1"})
+	var created struct {
+		PR struct {
+			AssignedReviewers []string `json:"assigned_reviewers"`
+		} `json:"pr"`
+	}
+	decodeBody(t, resp, &created)
+	if len(created.PR.AssignedReviewers) != 1 {
+		t.Fatalf("expected one reviewer, got %v", created.PR.AssignedReviewers)
+	}
+	reviewer := created.PR.AssignedReviewers[0]
+
+	// Read-only users act on themselves only.
+	self := signer.token(t, reviewer, "")
+	expectStatus(t, env.requestAs(t, self, http.MethodPost, "/users/setIsActive",
+		map[string]any{"user_id": "u1", "is_active": false}),
+		http.StatusForbidden, "FORBIDDEN")
+	expectStatus(t, env.requestAs(t, self, http.MethodPost, "/pullRequest/merge",
+		map[string]any{"pull_request_id": "pr-1"}),
+		http.StatusForbidden, "FORBIDDEN")
+	expectStatus(t, env.requestAs(t, self, http.MethodPost, "/pullRequest/reassign",
+		map[string]any{"pull_request_id": "pr-1", "old_user_id": reviewer}),
+		http.StatusOK, "")
+
+	resp = env.requestAs(t, self, http.MethodGet, "/pullRequest/history?pull_request_id=pr-1", nil)
+	var history struct {
+		Events []struct {
+			Type    string `json:"type"`
+			ActorID string `json:"actor_id"`
+		} `json:"events"`
+	}
+	decodeBody(t, resp, &history)
+	if len(history.Events) != 2 {
+		t.Fatalf("expected 2 events, got %+v", history.Events)
+	}
+	if history.Events[0].ActorID != "u1" || history.Events[1].ActorID != reviewer {
+		t.Fatalf("unexpected actors: %+v", history.Events)
+	}
+
+	expectStatus(t, env.requestAs(t, self, http.MethodPost, "/users/setIsActive",
+		map[string]any{"user_id": reviewer, "is_active": false}),
+		http.StatusOK, "")
+}
@@ -0,0 +1,74 @@
+package http_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/propagation"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.opentelemetry.io/otel/trace"
+
+	httphandler "pr-reviewer-service/internal/http"
+	"pr-reviewer-service/internal/repository/memory"
+	"pr-reviewer-service/internal/service"
+)
+
+func TestTraceRequestsContinuesIncomingTrace(t *testing.T) {
+	exporter := tracetest.NewInMemoryExporter()
+	provider := sdktrace.NewTracerProvider(sdktrace.WithSyncer(exporter))
+	prevProvider, prevPropagator := otel.GetTracerProvider(), otel.GetTextMapPropagator()
+	otel.SetTracerProvider(provider)
+	otel.SetTextMapPropagator(propagation.TraceContext{})
+	t.Cleanup(func() {
+		otel.SetTracerProvider(prevProvider)
+		otel.SetTextMapPropagator(prevPropagator)
+	})
+
+	store := memory.NewStore()
+	teamRepo := memory.NewTeamRepo(store)
+	userRepo := memory.NewUserRepo(store)
+	h := httphandler.NewHandler(
+		service.NewTeamService(teamRepo, userRepo),
+		service.NewUserService(userRepo),
+		service.NewPRService(teamRepo, userRepo, memory.NewPullRequestRepo(store)),
+	)
+	mux := http.NewServeMux()
+	h.RegisterRoutes(mux)
+	srv := httptest.NewServer(httphandler.TraceRequests(mux))
+	t.Cleanup(srv.Close)
+
+	const traceID = "4bf92f3577b34da6a3ce929d0e0e4736"
+	const parentID = "00f067aa0ba902b7"
+	req, _ := http.NewRequest(http.MethodGet, srv.URL+"/team/get?team_name=missing", nil)
+	req.Header.Set("traceparent", "00-"+traceID+"-"+parentID+"-01")
+	resp, err := srv.Client().Do(req)
+	if err != nil {
+		t.Fatalf("do request: %v", err)
+	}
+	expectStatus(t, resp, http.StatusNotFound, "NOT_FOUND")
+
+	spans := exporter.GetSpans()
+	var server, service *tracetest.SpanStub
+	for i := range spans {
+		switch spans[i].Name {
+		case "GET /team/get":
+			server = &spans[i]
+		case "TeamService.GetTeam":
+			service = &spans[i]
+		}
+	}
+	if server == nil || service == nil {
+		t.Fatalf("expected server and service spans, got %v", spans)
+	}
+
+	if server.SpanKind != trace.SpanKindServer || server.SpanContext.TraceID().String() != traceID ||
+		server.Parent.SpanID().String() != parentID {
+		t.Fatalf("server span does not continue the incoming trace: %+v", server)
+	}
+	if service.Parent.SpanID() != server.SpanContext.SpanID() {
+		t.Fatalf("service span is not a child of the server span")
+	}
+}
@@ -0,0 +1,266 @@
+package http_test
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"net/http"
+	"os"
+	"path/filepath"
+	"testing"
+
+	httphandler "pr-reviewer-service/internal/http"
+	"pr-reviewer-service/internal/vcs"
+)
+
+const testGitHubSecret = "s3cr3t"
+
+func loadFixture(t *testing.T, name string) []byte {
+	t.Helper()
+	data, err := os.ReadFile(filepath.Join("testdata", name))
+	if err != nil {
+		t.Fatalf("read fixture %s: %v", name, err)
+	}
+	return data
+}
+
+func (e *testEnv) postGitHubEvent(t *testing.T, event string, body []byte, secret string) *http.Response {
+	t.Helper()
+	req, err := http.NewRequest(http.MethodPost, e.server.URL+"/hooks/github", bytes.NewReader(body))
+	if err != nil {
+		t.Fatalf("new request: %v", err)
+	}
+
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write(body)
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("X-GitHub-Event", event)
+	req.Header.Set("X-Hub-Signature-256", "sha256="+hex.EncodeToString(mac.Sum(nil)))
+
+	resp, err := e.client.Do(req)
+	if err != nil {
+		t.Fatalf("do request: %v", err)
+	}
+	return resp
+}
+
+func TestGitHubWebhookOpensAndMergesPR(t *testing.T) {
+	env := newTestEnv(t, func(h *httphandler.Handler) {
+		h.EnableGitHubWebhook([]byte(testGitHubSecret), vcs.IdentityMap{
+			vcs.ProviderGitHub: {"alice-gh": "u1", "bob-gh": "u2"},
+		})
+	})
+
+	resp := env.postJSON(t, "/team/add", map[string]any{
+		"team_name": "backend",
+		"members": []map[string]any{
+			{"user_id": "u1", "username": "Alice", "is_active": true},
+			{"user_id": "u2", "username": "Bob", "is_active": true},
+			{"user_id": "u3", "username": "Charlie", "is_active": true},
+		},
+	})
+	if resp.StatusCode != http.StatusCreated {
+		t.Fatalf("expected status 201 on /team/add, got %d", resp.StatusCode)
+	}
+	_ = resp.Body.Close()
+
+	opened := loadFixture(t, "github_pull_request_opened.json")
+
+	resp = env.postGitHubEvent(t, "pull_request", opened, "wrong-secret")
+	if resp.StatusCode != http.StatusUnauthorized {
+		t.Fatalf("expected status 401 on bad signature, got %d", resp.StatusCode)
+	}
+	_ = resp.Body.Close()
+
+	resp = env.postGitHubEvent(t, "pull_request", opened, testGitHubSecret)
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("expected status 200 on opened event, got %d", resp.StatusCode)
+	}
+	var hookResp struct {
+		Status        string `json:"status"`
+		PullRequestID string `json:"pull_request_id"`
+	}
+	decodeBody(t, resp, &hookResp)
+	if hookResp.Status != "created" || hookResp.PullRequestID != "acme/api#42" {
+		t.Fatalf("unexpected webhook response: %+v", hookResp)
+	}
+
+	resp = env.postGitHubEvent(t, "pull_request", opened, testGitHubSecret)
+	decodeBody(t, resp, &hookResp)
+	if hookResp.Status != "duplicate" {
+		t.Fatalf("expected redelivered opened event to be a duplicate, got %+v", hookResp)
+	}
+
+	resp = env.get(t, "/users/getReview?user_id=u2")
+	var reviewResp userGetReviewResponse
+	decodeBody(t, resp, &reviewResp)
+	if len(reviewResp.PullRequests) != 1 || reviewResp.PullRequests[0].Name != "Add rate limiting to search" {
+		t.Fatalf("expected webhook PR in u2 reviews, got %+v", reviewResp.PullRequests)
+	}
+
+	resp = env.postGitHubEvent(t, "pull_request", loadFixture(t, "github_pull_request_merged.json"), testGitHubSecret)
+	decodeBody(t, resp, &hookResp)
+	if hookResp.Status != "merged" {
+		t.Fatalf("expected merged status, got %+v", hookResp)
+	}
+
+	resp = env.get(t, "/users/getReview?user_id=u2")
+	decodeBody(t, resp, &reviewResp)
+	if reviewResp.PullRequests[0].Status != "MERGED" {
+		t.Fatalf("expected PR to be MERGED, got %s", reviewResp.PullRequests[0].Status)
+	}
+}
+
+func TestGitHubWebhookLifecycleEvents(t *testing.T) {
+	env := newTestEnv(t, func(h *httphandler.Handler) {
+		h.EnableGitHubWebhook([]byte(testGitHubSecret), vcs.IdentityMap{
+			vcs.ProviderGitHub: {"alice-gh": "u1"},
+		})
+	})
+
+	resp := env.postJSON(t, "/team/add", map[string]any{
+		"team_name": "backend",
+		"members": []map[string]any{
+			{"user_id": "u1", "username": "Alice", "is_active": true},
+			{"user_id": "u2", "username": "Bob", "is_active": true},
+		},
+	})
+	if resp.StatusCode != http.StatusCreated {
+		t.Fatalf("expected status 201 on /team/add, got %d", resp.StatusCode)
+	}
+	_ = resp.Body.Close()
+
+	var hookResp struct {
+		Status        string `json:"status"`
+		PullRequestID string `json:"pull_request_id"`
+	}
+	var reviewResp userGetReviewResponse
+
+	steps := []struct {
+		fixture string
+		status  string
+	}{
+		{"github_pull_request_opened.json", "created"},
+		{"github_pull_request_closed.json", "closed"},
+		{"github_pull_request_reopened.json", "reopened"},
+		{"github_pull_request_opened_draft.json", "created"},
+		{"github_pull_request_ready_for_review.json", "ready"},
+	}
+	for _, step := range steps {
+		resp = env.postGitHubEvent(t, "pull_request", loadFixture(t, step.fixture), testGitHubSecret)
+		if resp.StatusCode != http.StatusOK {
+			t.Fatalf("expected status 200 on %s, got %d", step.fixture, resp.StatusCode)
+		}
+		decodeBody(t, resp, &hookResp)
+		if hookResp.Status != step.status {
+			t.Fatalf("expected %s for %s, got %+v", step.status, step.fixture, hookResp)
+		}
+
+		if step.fixture == "github_pull_request_closed.json" {
+			resp = env.get(t, "/users/getReview?user_id=u2")
+			decodeBody(t, resp, &reviewResp)
+			if len(reviewResp.PullRequests) != 1 || reviewResp.PullRequests[0].Status != "CLOSED" {
+				t.Fatalf("expected closed PR in u2 reviews, got %+v", reviewResp.PullRequests)
+			}
+		}
+	}
+
+	resp = env.get(t, "/users/getReview?user_id=u2")
+	decodeBody(t, resp, &reviewResp)
+	if len(reviewResp.PullRequests) != 2 {
+		t.Fatalf("expected both PRs in u2 reviews, got %+v", reviewResp.PullRequests)
+	}
+	for _, pr := range reviewResp.PullRequests {
+		if pr.Status != "OPEN" {
+			t.Fatalf("expected %s to be OPEN, got %s", pr.ID, pr.Status)
+		}
+	}
+}
+
+func TestGitHubWebhookUnknownIdentity(t *testing.T) {
+	env := newTestEnv(t, func(h *httphandler.Handler) {
+		h.EnableGitHubWebhook([]byte(testGitHubSecret), vcs.IdentityMap{})
+	})
+
+	resp := env.postGitHubEvent(t, "pull_request", loadFixture(t, "github_pull_request_opened.json"), testGitHubSecret)
+	if resp.StatusCode != http.StatusUnprocessableEntity {
+		t.Fatalf("expected status 422 for unmapped login, got %d", resp.StatusCode)
+	}
+	var errResp errorResponse
+	decodeBody(t, resp, &errResp)
+	if errResp.Error.Code != "UNKNOWN_IDENTITY" {
+		t.Fatalf("expected UNKNOWN_IDENTITY, got %s", errResp.Error.Code)
+	}
+}
+
+func (e *testEnv) postGitLabEvent(t *testing.T, body []byte, token string) *http.Response {
+	t.Helper()
+	req, err := http.NewRequest(http.MethodPost, e.server.URL+"/hooks/gitlab", bytes.NewReader(body))
+	if err != nil {
+		t.Fatalf("new request: %v", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("X-Gitlab-Event", "Merge Request Hook")
+	req.Header.Set("X-Gitlab-Token", token)
+
+	resp, err := e.client.Do(req)
+	if err != nil {
+		t.Fatalf("do request: %v", err)
+	}
+	return resp
+}
+
+func TestGitLabWebhookOpensAndMergesMR(t *testing.T) {
+	env := newTestEnv(t, func(h *httphandler.Handler) {
+		h.EnableGitLabWebhook([]byte("gl-token"), vcs.IdentityMap{
+			vcs.ProviderGitLab: {"alice.gl": "u1"},
+		})
+	})
+
+	resp := env.postJSON(t, "/team/add", map[string]any{
+		"team_name": "billing",
+		"members": []map[string]any{
+			{"user_id": "u1", "username": "Alice", "is_active": true},
+			{"user_id": "u2", "username": "Bob", "is_active": true},
+		},
+	})
+	if resp.StatusCode != http.StatusCreated {
+		t.Fatalf("expected status 201 on /team/add, got %d", resp.StatusCode)
+	}
+	_ = resp.Body.Close()
+
+	opened := loadFixture(t, "gitlab_merge_request_open.json")
+
+	resp = env.postGitLabEvent(t, opened, "wrong-token")
+	if resp.StatusCode != http.StatusUnauthorized {
+		t.Fatalf("expected status 401 on bad token, got %d", resp.StatusCode)
+	}
+	_ = resp.Body.Close()
+
+	resp = env.postGitLabEvent(t, opened, "gl-token")
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("expected status 200 on open event, got %d", resp.StatusCode)
+	}
+	var hookResp struct {
+		Status        string `json:"status"`
+		PullRequestID string `json:"pull_request_id"`
+	}
+	decodeBody(t, resp, &hookResp)
+	if hookResp.Status != "created" || hookResp.PullRequestID != "acme/billing!17" {
+		t.Fatalf("unexpected webhook response: %+v", hookResp)
+	}
+
+	resp = env.postGitLabEvent(t, loadFixture(t, "gitlab_merge_request_merge.json"), "gl-token")
+	decodeBody(t, resp, &hookResp)
+	if hookResp.Status != "merged" {
+		t.Fatalf("expected merged status, got %+v", hookResp)
+	}
+
+	resp = env.get(t, "/users/getReview?user_id=u2")
+	var reviewResp userGetReviewResponse
+	decodeBody(t, resp, &reviewResp)
+	if len(reviewResp.PullRequests) != 1 || reviewResp.PullRequests[0].Status != "MERGED" {
+		t.Fatalf("expected merged MR in u2 reviews, got %+v", reviewResp.PullRequests)
+	}
+}
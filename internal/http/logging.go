@@ -0,0 +1,120 @@
+package http
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"log/slog"
+	stdhttp "net/http"
+	"time"
+
+	"go.opentelemetry.io/otel/trace"
+)
+
+// RequestIDHeader carries the request ID in both directions. Callers may
+// set it to tie our logs to theirs; otherwise one is generated.
+const RequestIDHeader = "X-Request-ID"
+
+type requestLogKey struct{}
+
+// requestLog collects what handlers want in the access log line.
+type requestLog struct {
+	id      string
+	err     error
+	traceID string
+}
+
+type statusRecorder struct {
+	stdhttp.ResponseWriter
+	status int
+}
+
+func (r *statusRecorder) WriteHeader(status int) {
+	if r.status == 0 {
+		r.status = status
+	}
+	r.ResponseWriter.WriteHeader(status)
+}
+
+func (r *statusRecorder) Write(b []byte) (int, error) {
+	if r.status == 0 {
+		r.status = stdhttp.StatusOK
+	}
+	return r.ResponseWriter.Write(b)
+}
+
+// LogRequests logs one JSON line per request with its method, route, status
+// and latency, plus the error behind any INTERNAL response.
+func LogRequests(logger *slog.Logger, next stdhttp.Handler) stdhttp.Handler {
+	return stdhttp.HandlerFunc(func(w stdhttp.ResponseWriter, r *stdhttp.Request) {
+		start := time.Now()
+
+		entry := &requestLog{id: r.Header.Get(RequestIDHeader)}
+		if !validRequestID(entry.id) {
+			entry.id = newRequestID()
+		}
+		w.Header().Set(RequestIDHeader, entry.id)
+
+		rec := &statusRecorder{ResponseWriter: w}
+		r = r.WithContext(context.WithValue(r.Context(), requestLogKey{}, entry))
+		next.ServeHTTP(rec, r)
+
+		if rec.status == 0 {
+			rec.status = stdhttp.StatusOK
+		}
+		// The mux fills in Pattern; unmatched paths are logged as they came.
+		route := r.Pattern
+		if route == "" {
+			route = r.URL.Path
+		}
+		attrs := []slog.Attr{
+			slog.String("request_id", entry.id),
+			slog.String("method", r.Method),
+			slog.String("route", route),
+			slog.Int("status", rec.status),
+			slog.Float64("latency_ms", float64(time.Since(start).Microseconds())/1000),
+		}
+		level := slog.LevelInfo
+		if entry.err != nil {
+			level = slog.LevelError
+			attrs = append(attrs, slog.String("error", entry.err.Error()))
+		}
+		if entry.traceID != "" {
+			attrs = append(attrs, slog.String("trace_id", entry.traceID))
+		}
+		logger.LogAttrs(r.Context(), level, "request", attrs...)
+	})
+}
+
+// validRequestID keeps caller-supplied IDs short and printable so they
+// cannot forge log lines.
+func validRequestID(id string) bool {
+	if id == "" || len(id) > 128 {
+		return false
+	}
+	for i := 0; i < len(id); i++ {
+		if id[i] < 0x21 || id[i] > 0x7e {
+			return false
+		}
+	}
+	return true
+}
+
+func newRequestID() string {
+	buf := make([]byte, 16)
+	_, _ = rand.Read(buf)
+	return hex.EncodeToString(buf)
+}
+
+// writeInternalError answers 500 and hands err to the request's span and the
+// access log, or to the default logger when requests are not logged.
+func writeInternalError(w stdhttp.ResponseWriter, r *stdhttp.Request, err error) {
+	trace.SpanFromContext(r.Context()).RecordError(err)
+	if entry, ok := r.Context().Value(requestLogKey{}).(*requestLog); ok {
+		entry.err = err
+	} else {
+		slog.ErrorContext(r.Context(), "internal error", slog.String("method", r.Method),
+			slog.String("path", r.URL.Path), slog.String("error", err.Error()))
+	}
+	writeError(w, stdhttp.StatusInternalServerError, "INTERNAL", "internal error")
+}
@@ -0,0 +1,40 @@
+package http
+
+import (
+	stdhttp "net/http"
+	"strconv"
+	"time"
+
+	"pr-reviewer-service/internal/metrics"
+)
+
+// EnableMetrics serves reg on /metrics. Like the health probes it needs no credentials
+// so scrapers can reach it.
+func (h *Handler) EnableMetrics(reg *metrics.Registry) {
+	h.metrics = reg
+}
+
+// InstrumentRequests counts requests and observes their latency by route.
+// Paths no route matched share one label value to keep cardinality bounded.
+func InstrumentRequests(reg *metrics.Registry, next stdhttp.Handler) stdhttp.Handler {
+	requests := reg.NewCounter("pr_reviewer_http_requests_total",
+		"HTTP requests by method, route and status.", "method", "route", "status")
+	latency := reg.NewHistogram("pr_reviewer_http_request_duration_seconds",
+		"HTTP request latency by method and route.", metrics.DefaultBuckets, "method", "route")
+
+	return stdhttp.HandlerFunc(func(w stdhttp.ResponseWriter, r *stdhttp.Request) {
+		start := time.Now()
+		rec := &statusRecorder{ResponseWriter: w}
+		next.ServeHTTP(rec, r)
+
+		if rec.status == 0 {
+			rec.status = stdhttp.StatusOK
+		}
+		route := r.Pattern
+		if route == "" {
+			route = "unmatched"
+		}
+		requests.Inc(r.Method, route, strconv.Itoa(rec.status))
+		latency.Observe(time.Since(start).Seconds(), r.Method, route)
+	})
+}
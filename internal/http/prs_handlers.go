@@ -1,6 +1,7 @@
 package http
 
 import (
+	"context"
 	"encoding/json"
 	"errors"
 	stdhttp "net/http"
@@ -13,10 +14,44 @@ type prCreateRequest struct {
 	PullRequestID   string `json:"pull_request_id"`
 	PullRequestName string `json:"pull_request_name"`
 	AuthorID        string `json:"author_id"`
+	// Files lists changed paths used to prefer CODEOWNERS matches.
+	Files []string `json:"files,omitempty"`
+	// Draft opens the PR without reviewers until it is marked ready.
+	Draft bool `json:"draft,omitempty"`
+}
+
+type prReadyRequest struct {
+	PullRequestID string   `json:"pull_request_id"`
+	Files         []string `json:"files,omitempty"`
+}
+
+type prTransitionRequest struct {
+	PullRequestID string `json:"pull_request_id"`
+}
+
+type prTransitionResponse struct {
+	PR pullRequestDTO `json:"pr"`
 }
 
 type prMergeRequest struct {
 	PullRequestID string `json:"pull_request_id"`
+	// Override merges without the required approvals.
+	Override bool `json:"override"`
+}
+
+type prReviewRequest struct {
+	PullRequestID string `json:"pull_request_id"`
+	ReviewerID    string `json:"reviewer_id"`
+	State         string `json:"state"`
+}
+
+type prReviewResponse struct {
+	PR pullRequestDTO `json:"pr"`
+}
+
+type reviewDTO struct {
+	UserID string `json:"user_id"`
+	State  string `json:"state"`
 }
 
 type prReassignRequest struct {
@@ -24,14 +59,28 @@ type prReassignRequest struct {
 	OldUserID     string `json:"old_user_id"`
 }
 
+type fallbackReviewerDTO struct {
+	UserID   string `json:"user_id"`
+	TeamName string `json:"team_name"`
+}
+
+type matchedRuleDTO struct {
+	UserID string `json:"user_id"`
+	Rule   string `json:"rule"`
+}
+
 type pullRequestDTO struct {
-	PullRequestID     string   `json:"pull_request_id"`
-	PullRequestName   string   `json:"pull_request_name"`
-	AuthorID          string   `json:"author_id"`
-	Status            string   `json:"status"`
-	AssignedReviewers []string `json:"assigned_reviewers"`
-	CreatedAt         string   `json:"createdAt,omitempty"`
-	MergedAt          string   `json:"mergedAt,omitempty"`
+	PullRequestID     string                `json:"pull_request_id"`
+	PullRequestName   string                `json:"pull_request_name"`
+	AuthorID          string                `json:"author_id"`
+	Status            string                `json:"status"`
+	AssignedReviewers []string              `json:"assigned_reviewers"`
+	FallbackReviewers []fallbackReviewerDTO `json:"fallback_reviewers,omitempty"`
+	MatchedRules      []matchedRuleDTO      `json:"matched_rules,omitempty"`
+	Reviews           []reviewDTO           `json:"reviews"`
+	CreatedAt         string                `json:"createdAt,omitempty"`
+	MergedAt          string                `json:"mergedAt,omitempty"`
+	ClosedAt          string                `json:"closedAt,omitempty"`
 }
 
 type prCreateResponse struct {
@@ -67,21 +116,40 @@ func (h *Handler) handlePRCreate(w stdhttp.ResponseWriter, r *stdhttp.Request) {
 		writeError(w, stdhttp.StatusBadRequest, "BAD_REQUEST", "pull_request_id, pull_request_name and author_id are required")
 		return
 	}
+	if !h.authorizeUser(w, r, domain.UserID(req.AuthorID)) {
+		return
+	}
 
-	pr, err := h.prService.Create(
-		r.Context(),
-		domain.PullRequestID(req.PullRequestID),
-		req.PullRequestName,
-		domain.UserID(req.AuthorID),
-	)
+	var pr domain.PullRequest
+	var err error
+	if req.Draft {
+		pr, err = h.prService.CreateDraft(
+			r.Context(),
+			domain.PullRequestID(req.PullRequestID),
+			req.PullRequestName,
+			domain.UserID(req.AuthorID),
+		)
+	} else {
+		pr, err = h.prService.Create(
+			r.Context(),
+			domain.PullRequestID(req.PullRequestID),
+			req.PullRequestName,
+			domain.UserID(req.AuthorID),
+			req.Files,
+		)
+	}
 	if err != nil {
 		switch {
 		case errors.Is(err, domain.ErrPullRequestExists):
 			writeError(w, stdhttp.StatusConflict, "PR_EXISTS", "PR id already exists")
 		case errors.Is(err, domain.ErrNotFound):
 			writeError(w, stdhttp.StatusNotFound, "NOT_FOUND", "resource not found")
+		case errors.Is(err, domain.ErrPoolTooSmall):
+			writeError(w, stdhttp.StatusConflict, "POOL_TOO_SMALL", "not enough active reviewers in team")
+		case errors.Is(err, domain.ErrAllReviewersAtCapacity):
+			writeError(w, stdhttp.StatusUnprocessableEntity, "ALL_AT_CAPACITY", "all candidate reviewers are at capacity")
 		default:
-			writeError(w, stdhttp.StatusInternalServerError, "INTERNAL", "internal error")
+			writeInternalError(w, r, err)
 		}
 		return
 	}
@@ -112,14 +180,24 @@ func (h *Handler) handlePRMerge(w stdhttp.ResponseWriter, r *stdhttp.Request) {
 		writeError(w, stdhttp.StatusBadRequest, "BAD_REQUEST", "pull_request_id is required")
 		return
 	}
+	if !h.authorizePR(w, r, domain.PullRequestID(req.PullRequestID)) {
+		return
+	}
+	if req.Override && !h.authorizeAdmin(w, r) {
+		return
+	}
 
-	pr, err := h.prService.Merge(r.Context(), domain.PullRequestID(req.PullRequestID))
+	pr, err := h.prService.Merge(r.Context(), domain.PullRequestID(req.PullRequestID), req.Override)
 	if err != nil {
 		switch {
 		case errors.Is(err, domain.ErrNotFound):
 			writeError(w, stdhttp.StatusNotFound, "NOT_FOUND", "resource not found")
+		case errors.Is(err, domain.ErrNotEnoughApprovals):
+			writeError(w, stdhttp.StatusConflict, "NOT_ENOUGH_APPROVALS", "pull request lacks required approvals")
+		case errors.Is(err, domain.ErrInvalidTransition):
+			writeError(w, stdhttp.StatusConflict, "INVALID_TRANSITION", "only open PRs can be merged")
 		default:
-			writeError(w, stdhttp.StatusInternalServerError, "INTERNAL", "internal error")
+			writeInternalError(w, r, err)
 		}
 		return
 	}
@@ -150,6 +228,9 @@ func (h *Handler) handlePRReassign(w stdhttp.ResponseWriter, r *stdhttp.Request)
 		writeError(w, stdhttp.StatusBadRequest, "BAD_REQUEST", "pull_request_id and old_user_id are required")
 		return
 	}
+	if !isSelf(r, domain.UserID(req.OldUserID)) && !h.authorizePR(w, r, domain.PullRequestID(req.PullRequestID)) {
+		return
+	}
 
 	pr, newReviewer, err := h.prService.Reassign(
 		r.Context(),
@@ -162,12 +243,18 @@ func (h *Handler) handlePRReassign(w stdhttp.ResponseWriter, r *stdhttp.Request)
 			writeError(w, stdhttp.StatusNotFound, "NOT_FOUND", "resource not found")
 		case errors.Is(err, domain.ErrPullRequestMerged):
 			writeError(w, stdhttp.StatusConflict, "PR_MERGED", "cannot reassign on merged PR")
+		case errors.Is(err, domain.ErrPullRequestClosed):
+			writeError(w, stdhttp.StatusConflict, "PR_CLOSED", "cannot reassign on closed PR")
 		case errors.Is(err, domain.ErrNotAssigned):
 			writeError(w, stdhttp.StatusConflict, "NOT_ASSIGNED", "reviewer is not assigned to this PR")
 		case errors.Is(err, domain.ErrNoCandidate):
 			writeError(w, stdhttp.StatusConflict, "NO_CANDIDATE", "no active replacement candidate in team")
+		case errors.Is(err, domain.ErrPoolTooSmall):
+			writeError(w, stdhttp.StatusConflict, "POOL_TOO_SMALL", "not enough active reviewers in team")
+		case errors.Is(err, domain.ErrAllReviewersAtCapacity):
+			writeError(w, stdhttp.StatusUnprocessableEntity, "ALL_AT_CAPACITY", "all candidate reviewers are at capacity")
 		default:
-			writeError(w, stdhttp.StatusInternalServerError, "INTERNAL", "internal error")
+			writeInternalError(w, r, err)
 		}
 		return
 	}
@@ -179,6 +266,160 @@ func (h *Handler) handlePRReassign(w stdhttp.ResponseWriter, r *stdhttp.Request)
 	writeJSON(w, stdhttp.StatusOK, resp)
 }
 
+func (h *Handler) handlePRReview(w stdhttp.ResponseWriter, r *stdhttp.Request) {
+	if r.Method != stdhttp.MethodPost {
+		w.Header().Set("Allow", stdhttp.MethodPost)
+		writeError(w, stdhttp.StatusMethodNotAllowed, "METHOD_NOT_ALLOWED", "method not allowed")
+		return
+	}
+
+	defer func() {
+		_ = r.Body.Close()
+	}()
+	var req prReviewRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, stdhttp.StatusBadRequest, "BAD_REQUEST", "invalid json body")
+		return
+	}
+
+	if req.PullRequestID == "" || req.ReviewerID == "" {
+		writeError(w, stdhttp.StatusBadRequest, "BAD_REQUEST", "pull_request_id and reviewer_id are required")
+		return
+	}
+	if !isSelf(r, domain.UserID(req.ReviewerID)) && !h.authorizePR(w, r, domain.PullRequestID(req.PullRequestID)) {
+		return
+	}
+
+	pr, err := h.prService.Review(
+		r.Context(),
+		domain.PullRequestID(req.PullRequestID),
+		domain.UserID(req.ReviewerID),
+		domain.ReviewState(req.State),
+	)
+	if err != nil {
+		switch {
+		case errors.Is(err, domain.ErrInvalidReview):
+			writeError(w, stdhttp.StatusBadRequest, "BAD_REQUEST", "state must be APPROVED, CHANGES_REQUESTED or COMMENTED")
+		case errors.Is(err, domain.ErrNotFound):
+			writeError(w, stdhttp.StatusNotFound, "NOT_FOUND", "resource not found")
+		case errors.Is(err, domain.ErrPullRequestMerged):
+			writeError(w, stdhttp.StatusConflict, "PR_MERGED", "cannot review merged PR")
+		case errors.Is(err, domain.ErrPullRequestClosed):
+			writeError(w, stdhttp.StatusConflict, "PR_CLOSED", "cannot review closed PR")
+		case errors.Is(err, domain.ErrNotAssigned):
+			writeError(w, stdhttp.StatusConflict, "NOT_ASSIGNED", "reviewer is not assigned to this PR")
+		default:
+			writeInternalError(w, r, err)
+		}
+		return
+	}
+
+	writeJSON(w, stdhttp.StatusOK, prReviewResponse{
+		PR: prToDTO(pr),
+	})
+}
+
+func (h *Handler) handlePRReady(w stdhttp.ResponseWriter, r *stdhttp.Request) {
+	if r.Method != stdhttp.MethodPost {
+		w.Header().Set("Allow", stdhttp.MethodPost)
+		writeError(w, stdhttp.StatusMethodNotAllowed, "METHOD_NOT_ALLOWED", "method not allowed")
+		return
+	}
+
+	defer func() {
+		_ = r.Body.Close()
+	}()
+	var req prReadyRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, stdhttp.StatusBadRequest, "BAD_REQUEST", "invalid json body")
+		return
+	}
+
+	if req.PullRequestID == "" {
+		writeError(w, stdhttp.StatusBadRequest, "BAD_REQUEST", "pull_request_id is required")
+		return
+	}
+	if !h.authorizePR(w, r, domain.PullRequestID(req.PullRequestID)) {
+		return
+	}
+
+	pr, err := h.prService.Ready(r.Context(), domain.PullRequestID(req.PullRequestID), req.Files)
+	if err != nil {
+		switch {
+		case errors.Is(err, domain.ErrNotFound):
+			writeError(w, stdhttp.StatusNotFound, "NOT_FOUND", "resource not found")
+		case errors.Is(err, domain.ErrInvalidTransition):
+			writeError(w, stdhttp.StatusConflict, "INVALID_TRANSITION", "only draft PRs can be marked ready")
+		case errors.Is(err, domain.ErrPoolTooSmall):
+			writeError(w, stdhttp.StatusConflict, "POOL_TOO_SMALL", "not enough active reviewers in team")
+		case errors.Is(err, domain.ErrAllReviewersAtCapacity):
+			writeError(w, stdhttp.StatusUnprocessableEntity, "ALL_AT_CAPACITY", "all candidate reviewers are at capacity")
+		default:
+			writeInternalError(w, r, err)
+		}
+		return
+	}
+
+	writeJSON(w, stdhttp.StatusOK, prTransitionResponse{
+		PR: prToDTO(pr),
+	})
+}
+
+func (h *Handler) handlePRClose(w stdhttp.ResponseWriter, r *stdhttp.Request) {
+	h.handlePRTransition(w, r, h.prService.Close, "merged PRs cannot be closed")
+}
+
+func (h *Handler) handlePRReopen(w stdhttp.ResponseWriter, r *stdhttp.Request) {
+	h.handlePRTransition(w, r, h.prService.Reopen, "merged PRs cannot be reopened")
+}
+
+func (h *Handler) handlePRTransition(
+	w stdhttp.ResponseWriter,
+	r *stdhttp.Request,
+	apply func(ctx context.Context, id domain.PullRequestID) (domain.PullRequest, error),
+	invalidMsg string,
+) {
+	if r.Method != stdhttp.MethodPost {
+		w.Header().Set("Allow", stdhttp.MethodPost)
+		writeError(w, stdhttp.StatusMethodNotAllowed, "METHOD_NOT_ALLOWED", "method not allowed")
+		return
+	}
+
+	defer func() {
+		_ = r.Body.Close()
+	}()
+	var req prTransitionRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, stdhttp.StatusBadRequest, "BAD_REQUEST", "invalid json body")
+		return
+	}
+
+	if req.PullRequestID == "" {
+		writeError(w, stdhttp.StatusBadRequest, "BAD_REQUEST", "pull_request_id is required")
+		return
+	}
+	if !h.authorizePR(w, r, domain.PullRequestID(req.PullRequestID)) {
+		return
+	}
+
+	pr, err := apply(r.Context(), domain.PullRequestID(req.PullRequestID))
+	if err != nil {
+		switch {
+		case errors.Is(err, domain.ErrNotFound):
+			writeError(w, stdhttp.StatusNotFound, "NOT_FOUND", "resource not found")
+		case errors.Is(err, domain.ErrInvalidTransition):
+			writeError(w, stdhttp.StatusConflict, "INVALID_TRANSITION", invalidMsg)
+		default:
+			writeInternalError(w, r, err)
+		}
+		return
+	}
+
+	writeJSON(w, stdhttp.StatusOK, prTransitionResponse{
+		PR: prToDTO(pr),
+	})
+}
+
 func prToDTO(pr domain.PullRequest) pullRequestDTO {
 	dto := pullRequestDTO{
 		PullRequestID:     string(pr.ID),
@@ -186,10 +427,27 @@ func prToDTO(pr domain.PullRequest) pullRequestDTO {
 		AuthorID:          string(pr.AuthorID),
 		Status:            string(pr.Status),
 		AssignedReviewers: make([]string, 0, len(pr.AssignedReviewers)),
+		Reviews:           make([]reviewDTO, 0, len(pr.AssignedReviewers)),
 	}
 
 	for _, r := range pr.AssignedReviewers {
 		dto.AssignedReviewers = append(dto.AssignedReviewers, string(r))
+		dto.Reviews = append(dto.Reviews, reviewDTO{
+			UserID: string(r),
+			State:  string(pr.ReviewState(r)),
+		})
+		if team, ok := pr.FallbackReviewers[r]; ok {
+			dto.FallbackReviewers = append(dto.FallbackReviewers, fallbackReviewerDTO{
+				UserID:   string(r),
+				TeamName: string(team),
+			})
+		}
+		if rule, ok := pr.MatchedRules[r]; ok {
+			dto.MatchedRules = append(dto.MatchedRules, matchedRuleDTO{
+				UserID: string(r),
+				Rule:   rule,
+			})
+		}
 	}
 
 	if !pr.CreatedAt.IsZero() {
@@ -198,6 +456,67 @@ func prToDTO(pr domain.PullRequest) pullRequestDTO {
 	if pr.MergedAt != nil {
 		dto.MergedAt = pr.MergedAt.UTC().Format(time.RFC3339)
 	}
+	if pr.ClosedAt != nil {
+		dto.ClosedAt = pr.ClosedAt.UTC().Format(time.RFC3339)
+	}
 
 	return dto
 }
+
+type assignmentEventDTO struct {
+	EventID    int64  `json:"event_id"`
+	Type       string `json:"type"`
+	UserID     string `json:"user_id"`
+	ReplacedBy string `json:"replaced_by,omitempty"`
+	ActorID    string `json:"actor_id,omitempty"`
+	Reason     string `json:"reason"`
+	CreatedAt  string `json:"createdAt"`
+}
+
+type prHistoryResponse struct {
+	PullRequestID string               `json:"pull_request_id"`
+	Events        []assignmentEventDTO `json:"events"`
+}
+
+func (h *Handler) handlePRHistory(w stdhttp.ResponseWriter, r *stdhttp.Request) {
+	if r.Method != stdhttp.MethodGet {
+		w.Header().Set("Allow", stdhttp.MethodGet)
+		writeError(w, stdhttp.StatusMethodNotAllowed, "METHOD_NOT_ALLOWED", "method not allowed")
+		return
+	}
+
+	prID := r.URL.Query().Get("pull_request_id")
+	if prID == "" {
+		writeError(w, stdhttp.StatusBadRequest, "BAD_REQUEST", "pull_request_id is required")
+		return
+	}
+
+	events, err := h.prService.History(r.Context(), domain.PullRequestID(prID))
+	if err != nil {
+		switch {
+		case errors.Is(err, domain.ErrNotFound):
+			writeError(w, stdhttp.StatusNotFound, "NOT_FOUND", "resource not found")
+		default:
+			writeInternalError(w, r, err)
+		}
+		return
+	}
+
+	resp := prHistoryResponse{
+		PullRequestID: prID,
+		Events:        make([]assignmentEventDTO, 0, len(events)),
+	}
+	for _, e := range events {
+		resp.Events = append(resp.Events, assignmentEventDTO{
+			EventID:    e.ID,
+			Type:       string(e.Type),
+			UserID:     string(e.UserID),
+			ReplacedBy: string(e.ReplacedBy),
+			ActorID:    string(e.Actor),
+			Reason:     string(e.Reason),
+			CreatedAt:  e.CreatedAt.UTC().Format(time.RFC3339),
+		})
+	}
+
+	writeJSON(w, stdhttp.StatusOK, resp)
+}
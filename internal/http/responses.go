@@ -9,6 +9,8 @@ type errorBody struct {
 	Error struct {
 		Code    string `json:"code"`
 		Message string `json:"message"`
+		// RequestID lets a caller quote the failing request back to us.
+		RequestID string `json:"request_id,omitempty"`
 	} `json:"error"`
 }
 
@@ -25,5 +27,6 @@ func writeError(w stdhttp.ResponseWriter, status int, code, message string) {
 	var body errorBody
 	body.Error.Code = code
 	body.Error.Message = message
+	body.Error.RequestID = w.Header().Get(RequestIDHeader)
 	writeJSON(w, status, body)
 }
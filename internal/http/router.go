@@ -2,6 +2,10 @@ package http
 
 import (
 	stdhttp "net/http"
+	"sync/atomic"
+
+	"pr-reviewer-service/internal/metrics"
+	"pr-reviewer-service/internal/oidc"
 	"pr-reviewer-service/internal/service"
 )
 
@@ -9,6 +13,16 @@ type Handler struct {
 	teamService *service.TeamService
 	userService *service.UserService
 	prService   *service.PRService
+	github      *webhookConfig
+	gitlab      *webhookConfig
+
+	webhookService *service.WebhookService
+	apiKeys        *service.APIKeyService
+	sso            *oidc.Verifier
+	metrics        *metrics.Registry
+
+	readiness []ReadinessCheck
+	draining  atomic.Bool
 }
 
 func NewHandler(teamSvc *service.TeamService, userSvc *service.UserService, prSvc *service.PRService) *Handler {
@@ -20,18 +34,52 @@ func NewHandler(teamSvc *service.TeamService, userSvc *service.UserService, prSv
 }
 
 func (h *Handler) RegisterRoutes(mux *stdhttp.ServeMux) {
-	mux.HandleFunc("/health", h.handleHealth)
+	// /health predates the split and stays an alias of /livez.
+	mux.HandleFunc("/health", h.handleLive)
+	mux.HandleFunc("/livez", h.handleLive)
+	mux.HandleFunc("/readyz", h.handleReady)
+	if h.metrics != nil {
+		mux.Handle("/metrics", h.metrics.Handler())
+	}
+
+	mux.HandleFunc("/team/add", h.guard(accessAdmin, accessAdmin, h.handleTeamAdd))
+	mux.HandleFunc("/team/get", h.guard(accessRead, accessRead, h.handleTeamGet))
+	mux.HandleFunc("/team/settings", h.guard(accessRead, accessWrite, h.handleTeamSettings))
+	mux.HandleFunc("/team/codeowners", h.guard(accessRead, accessWrite, h.handleTeamCodeowners))
 
-	mux.HandleFunc("/team/add", h.handleTeamAdd)
-	mux.HandleFunc("/team/get", h.handleTeamGet)
+	// Self-service routes admit read-only callers; handlers let them act
+	// only on themselves.
+	mux.HandleFunc("/users/setIsActive", h.guard(accessRead, accessRead, h.handleUserSetIsActive))
+	mux.HandleFunc("/users/setMaxOpenReviews", h.guard(accessWrite, accessWrite, h.handleUserSetMaxOpenReviews))
+	mux.HandleFunc("/users/getReview", h.guard(accessRead, accessRead, h.handleUserGetReview))
+	mux.HandleFunc("/users/unavailability", h.guard(accessRead, accessRead, h.handleUserUnavailability))
+	mux.HandleFunc("/users/unavailability/delete", h.guard(accessRead, accessRead, h.handleUserUnavailabilityDelete))
 
-	mux.HandleFunc("/users/setIsActive", h.handleUserSetIsActive)
-	mux.HandleFunc("/users/getReview", h.handleUserGetReview)
+	mux.HandleFunc("/pullRequest/create", h.guard(accessWrite, accessWrite, h.handlePRCreate))
+	mux.HandleFunc("/pullRequest/merge", h.guard(accessWrite, accessWrite, h.handlePRMerge))
+	mux.HandleFunc("/pullRequest/ready", h.guard(accessWrite, accessWrite, h.handlePRReady))
+	mux.HandleFunc("/pullRequest/close", h.guard(accessWrite, accessWrite, h.handlePRClose))
+	mux.HandleFunc("/pullRequest/reopen", h.guard(accessWrite, accessWrite, h.handlePRReopen))
+	mux.HandleFunc("/pullRequest/reassign", h.guard(accessRead, accessRead, h.handlePRReassign))
+	mux.HandleFunc("/pullRequest/review", h.guard(accessRead, accessRead, h.handlePRReview))
+	mux.HandleFunc("/pullRequest/history", h.guard(accessRead, accessRead, h.handlePRHistory))
 
-	mux.HandleFunc("/pullRequest/create", h.handlePRCreate)
-	mux.HandleFunc("/pullRequest/merge", h.handlePRMerge)
-	mux.HandleFunc("/pullRequest/reassign", h.handlePRReassign)
+	mux.HandleFunc("/stats/assignments", h.guard(accessRead, accessRead, h.handleStatsAssignments))
+	mux.HandleFunc("/team/deactivateMembers", h.guard(accessWrite, accessWrite, h.handleTeamBulkDeactivate))
 
-	mux.HandleFunc("/stats/assignments", h.handleStatsAssignments)
-	mux.HandleFunc("/team/deactivateMembers", h.handleTeamBulkDeactivate)
+	// VCS hooks authenticate with their own signatures.
+	if h.github != nil {
+		mux.HandleFunc("/hooks/github", h.handleGitHubWebhook)
+	}
+	if h.gitlab != nil {
+		mux.HandleFunc("/hooks/gitlab", h.handleGitLabWebhook)
+	}
+
+	if h.webhookService != nil {
+		mux.HandleFunc("/webhooks/add", h.guard(accessAdmin, accessAdmin, h.handleWebhookSubscribe))
+		mux.HandleFunc("/webhooks/list", h.guard(accessAdmin, accessAdmin, h.handleWebhookList))
+		mux.HandleFunc("/webhooks/delete", h.guard(accessAdmin, accessAdmin, h.handleWebhookDelete))
+		mux.HandleFunc("/webhooks/deadLetters", h.guard(accessAdmin, accessAdmin, h.handleWebhookDeadLetters))
+		mux.HandleFunc("/webhooks/redrive", h.guard(accessAdmin, accessAdmin, h.handleWebhookRedrive))
+	}
 }
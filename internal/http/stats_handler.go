@@ -22,7 +22,7 @@ func (h *Handler) handleStatsAssignments(w stdhttp.ResponseWriter, r *stdhttp.Re
 
 	stats, err := h.prService.StatsAssignmentsByUser(r.Context())
 	if err != nil {
-		writeError(w, stdhttp.StatusInternalServerError, "INTERNAL", "internal error")
+		writeInternalError(w, r, err)
 		return
 	}
 
@@ -4,14 +4,18 @@ import (
 	"encoding/json"
 	"errors"
 	stdhttp "net/http"
+	"time"
 
+	"pr-reviewer-service/internal/codeowners"
 	"pr-reviewer-service/internal/domain"
 )
 
 type teamMemberDTO struct {
-	UserID   string `json:"user_id"`
-	Username string `json:"username"`
-	IsActive bool   `json:"is_active"`
+	UserID         string `json:"user_id"`
+	Username       string `json:"username"`
+	IsActive       bool   `json:"is_active"`
+	SlackHandle    string `json:"slack_handle,omitempty"`
+	MaxOpenReviews int    `json:"max_open_reviews"`
 }
 
 type teamDTO struct {
@@ -50,9 +54,11 @@ func (h *Handler) handleTeamAdd(w stdhttp.ResponseWriter, r *stdhttp.Request) {
 			continue
 		}
 		members = append(members, domain.User{
-			ID:       domain.UserID(m.UserID),
-			Username: m.Username,
-			IsActive: m.IsActive,
+			ID:             domain.UserID(m.UserID),
+			Username:       m.Username,
+			IsActive:       m.IsActive,
+			SlackHandle:    m.SlackHandle,
+			MaxOpenReviews: m.MaxOpenReviews,
 		})
 	}
 
@@ -61,8 +67,10 @@ func (h *Handler) handleTeamAdd(w stdhttp.ResponseWriter, r *stdhttp.Request) {
 		switch {
 		case errors.Is(err, domain.ErrTeamExists):
 			writeError(w, stdhttp.StatusBadRequest, "TEAM_EXISTS", "team_name already exists")
+		case errors.Is(err, domain.ErrInvalidCapacity):
+			writeError(w, stdhttp.StatusBadRequest, "BAD_REQUEST", "max_open_reviews must not be negative")
 		default:
-			writeError(w, stdhttp.StatusInternalServerError, "INTERNAL", "internal error")
+			writeInternalError(w, r, err)
 		}
 		return
 	}
@@ -93,7 +101,7 @@ func (h *Handler) handleTeamGet(w stdhttp.ResponseWriter, r *stdhttp.Request) {
 		case errors.Is(err, domain.ErrNotFound):
 			writeError(w, stdhttp.StatusNotFound, "NOT_FOUND", "resource not found")
 		default:
-			writeError(w, stdhttp.StatusInternalServerError, "INTERNAL", "internal error")
+			writeInternalError(w, r, err)
 		}
 		return
 	}
@@ -106,9 +114,11 @@ func teamToDTO(t domain.Team) teamDTO {
 	members := make([]teamMemberDTO, 0, len(t.Members))
 	for _, m := range t.Members {
 		members = append(members, teamMemberDTO{
-			UserID:   string(m.ID),
-			Username: m.Username,
-			IsActive: m.IsActive,
+			UserID:         string(m.ID),
+			Username:       m.Username,
+			IsActive:       m.IsActive,
+			SlackHandle:    m.SlackHandle,
+			MaxOpenReviews: m.MaxOpenReviews,
 		})
 	}
 	return teamDTO{
@@ -147,14 +157,20 @@ func (h *Handler) handleTeamBulkDeactivate(w stdhttp.ResponseWriter, r *stdhttp.
 		writeError(w, stdhttp.StatusBadRequest, "BAD_REQUEST", "team_name and user_ids are required")
 		return
 	}
+	if !h.authorizeTeam(w, r, domain.TeamName(req.TeamName)) {
+		return
+	}
 
 	ids := make([]domain.UserID, 0, len(req.UserIDs))
 	for _, id := range req.UserIDs {
+		if !h.authorizeUser(w, r, domain.UserID(id)) {
+			return
+		}
 		ids = append(ids, domain.UserID(id))
 	}
 
 	if err := h.prService.BulkDeactivateAndReassign(r.Context(), ids); err != nil {
-		writeError(w, stdhttp.StatusInternalServerError, "INTERNAL", "internal error")
+		writeInternalError(w, r, err)
 		return
 	}
 
@@ -162,3 +178,214 @@ func (h *Handler) handleTeamBulkDeactivate(w stdhttp.ResponseWriter, r *stdhttp.
 
 	writeJSON(w, stdhttp.StatusOK, resp)
 }
+
+type teamSettingsDTO struct {
+	TeamName       string   `json:"team_name"`
+	ReviewersCount int      `json:"reviewers_count"`
+	Strategy       string   `json:"strategy"`
+	MinPoolSize    int      `json:"min_pool_size"`
+	FallbackTeams  []string `json:"fallback_teams"`
+
+	SlackWebhookURL   string `json:"slack_webhook_url"`
+	RequiredApprovals int    `json:"required_approvals"`
+}
+
+func (h *Handler) handleTeamSettings(w stdhttp.ResponseWriter, r *stdhttp.Request) {
+	switch r.Method {
+	case stdhttp.MethodGet:
+		h.handleTeamSettingsGet(w, r)
+	case stdhttp.MethodPost:
+		h.handleTeamSettingsUpdate(w, r)
+	default:
+		w.Header().Set("Allow", stdhttp.MethodGet+", "+stdhttp.MethodPost)
+		writeError(w, stdhttp.StatusMethodNotAllowed, "METHOD_NOT_ALLOWED", "method not allowed")
+	}
+}
+
+func (h *Handler) handleTeamSettingsGet(w stdhttp.ResponseWriter, r *stdhttp.Request) {
+	teamName := r.URL.Query().Get("team_name")
+	if teamName == "" {
+		writeError(w, stdhttp.StatusBadRequest, "BAD_REQUEST", "team_name is required")
+		return
+	}
+
+	settings, err := h.teamService.GetSettings(r.Context(), domain.TeamName(teamName))
+	if err != nil {
+		switch {
+		case errors.Is(err, domain.ErrNotFound):
+			writeError(w, stdhttp.StatusNotFound, "NOT_FOUND", "resource not found")
+		default:
+			writeInternalError(w, r, err)
+		}
+		return
+	}
+
+	writeJSON(w, stdhttp.StatusOK, teamSettingsToDTO(settings))
+}
+
+func (h *Handler) handleTeamSettingsUpdate(w stdhttp.ResponseWriter, r *stdhttp.Request) {
+	defer func() {
+		_ = r.Body.Close()
+	}()
+	var req teamSettingsDTO
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, stdhttp.StatusBadRequest, "BAD_REQUEST", "invalid json body")
+		return
+	}
+
+	if req.TeamName == "" {
+		writeError(w, stdhttp.StatusBadRequest, "BAD_REQUEST", "team_name is required")
+		return
+	}
+	if !h.authorizeTeam(w, r, domain.TeamName(req.TeamName)) {
+		return
+	}
+
+	fallbacks := make([]domain.TeamName, 0, len(req.FallbackTeams))
+	for _, name := range req.FallbackTeams {
+		fallbacks = append(fallbacks, domain.TeamName(name))
+	}
+
+	settings, err := h.teamService.UpdateSettings(r.Context(), domain.TeamSettings{
+		TeamName:       domain.TeamName(req.TeamName),
+		ReviewersCount: req.ReviewersCount,
+		Strategy:       domain.ReviewerStrategy(req.Strategy),
+		MinPoolSize:    req.MinPoolSize,
+		FallbackTeams:  fallbacks,
+
+		SlackWebhookURL:   req.SlackWebhookURL,
+		RequiredApprovals: req.RequiredApprovals,
+	})
+	if err != nil {
+		switch {
+		case errors.Is(err, domain.ErrInvalidSettings):
+			writeError(w, stdhttp.StatusBadRequest, "BAD_REQUEST", "invalid team settings")
+		case errors.Is(err, domain.ErrNotFound):
+			writeError(w, stdhttp.StatusNotFound, "NOT_FOUND", "resource not found")
+		default:
+			writeInternalError(w, r, err)
+		}
+		return
+	}
+
+	writeJSON(w, stdhttp.StatusOK, teamSettingsToDTO(settings))
+}
+
+func teamSettingsToDTO(s domain.TeamSettings) teamSettingsDTO {
+	dto := teamSettingsDTO{
+		TeamName:       string(s.TeamName),
+		ReviewersCount: s.ReviewersCount,
+		Strategy:       string(s.Strategy),
+		MinPoolSize:    s.MinPoolSize,
+		FallbackTeams:  make([]string, 0, len(s.FallbackTeams)),
+
+		SlackWebhookURL:   s.SlackWebhookURL,
+		RequiredApprovals: s.RequiredApprovals,
+	}
+	for _, name := range s.FallbackTeams {
+		dto.FallbackTeams = append(dto.FallbackTeams, string(name))
+	}
+	return dto
+}
+
+type codeownersUploadRequest struct {
+	TeamName   string `json:"team_name"`
+	Codeowners string `json:"codeowners"`
+}
+
+type codeownersRuleDTO struct {
+	Line    int      `json:"line"`
+	Pattern string   `json:"pattern"`
+	Owners  []string `json:"owners"`
+}
+
+type codeownersDTO struct {
+	TeamName   string              `json:"team_name"`
+	Codeowners string              `json:"codeowners"`
+	Rules      []codeownersRuleDTO `json:"rules"`
+	UpdatedAt  string              `json:"updatedAt"`
+}
+
+func (h *Handler) handleTeamCodeowners(w stdhttp.ResponseWriter, r *stdhttp.Request) {
+	switch r.Method {
+	case stdhttp.MethodGet:
+		h.handleTeamCodeownersGet(w, r)
+	case stdhttp.MethodPost:
+		h.handleTeamCodeownersUpload(w, r)
+	default:
+		w.Header().Set("Allow", stdhttp.MethodGet+", "+stdhttp.MethodPost)
+		writeError(w, stdhttp.StatusMethodNotAllowed, "METHOD_NOT_ALLOWED", "method not allowed")
+	}
+}
+
+func (h *Handler) handleTeamCodeownersGet(w stdhttp.ResponseWriter, r *stdhttp.Request) {
+	teamName := r.URL.Query().Get("team_name")
+	if teamName == "" {
+		writeError(w, stdhttp.StatusBadRequest, "BAD_REQUEST", "team_name is required")
+		return
+	}
+
+	c, rules, err := h.teamService.GetCodeowners(r.Context(), domain.TeamName(teamName))
+	if err != nil {
+		switch {
+		case errors.Is(err, domain.ErrNotFound):
+			writeError(w, stdhttp.StatusNotFound, "NOT_FOUND", "resource not found")
+		default:
+			writeInternalError(w, r, err)
+		}
+		return
+	}
+
+	writeJSON(w, stdhttp.StatusOK, codeownersToDTO(c, rules))
+}
+
+func (h *Handler) handleTeamCodeownersUpload(w stdhttp.ResponseWriter, r *stdhttp.Request) {
+	defer func() {
+		_ = r.Body.Close()
+	}()
+	var req codeownersUploadRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, stdhttp.StatusBadRequest, "BAD_REQUEST", "invalid json body")
+		return
+	}
+
+	if req.TeamName == "" {
+		writeError(w, stdhttp.StatusBadRequest, "BAD_REQUEST", "team_name is required")
+		return
+	}
+	if !h.authorizeTeam(w, r, domain.TeamName(req.TeamName)) {
+		return
+	}
+
+	c, rules, err := h.teamService.UploadCodeowners(r.Context(), domain.TeamName(req.TeamName), req.Codeowners)
+	if err != nil {
+		switch {
+		case errors.Is(err, domain.ErrInvalidCodeowners):
+			writeError(w, stdhttp.StatusBadRequest, "BAD_REQUEST", err.Error())
+		case errors.Is(err, domain.ErrNotFound):
+			writeError(w, stdhttp.StatusNotFound, "NOT_FOUND", "resource not found")
+		default:
+			writeInternalError(w, r, err)
+		}
+		return
+	}
+
+	writeJSON(w, stdhttp.StatusOK, codeownersToDTO(c, rules))
+}
+
+func codeownersToDTO(c domain.Codeowners, rules codeowners.Ruleset) codeownersDTO {
+	dto := codeownersDTO{
+		TeamName:   string(c.TeamName),
+		Codeowners: c.Content,
+		Rules:      make([]codeownersRuleDTO, 0, len(rules.Rules)),
+		UpdatedAt:  c.UpdatedAt.UTC().Format(time.RFC3339),
+	}
+	for _, rule := range rules.Rules {
+		dto.Rules = append(dto.Rules, codeownersRuleDTO{
+			Line:    rule.Line,
+			Pattern: rule.Pattern,
+			Owners:  append([]string{}, rule.Owners...),
+		})
+	}
+	return dto
+}
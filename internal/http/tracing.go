@@ -0,0 +1,52 @@
+package http
+
+import (
+	stdhttp "net/http"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/propagation"
+	semconv "go.opentelemetry.io/otel/semconv/v1.26.0"
+	"go.opentelemetry.io/otel/trace"
+)
+
+var tracer = otel.Tracer("pr-reviewer-service/internal/http")
+
+// TraceRequests starts a server span per request, continuing the trace named
+// by incoming W3C traceparent headers. It sits right around the mux so the
+// span can be named after the matched route.
+func TraceRequests(next stdhttp.Handler) stdhttp.Handler {
+	return stdhttp.HandlerFunc(func(w stdhttp.ResponseWriter, r *stdhttp.Request) {
+		ctx := otel.GetTextMapPropagator().Extract(r.Context(), propagation.HeaderCarrier(r.Header))
+		ctx, span := tracer.Start(ctx, r.Method,
+			trace.WithSpanKind(trace.SpanKindServer),
+			trace.WithAttributes(
+				semconv.HTTPRequestMethodKey.String(r.Method),
+				semconv.URLPath(r.URL.Path),
+			),
+		)
+		defer span.End()
+		if entry, ok := ctx.Value(requestLogKey{}).(*requestLog); ok && span.SpanContext().HasTraceID() {
+			entry.traceID = span.SpanContext().TraceID().String()
+		}
+
+		rec := &statusRecorder{ResponseWriter: w}
+		traced := r.WithContext(ctx)
+		next.ServeHTTP(rec, traced)
+
+		// Hand the route the mux matched back to outer middleware, which
+		// reads it from their own copy of the request.
+		r.Pattern = traced.Pattern
+		if rec.status == 0 {
+			rec.status = stdhttp.StatusOK
+		}
+		if r.Pattern != "" {
+			span.SetName(r.Method + " " + r.Pattern)
+			span.SetAttributes(semconv.HTTPRoute(r.Pattern))
+		}
+		span.SetAttributes(semconv.HTTPResponseStatusCode(rec.status))
+		if rec.status >= stdhttp.StatusInternalServerError {
+			span.SetStatus(codes.Error, stdhttp.StatusText(rec.status))
+		}
+	})
+}
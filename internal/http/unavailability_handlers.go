@@ -0,0 +1,177 @@
+package http
+
+import (
+	"encoding/json"
+	"errors"
+	stdhttp "net/http"
+	"time"
+
+	"pr-reviewer-service/internal/domain"
+)
+
+type addUnavailabilityRequest struct {
+	UserID              string    `json:"user_id"`
+	StartsAt            time.Time `json:"starts_at"`
+	EndsAt              time.Time `json:"ends_at"`
+	Reason              string    `json:"reason"`
+	ReassignOpenReviews bool      `json:"reassign_open_reviews"`
+}
+
+type unavailabilityDTO struct {
+	UnavailabilityID    int64   `json:"unavailability_id"`
+	UserID              string  `json:"user_id"`
+	StartsAt            string  `json:"starts_at"`
+	EndsAt              string  `json:"ends_at"`
+	Reason              string  `json:"reason"`
+	ReassignOpenReviews bool    `json:"reassign_open_reviews"`
+	ReassignedAt        *string `json:"reassigned_at,omitempty"`
+}
+
+type addUnavailabilityResponse struct {
+	Unavailability unavailabilityDTO `json:"unavailability"`
+}
+
+type listUnavailabilityResponse struct {
+	UserID         string              `json:"user_id"`
+	Unavailability []unavailabilityDTO `json:"unavailability"`
+}
+
+type deleteUnavailabilityRequest struct {
+	UnavailabilityID int64 `json:"unavailability_id"`
+}
+
+func (h *Handler) handleUserUnavailability(w stdhttp.ResponseWriter, r *stdhttp.Request) {
+	switch r.Method {
+	case stdhttp.MethodGet:
+		h.handleUserUnavailabilityList(w, r)
+	case stdhttp.MethodPost:
+		h.handleUserUnavailabilityAdd(w, r)
+	default:
+		w.Header().Set("Allow", stdhttp.MethodGet+", "+stdhttp.MethodPost)
+		writeError(w, stdhttp.StatusMethodNotAllowed, "METHOD_NOT_ALLOWED", "method not allowed")
+	}
+}
+
+func (h *Handler) handleUserUnavailabilityList(w stdhttp.ResponseWriter, r *stdhttp.Request) {
+	userID := r.URL.Query().Get("user_id")
+	if userID == "" {
+		writeError(w, stdhttp.StatusBadRequest, "BAD_REQUEST", "user_id is required")
+		return
+	}
+
+	periods, err := h.userService.ListUnavailability(r.Context(), domain.UserID(userID))
+	if err != nil {
+		switch {
+		case errors.Is(err, domain.ErrNotFound):
+			writeError(w, stdhttp.StatusNotFound, "NOT_FOUND", "resource not found")
+		default:
+			writeInternalError(w, r, err)
+		}
+		return
+	}
+
+	resp := listUnavailabilityResponse{
+		UserID:         userID,
+		Unavailability: make([]unavailabilityDTO, 0, len(periods)),
+	}
+	for _, p := range periods {
+		resp.Unavailability = append(resp.Unavailability, unavailabilityToDTO(p))
+	}
+
+	writeJSON(w, stdhttp.StatusOK, resp)
+}
+
+func (h *Handler) handleUserUnavailabilityAdd(w stdhttp.ResponseWriter, r *stdhttp.Request) {
+	defer func() {
+		_ = r.Body.Close()
+	}()
+	var req addUnavailabilityRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, stdhttp.StatusBadRequest, "BAD_REQUEST", "invalid json body")
+		return
+	}
+
+	if req.UserID == "" {
+		writeError(w, stdhttp.StatusBadRequest, "BAD_REQUEST", "user_id is required")
+		return
+	}
+	if !isSelf(r, domain.UserID(req.UserID)) && !h.authorizeUser(w, r, domain.UserID(req.UserID)) {
+		return
+	}
+
+	period, err := h.userService.AddUnavailability(r.Context(), domain.Unavailability{
+		UserID:              domain.UserID(req.UserID),
+		StartsAt:            req.StartsAt.UTC(),
+		EndsAt:              req.EndsAt.UTC(),
+		Reason:              req.Reason,
+		ReassignOpenReviews: req.ReassignOpenReviews,
+	})
+	if err != nil {
+		switch {
+		case errors.Is(err, domain.ErrInvalidPeriod):
+			writeError(w, stdhttp.StatusBadRequest, "BAD_REQUEST", "ends_at must be after starts_at")
+		case errors.Is(err, domain.ErrNotFound):
+			writeError(w, stdhttp.StatusNotFound, "NOT_FOUND", "resource not found")
+		default:
+			writeInternalError(w, r, err)
+		}
+		return
+	}
+
+	writeJSON(w, stdhttp.StatusCreated, addUnavailabilityResponse{
+		Unavailability: unavailabilityToDTO(period),
+	})
+}
+
+func (h *Handler) handleUserUnavailabilityDelete(w stdhttp.ResponseWriter, r *stdhttp.Request) {
+	if r.Method != stdhttp.MethodPost {
+		w.Header().Set("Allow", stdhttp.MethodPost)
+		writeError(w, stdhttp.StatusMethodNotAllowed, "METHOD_NOT_ALLOWED", "method not allowed")
+		return
+	}
+
+	defer func() {
+		_ = r.Body.Close()
+	}()
+	var req deleteUnavailabilityRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, stdhttp.StatusBadRequest, "BAD_REQUEST", "invalid json body")
+		return
+	}
+
+	if req.UnavailabilityID == 0 {
+		writeError(w, stdhttp.StatusBadRequest, "BAD_REQUEST", "unavailability_id is required")
+		return
+	}
+	if !h.authorizeUnavailability(w, r, req.UnavailabilityID) {
+		return
+	}
+
+	if err := h.userService.RemoveUnavailability(r.Context(), req.UnavailabilityID); err != nil {
+		switch {
+		case errors.Is(err, domain.ErrNotFound):
+			writeError(w, stdhttp.StatusNotFound, "NOT_FOUND", "resource not found")
+		default:
+			writeInternalError(w, r, err)
+		}
+		return
+	}
+
+	writeJSON(w, stdhttp.StatusOK, req)
+}
+
+func unavailabilityToDTO(u domain.Unavailability) unavailabilityDTO {
+	dto := unavailabilityDTO{
+		UnavailabilityID:    u.ID,
+		UserID:              string(u.UserID),
+		StartsAt:            u.StartsAt.UTC().Format(time.RFC3339),
+		EndsAt:              u.EndsAt.UTC().Format(time.RFC3339),
+		Reason:              u.Reason,
+		ReassignOpenReviews: u.ReassignOpenReviews,
+	}
+	if u.ReassignedAt != nil {
+		s := u.ReassignedAt.UTC().Format(time.RFC3339)
+		dto.ReassignedAt = &s
+	}
+	return dto
+}
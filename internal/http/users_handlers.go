@@ -13,11 +13,22 @@ type setIsActiveRequest struct {
 	IsActive bool   `json:"is_active"`
 }
 
+type setMaxOpenReviewsRequest struct {
+	UserID         string `json:"user_id"`
+	MaxOpenReviews int    `json:"max_open_reviews"`
+}
+
+type setMaxOpenReviewsResponse struct {
+	User userDTO `json:"user"`
+}
+
 type userDTO struct {
-	UserID   string `json:"user_id"`
-	Username string `json:"username"`
-	TeamName string `json:"team_name"`
-	IsActive bool   `json:"is_active"`
+	UserID         string `json:"user_id"`
+	Username       string `json:"username"`
+	TeamName       string `json:"team_name"`
+	IsActive       bool   `json:"is_active"`
+	SlackHandle    string `json:"slack_handle,omitempty"`
+	MaxOpenReviews int    `json:"max_open_reviews"`
 }
 
 type setIsActiveResponse struct {
@@ -56,6 +67,9 @@ func (h *Handler) handleUserSetIsActive(w stdhttp.ResponseWriter, r *stdhttp.Req
 		writeError(w, stdhttp.StatusBadRequest, "BAD_REQUEST", "user_id is required")
 		return
 	}
+	if !isSelf(r, domain.UserID(req.UserID)) && !h.authorizeUser(w, r, domain.UserID(req.UserID)) {
+		return
+	}
 
 	user, err := h.userService.SetIsActive(r.Context(), domain.UserID(req.UserID), req.IsActive)
 	if err != nil {
@@ -63,7 +77,7 @@ func (h *Handler) handleUserSetIsActive(w stdhttp.ResponseWriter, r *stdhttp.Req
 		case errors.Is(err, domain.ErrNotFound):
 			writeError(w, stdhttp.StatusNotFound, "NOT_FOUND", "resource not found")
 		default:
-			writeError(w, stdhttp.StatusInternalServerError, "INTERNAL", "internal error")
+			writeInternalError(w, r, err)
 		}
 		return
 	}
@@ -74,6 +88,49 @@ func (h *Handler) handleUserSetIsActive(w stdhttp.ResponseWriter, r *stdhttp.Req
 	writeJSON(w, stdhttp.StatusOK, resp)
 }
 
+func (h *Handler) handleUserSetMaxOpenReviews(w stdhttp.ResponseWriter, r *stdhttp.Request) {
+	if r.Method != stdhttp.MethodPost {
+		w.Header().Set("Allow", stdhttp.MethodPost)
+		writeError(w, stdhttp.StatusMethodNotAllowed, "METHOD_NOT_ALLOWED", "method not allowed")
+		return
+	}
+
+	defer func() {
+		_ = r.Body.Close()
+	}()
+	var req setMaxOpenReviewsRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, stdhttp.StatusBadRequest, "BAD_REQUEST", "invalid json body")
+		return
+	}
+
+	if req.UserID == "" {
+		writeError(w, stdhttp.StatusBadRequest, "BAD_REQUEST", "user_id is required")
+		return
+	}
+	if !h.authorizeUser(w, r, domain.UserID(req.UserID)) {
+		return
+	}
+
+	user, err := h.userService.SetMaxOpenReviews(r.Context(), domain.UserID(req.UserID), req.MaxOpenReviews)
+	if err != nil {
+		switch {
+		case errors.Is(err, domain.ErrInvalidCapacity):
+			writeError(w, stdhttp.StatusBadRequest, "BAD_REQUEST", "max_open_reviews must not be negative")
+		case errors.Is(err, domain.ErrNotFound):
+			writeError(w, stdhttp.StatusNotFound, "NOT_FOUND", "resource not found")
+		default:
+			writeInternalError(w, r, err)
+		}
+		return
+	}
+
+	resp := setMaxOpenReviewsResponse{
+		User: userToDTO(user),
+	}
+	writeJSON(w, stdhttp.StatusOK, resp)
+}
+
 func (h *Handler) handleUserGetReview(w stdhttp.ResponseWriter, r *stdhttp.Request) {
 	if r.Method != stdhttp.MethodGet {
 		w.Header().Set("Allow", stdhttp.MethodGet)
@@ -89,7 +146,7 @@ func (h *Handler) handleUserGetReview(w stdhttp.ResponseWriter, r *stdhttp.Reque
 
 	prs, err := h.prService.ListByReviewer(r.Context(), domain.UserID(userID))
 	if err != nil {
-		writeError(w, stdhttp.StatusInternalServerError, "INTERNAL", "internal error")
+		writeInternalError(w, r, err)
 		return
 	}
 
@@ -103,10 +160,12 @@ func (h *Handler) handleUserGetReview(w stdhttp.ResponseWriter, r *stdhttp.Reque
 
 func userToDTO(u domain.User) userDTO {
 	return userDTO{
-		UserID:   string(u.ID),
-		Username: u.Username,
-		TeamName: string(u.TeamName),
-		IsActive: u.IsActive,
+		UserID:         string(u.ID),
+		Username:       u.Username,
+		TeamName:       string(u.TeamName),
+		IsActive:       u.IsActive,
+		SlackHandle:    u.SlackHandle,
+		MaxOpenReviews: u.MaxOpenReviews,
 	}
 }
 
@@ -0,0 +1,179 @@
+package http
+
+import (
+	"context"
+	"errors"
+	"io"
+	stdhttp "net/http"
+
+	"pr-reviewer-service/internal/domain"
+	"pr-reviewer-service/internal/vcs"
+)
+
+const maxWebhookBodyBytes = 10 << 20
+
+type webhookConfig struct {
+	secret     []byte
+	identities vcs.IdentityMap
+}
+
+type vcsWebhookResponse struct {
+	Status        string `json:"status"`
+	PullRequestID string `json:"pull_request_id,omitempty"`
+}
+
+func (h *Handler) EnableGitHubWebhook(secret []byte, identities vcs.IdentityMap) {
+	h.github = &webhookConfig{secret: secret, identities: identities}
+}
+
+func (h *Handler) EnableGitLabWebhook(token []byte, identities vcs.IdentityMap) {
+	h.gitlab = &webhookConfig{secret: token, identities: identities}
+}
+
+func (h *Handler) handleGitHubWebhook(w stdhttp.ResponseWriter, r *stdhttp.Request) {
+	if r.Method != stdhttp.MethodPost {
+		w.Header().Set("Allow", stdhttp.MethodPost)
+		writeError(w, stdhttp.StatusMethodNotAllowed, "METHOD_NOT_ALLOWED", "method not allowed")
+		return
+	}
+
+	defer func() {
+		_ = r.Body.Close()
+	}()
+	body, err := io.ReadAll(io.LimitReader(r.Body, maxWebhookBodyBytes))
+	if err != nil {
+		writeError(w, stdhttp.StatusBadRequest, "BAD_REQUEST", "cannot read body")
+		return
+	}
+
+	if err := vcs.VerifyGitHubSignature(h.github.secret, body, r.Header.Get("X-Hub-Signature-256")); err != nil {
+		writeError(w, stdhttp.StatusUnauthorized, "INVALID_SIGNATURE", "invalid webhook signature")
+		return
+	}
+
+	switch r.Header.Get("X-GitHub-Event") {
+	case "ping":
+		writeJSON(w, stdhttp.StatusOK, vcsWebhookResponse{Status: "ok"})
+		return
+	case "pull_request":
+	default:
+		writeJSON(w, stdhttp.StatusOK, vcsWebhookResponse{Status: "ignored"})
+		return
+	}
+
+	ev, ok, err := vcs.ParseGitHubPullRequest(body, h.github.identities)
+	h.respondVCSEvent(w, r, ev, ok, err)
+}
+
+func (h *Handler) handleGitLabWebhook(w stdhttp.ResponseWriter, r *stdhttp.Request) {
+	if r.Method != stdhttp.MethodPost {
+		w.Header().Set("Allow", stdhttp.MethodPost)
+		writeError(w, stdhttp.StatusMethodNotAllowed, "METHOD_NOT_ALLOWED", "method not allowed")
+		return
+	}
+
+	defer func() {
+		_ = r.Body.Close()
+	}()
+	body, err := io.ReadAll(io.LimitReader(r.Body, maxWebhookBodyBytes))
+	if err != nil {
+		writeError(w, stdhttp.StatusBadRequest, "BAD_REQUEST", "cannot read body")
+		return
+	}
+
+	if err := vcs.VerifyGitLabToken(h.gitlab.secret, r.Header.Get("X-Gitlab-Token")); err != nil {
+		writeError(w, stdhttp.StatusUnauthorized, "INVALID_SIGNATURE", "invalid webhook token")
+		return
+	}
+
+	if r.Header.Get("X-Gitlab-Event") != "Merge Request Hook" {
+		writeJSON(w, stdhttp.StatusOK, vcsWebhookResponse{Status: "ignored"})
+		return
+	}
+
+	ev, ok, err := vcs.ParseGitLabMergeRequest(body, h.gitlab.identities)
+	h.respondVCSEvent(w, r, ev, ok, err)
+}
+
+func (h *Handler) respondVCSEvent(w stdhttp.ResponseWriter, r *stdhttp.Request, ev vcs.Event, ok bool, err error) {
+	if err != nil {
+		switch {
+		case errors.Is(err, vcs.ErrInvalidPayload):
+			writeError(w, stdhttp.StatusBadRequest, "BAD_REQUEST", "invalid webhook payload")
+		case errors.Is(err, vcs.ErrUnknownIdentity):
+			writeError(w, stdhttp.StatusUnprocessableEntity, "UNKNOWN_IDENTITY", "author login is not mapped to a user")
+		default:
+			writeInternalError(w, r, err)
+		}
+		return
+	}
+	if !ok {
+		writeJSON(w, stdhttp.StatusOK, vcsWebhookResponse{Status: "ignored"})
+		return
+	}
+
+	status, err := h.applyVCSEvent(r.Context(), ev)
+	if err != nil {
+		switch {
+		case errors.Is(err, domain.ErrNotFound):
+			writeError(w, stdhttp.StatusNotFound, "NOT_FOUND", "resource not found")
+		case errors.Is(err, domain.ErrPoolTooSmall):
+			writeError(w, stdhttp.StatusConflict, "POOL_TOO_SMALL", "not enough active reviewers in team")
+		case errors.Is(err, domain.ErrAllReviewersAtCapacity):
+			writeError(w, stdhttp.StatusUnprocessableEntity, "ALL_AT_CAPACITY", "all candidate reviewers are at capacity")
+		case errors.Is(err, domain.ErrInvalidTransition):
+			writeError(w, stdhttp.StatusConflict, "INVALID_TRANSITION", "pull request status transition is not allowed")
+		default:
+			writeInternalError(w, r, err)
+		}
+		return
+	}
+
+	writeJSON(w, stdhttp.StatusOK, vcsWebhookResponse{
+		Status:        status,
+		PullRequestID: string(ev.PullRequestID),
+	})
+}
+
+func (h *Handler) applyVCSEvent(ctx context.Context, ev vcs.Event) (string, error) {
+	switch ev.Action {
+	case vcs.ActionOpened:
+		var err error
+		if ev.Draft {
+			_, err = h.prService.CreateDraft(ctx, ev.PullRequestID, ev.Title, ev.AuthorID)
+		} else {
+			_, err = h.prService.Create(ctx, ev.PullRequestID, ev.Title, ev.AuthorID, nil)
+		}
+		if errors.Is(err, domain.ErrPullRequestExists) {
+			// Providers redeliver webhooks, so a repeated "opened" is not an error.
+			return "duplicate", nil
+		}
+		if err != nil {
+			return "", err
+		}
+		return "created", nil
+	case vcs.ActionMerged:
+		// The merge already happened upstream, so the approval gate does not apply.
+		if _, err := h.prService.Merge(ctx, ev.PullRequestID, true); err != nil {
+			return "", err
+		}
+		return "merged", nil
+	case vcs.ActionClosed:
+		if _, err := h.prService.Close(ctx, ev.PullRequestID); err != nil {
+			return "", err
+		}
+		return "closed", nil
+	case vcs.ActionReopened:
+		if _, err := h.prService.Reopen(ctx, ev.PullRequestID); err != nil {
+			return "", err
+		}
+		return "reopened", nil
+	case vcs.ActionReady:
+		if _, err := h.prService.Ready(ctx, ev.PullRequestID, nil); err != nil {
+			return "", err
+		}
+		return "ready", nil
+	default:
+		return "ignored", nil
+	}
+}
@@ -0,0 +1,236 @@
+package http
+
+import (
+	"encoding/json"
+	"errors"
+	stdhttp "net/http"
+	"time"
+
+	"pr-reviewer-service/internal/domain"
+	"pr-reviewer-service/internal/service"
+)
+
+type webhookSubscribeRequest struct {
+	URL    string   `json:"url"`
+	Secret string   `json:"secret"`
+	Events []string `json:"events"`
+}
+
+type webhookSubscriptionDTO struct {
+	SubscriptionID int64    `json:"subscription_id"`
+	URL            string   `json:"url"`
+	Events         []string `json:"events"`
+	Secret         string   `json:"secret,omitempty"`
+	CreatedAt      string   `json:"createdAt"`
+}
+
+type webhookSubscribeResponse struct {
+	Subscription webhookSubscriptionDTO `json:"subscription"`
+}
+
+type webhookListResponse struct {
+	Subscriptions []webhookSubscriptionDTO `json:"subscriptions"`
+}
+
+type webhookDeleteRequest struct {
+	SubscriptionID int64 `json:"subscription_id"`
+}
+
+type webhookDeliveryDTO struct {
+	DeliveryID     int64  `json:"delivery_id"`
+	SubscriptionID int64  `json:"subscription_id"`
+	URL            string `json:"url"`
+	EventID        int64  `json:"event_id"`
+	EventType      string `json:"event_type"`
+	Attempts       int    `json:"attempts"`
+	LastError      string `json:"last_error"`
+}
+
+type webhookDeadLettersResponse struct {
+	Deliveries []webhookDeliveryDTO `json:"deliveries"`
+}
+
+type webhookRedriveRequest struct {
+	DeliveryID int64 `json:"delivery_id"`
+}
+
+func (h *Handler) EnableOutgoingWebhooks(svc *service.WebhookService) {
+	h.webhookService = svc
+}
+
+func (h *Handler) handleWebhookSubscribe(w stdhttp.ResponseWriter, r *stdhttp.Request) {
+	if r.Method != stdhttp.MethodPost {
+		w.Header().Set("Allow", stdhttp.MethodPost)
+		writeError(w, stdhttp.StatusMethodNotAllowed, "METHOD_NOT_ALLOWED", "method not allowed")
+		return
+	}
+
+	defer func() {
+		_ = r.Body.Close()
+	}()
+	var req webhookSubscribeRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, stdhttp.StatusBadRequest, "BAD_REQUEST", "invalid json body")
+		return
+	}
+
+	if req.URL == "" {
+		writeError(w, stdhttp.StatusBadRequest, "BAD_REQUEST", "url is required")
+		return
+	}
+
+	events := make([]domain.OutboxEventType, 0, len(req.Events))
+	for _, e := range req.Events {
+		events = append(events, domain.OutboxEventType(e))
+	}
+
+	sub, err := h.webhookService.Subscribe(r.Context(), req.URL, req.Secret, events)
+	if err != nil {
+		switch {
+		case errors.Is(err, domain.ErrInvalidWebhook):
+			writeError(w, stdhttp.StatusBadRequest, "BAD_REQUEST", "invalid url or event type")
+		default:
+			writeInternalError(w, r, err)
+		}
+		return
+	}
+
+	dto := subscriptionToDTO(sub)
+	dto.Secret = sub.Secret
+	writeJSON(w, stdhttp.StatusCreated, webhookSubscribeResponse{Subscription: dto})
+}
+
+func (h *Handler) handleWebhookList(w stdhttp.ResponseWriter, r *stdhttp.Request) {
+	if r.Method != stdhttp.MethodGet {
+		w.Header().Set("Allow", stdhttp.MethodGet)
+		writeError(w, stdhttp.StatusMethodNotAllowed, "METHOD_NOT_ALLOWED", "method not allowed")
+		return
+	}
+
+	subs, err := h.webhookService.List(r.Context())
+	if err != nil {
+		writeInternalError(w, r, err)
+		return
+	}
+
+	resp := webhookListResponse{
+		Subscriptions: make([]webhookSubscriptionDTO, 0, len(subs)),
+	}
+	for _, sub := range subs {
+		resp.Subscriptions = append(resp.Subscriptions, subscriptionToDTO(sub))
+	}
+
+	writeJSON(w, stdhttp.StatusOK, resp)
+}
+
+func (h *Handler) handleWebhookDelete(w stdhttp.ResponseWriter, r *stdhttp.Request) {
+	if r.Method != stdhttp.MethodPost {
+		w.Header().Set("Allow", stdhttp.MethodPost)
+		writeError(w, stdhttp.StatusMethodNotAllowed, "METHOD_NOT_ALLOWED", "method not allowed")
+		return
+	}
+
+	defer func() {
+		_ = r.Body.Close()
+	}()
+	var req webhookDeleteRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, stdhttp.StatusBadRequest, "BAD_REQUEST", "invalid json body")
+		return
+	}
+
+	if req.SubscriptionID == 0 {
+		writeError(w, stdhttp.StatusBadRequest, "BAD_REQUEST", "subscription_id is required")
+		return
+	}
+
+	if err := h.webhookService.Unsubscribe(r.Context(), req.SubscriptionID); err != nil {
+		switch {
+		case errors.Is(err, domain.ErrNotFound):
+			writeError(w, stdhttp.StatusNotFound, "NOT_FOUND", "resource not found")
+		default:
+			writeInternalError(w, r, err)
+		}
+		return
+	}
+
+	writeJSON(w, stdhttp.StatusOK, req)
+}
+
+func (h *Handler) handleWebhookDeadLetters(w stdhttp.ResponseWriter, r *stdhttp.Request) {
+	if r.Method != stdhttp.MethodGet {
+		w.Header().Set("Allow", stdhttp.MethodGet)
+		writeError(w, stdhttp.StatusMethodNotAllowed, "METHOD_NOT_ALLOWED", "method not allowed")
+		return
+	}
+
+	deliveries, err := h.webhookService.DeadLetters(r.Context())
+	if err != nil {
+		writeInternalError(w, r, err)
+		return
+	}
+
+	resp := webhookDeadLettersResponse{
+		Deliveries: make([]webhookDeliveryDTO, 0, len(deliveries)),
+	}
+	for _, d := range deliveries {
+		resp.Deliveries = append(resp.Deliveries, webhookDeliveryDTO{
+			DeliveryID:     d.ID,
+			SubscriptionID: d.SubscriptionID,
+			URL:            d.URL,
+			EventID:        d.Event.ID,
+			EventType:      string(d.Event.Type),
+			Attempts:       d.Attempts,
+			LastError:      d.LastError,
+		})
+	}
+
+	writeJSON(w, stdhttp.StatusOK, resp)
+}
+
+func (h *Handler) handleWebhookRedrive(w stdhttp.ResponseWriter, r *stdhttp.Request) {
+	if r.Method != stdhttp.MethodPost {
+		w.Header().Set("Allow", stdhttp.MethodPost)
+		writeError(w, stdhttp.StatusMethodNotAllowed, "METHOD_NOT_ALLOWED", "method not allowed")
+		return
+	}
+
+	defer func() {
+		_ = r.Body.Close()
+	}()
+	var req webhookRedriveRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, stdhttp.StatusBadRequest, "BAD_REQUEST", "invalid json body")
+		return
+	}
+
+	if req.DeliveryID == 0 {
+		writeError(w, stdhttp.StatusBadRequest, "BAD_REQUEST", "delivery_id is required")
+		return
+	}
+
+	if err := h.webhookService.Redrive(r.Context(), req.DeliveryID); err != nil {
+		switch {
+		case errors.Is(err, domain.ErrNotFound):
+			writeError(w, stdhttp.StatusNotFound, "NOT_FOUND", "resource not found")
+		default:
+			writeInternalError(w, r, err)
+		}
+		return
+	}
+
+	writeJSON(w, stdhttp.StatusOK, req)
+}
+
+func subscriptionToDTO(sub domain.WebhookSubscription) webhookSubscriptionDTO {
+	dto := webhookSubscriptionDTO{
+		SubscriptionID: sub.ID,
+		URL:            sub.URL,
+		Events:         make([]string, 0, len(sub.Events)),
+		CreatedAt:      sub.CreatedAt.UTC().Format(time.RFC3339),
+	}
+	for _, e := range sub.Events {
+		dto.Events = append(dto.Events, string(e))
+	}
+	return dto
+}
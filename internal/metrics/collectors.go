@@ -0,0 +1,88 @@
+package metrics
+
+import (
+	"context"
+	"database/sql"
+
+	"pr-reviewer-service/internal/domain"
+)
+
+const namespace = "pr_reviewer_"
+
+// RegisterDBStats exports the connection pool stats of db.
+func RegisterDBStats(r *Registry, db *sql.DB) {
+	stat := func(value func(s sql.DBStats) float64) func(ctx context.Context) ([]Sample, error) {
+		return func(ctx context.Context) ([]Sample, error) {
+			return []Sample{{Value: value(db.Stats())}}, nil
+		}
+	}
+
+	r.NewGaugeFunc(namespace+"db_max_open_connections", "Maximum number of open connections to the database.", nil,
+		stat(func(s sql.DBStats) float64 { return float64(s.MaxOpenConnections) }))
+	r.NewGaugeFunc(namespace+"db_open_connections", "Established connections, in use or idle.", nil,
+		stat(func(s sql.DBStats) float64 { return float64(s.OpenConnections) }))
+	r.NewGaugeFunc(namespace+"db_in_use_connections", "Connections currently in use.", nil,
+		stat(func(s sql.DBStats) float64 { return float64(s.InUse) }))
+	r.NewGaugeFunc(namespace+"db_idle_connections", "Idle connections.", nil,
+		stat(func(s sql.DBStats) float64 { return float64(s.Idle) }))
+	r.NewCounterFunc(namespace+"db_wait_count_total", "Connections waited for.", nil,
+		stat(func(s sql.DBStats) float64 { return float64(s.WaitCount) }))
+	r.NewCounterFunc(namespace+"db_wait_duration_seconds_total", "Time blocked waiting for a connection.", nil,
+		stat(func(s sql.DBStats) float64 { return s.WaitDuration.Seconds() }))
+}
+
+// PRMetrics counts assignment outcomes for the PR service and reports open
+// PRs and review load from the repository at scrape time.
+type PRMetrics struct {
+	replaced     *Counter
+	noCandidate  *Counter
+	understaffed *Counter
+}
+
+func NewPRMetrics(r *Registry, prs domain.PullRequestRepository) *PRMetrics {
+	r.NewGaugeFunc(namespace+"open_pull_requests", "Open pull requests by the author's team.", []string{"team"},
+		func(ctx context.Context) ([]Sample, error) {
+			counts, err := prs.OpenByTeam(ctx)
+			if err != nil {
+				return nil, err
+			}
+			samples := make([]Sample, 0, len(counts))
+			for team, n := range counts {
+				samples = append(samples, Sample{Labels: []string{string(team)}, Value: float64(n)})
+			}
+			return samples, nil
+		})
+	r.NewGaugeFunc(namespace+"open_assignments", "Reviews assigned on open pull requests by reviewer.", []string{"reviewer"},
+		func(ctx context.Context) ([]Sample, error) {
+			counts, err := prs.OpenAssignmentsByUser(ctx)
+			if err != nil {
+				return nil, err
+			}
+			samples := make([]Sample, 0, len(counts))
+			for id, n := range counts {
+				samples = append(samples, Sample{Labels: []string{string(id)}, Value: float64(n)})
+			}
+			return samples, nil
+		})
+
+	return &PRMetrics{
+		replaced: r.NewCounter(namespace+"reviewer_reassignments_total",
+			"Reviewers replaced on a pull request.", "reason"),
+		noCandidate: r.NewCounter(namespace+"reassignment_no_candidate_total",
+			"Reassignments that found no replacement reviewer.", "reason"),
+		understaffed: r.NewCounter(namespace+"understaffed_pull_requests_total",
+			"Pull requests opened with fewer reviewers than their team asks for.", "team"),
+	}
+}
+
+func (m *PRMetrics) ReviewerReplaced(reason domain.AssignmentReason) {
+	m.replaced.Inc(string(reason))
+}
+
+func (m *PRMetrics) NoCandidate(reason domain.AssignmentReason) {
+	m.noCandidate.Inc(string(reason))
+}
+
+func (m *PRMetrics) Understaffed(team domain.TeamName) {
+	m.understaffed.Inc(string(team))
+}
@@ -0,0 +1,72 @@
+package metrics
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"testing"
+)
+
+func TestRegistryWritesTextFormat(t *testing.T) {
+	r := NewRegistry()
+
+	requests := r.NewCounter("requests_total", "Requests by route.", "route", "status")
+	requests.Inc("/a", "200")
+	requests.Add(2, "/a", "200")
+	requests.Inc(`/b"\`, "500")
+
+	latency := r.NewHistogram("latency_seconds", "Latency\nin seconds.", []float64{1, 0.1}, "route")
+	latency.Observe(0.05, "/a")
+	latency.Observe(0.5, "/a")
+	latency.Observe(5, "/a")
+
+	r.NewGaugeFunc("open", "Open things.", []string{"team"}, func(ctx context.Context) ([]Sample, error) {
+		return []Sample{{Labels: []string{"z"}, Value: 2}, {Labels: []string{"a"}, Value: 1.5}}, nil
+	})
+	r.NewGaugeFunc("broken", "Never collected.", nil, func(ctx context.Context) ([]Sample, error) {
+		return nil, errors.New("db down")
+	})
+	r.NewCounterFunc("waits_total", "Waits.", nil, func(ctx context.Context) ([]Sample, error) {
+		return []Sample{{Value: 7}}, nil
+	})
+
+	var out bytes.Buffer
+	if err := r.Write(context.Background(), &out); err != nil {
+		t.Fatalf("Write returned error: %v", err)
+	}
+
+	want := `# HELP latency_seconds Latency\nin seconds.
+# TYPE latency_seconds histogram
+latency_seconds_bucket{route="/a",le="0.1"} 1
+latency_seconds_bucket{route="/a",le="1"} 2
+latency_seconds_bucket{route="/a",le="+Inf"} 3
+latency_seconds_sum{route="/a"} 5.55
+latency_seconds_count{route="/a"} 3
+# HELP open Open things.
+# TYPE open gauge
+open{team="a"} 1.5
+open{team="z"} 2
+# HELP requests_total Requests by route.
+# TYPE requests_total counter
+requests_total{route="/a",status="200"} 3
+requests_total{route="/b\"\\",status="500"} 1
+# HELP waits_total Waits.
+# TYPE waits_total counter
+waits_total 7
+`
+	if out.String() != want {
+		t.Fatalf("unexpected output:\n%s\nwant:\n%s", out.String(), want)
+	}
+}
+
+func TestRegistryRejectsDuplicates(t *testing.T) {
+	r := NewRegistry()
+	r.NewCounter("dup", "First.")
+
+	defer func() {
+		if recover() == nil {
+			t.Fatalf("expected a panic for a duplicate name")
+		}
+	}()
+	r.NewCounter("dup", "Second.")
+}
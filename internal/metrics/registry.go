@@ -0,0 +1,154 @@
+// Package metrics exposes counters, histograms and scrape-time gauges in the
+// Prometheus text format.
+package metrics
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
+	"math"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// DefaultBuckets suit request latencies in seconds.
+var DefaultBuckets = []float64{.005, .01, .025, .05, .1, .25, .5, 1, 2.5, 5, 10}
+
+// Sample is one labelled value of a gauge computed at scrape time. Labels
+// are given in the order the gauge declared them.
+type Sample struct {
+	Labels []string
+	Value  float64
+}
+
+type family interface {
+	write(ctx context.Context, w *bufio.Writer) error
+}
+
+// Registry holds metrics by name and serves them.
+type Registry struct {
+	mu       sync.Mutex
+	families map[string]family
+}
+
+func NewRegistry() *Registry {
+	return &Registry{families: make(map[string]family)}
+}
+
+func (r *Registry) register(name string, f family) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if _, ok := r.families[name]; ok {
+		panic("metrics: duplicate metric " + name)
+	}
+	r.families[name] = f
+}
+
+// Handler serves every metric, sorted by name. A gauge whose collector
+// fails is left out rather than failing the whole scrape.
+func (r *Registry) Handler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		w.Header().Set("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+		_ = r.Write(req.Context(), w)
+	})
+}
+
+func (r *Registry) Write(ctx context.Context, out io.Writer) error {
+	r.mu.Lock()
+	names := make([]string, 0, len(r.families))
+	for name := range r.families {
+		names = append(names, name)
+	}
+	families := make([]family, 0, len(names))
+	slices.Sort(names)
+	for _, name := range names {
+		families = append(families, r.families[name])
+	}
+	r.mu.Unlock()
+
+	w := bufio.NewWriter(out)
+	for i, f := range families {
+		if err := f.write(ctx, w); err != nil {
+			slog.WarnContext(ctx, "metrics collection failed", slog.String("metric", names[i]), slog.String("error", err.Error()))
+		}
+	}
+	return w.Flush()
+}
+
+type meta struct {
+	name   string
+	help   string
+	labels []string
+}
+
+func (m meta) header(w *bufio.Writer, typ string) {
+	fmt.Fprintf(w, "# HELP %s %s\n# TYPE %s %s\n", m.name, escapeHelp(m.help), m.name, typ)
+}
+
+// series formats name{labels} with extra label pairs appended.
+func (m meta) series(name string, values []string, extra ...string) string {
+	var b strings.Builder
+	b.WriteString(name)
+	if len(m.labels)+len(extra) == 0 {
+		return b.String()
+	}
+	b.WriteByte('{')
+	for i, l := range m.labels {
+		if i > 0 {
+			b.WriteByte(',')
+		}
+		fmt.Fprintf(&b, "%s=\"%s\"", l, escapeLabel(values[i]))
+	}
+	for i := 0; i+1 < len(extra); i += 2 {
+		if len(m.labels) > 0 || i > 0 {
+			b.WriteByte(',')
+		}
+		fmt.Fprintf(&b, "%s=\"%s\"", extra[i], escapeLabel(extra[i+1]))
+	}
+	b.WriteByte('}')
+	return b.String()
+}
+
+func (m meta) check(values []string) {
+	if len(values) != len(m.labels) {
+		panic(fmt.Sprintf("metrics: %s wants %d label values, got %d", m.name, len(m.labels), len(values)))
+	}
+}
+
+var (
+	helpEscaper  = strings.NewReplacer(`\`, `\\`, "\n", `\n`)
+	labelEscaper = strings.NewReplacer(`\`, `\\`, "\n", `\n`, `"`, `\"`)
+)
+
+func escapeHelp(s string) string  { return helpEscaper.Replace(s) }
+func escapeLabel(s string) string { return labelEscaper.Replace(s) }
+
+func formatValue(v float64) string {
+	switch {
+	case math.IsInf(v, 1):
+		return "+Inf"
+	case math.IsInf(v, -1):
+		return "-Inf"
+	default:
+		return strconv.FormatFloat(v, 'g', -1, 64)
+	}
+}
+
+// key joins label values into a map key; \xff cannot occur in valid UTF-8.
+func key(values []string) string {
+	return strings.Join(values, "\xff")
+}
+
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	slices.Sort(keys)
+	return keys
+}
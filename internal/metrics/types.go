@@ -0,0 +1,154 @@
+package metrics
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+	"sync"
+)
+
+// Counter is a monotonically increasing value per label combination.
+type Counter struct {
+	meta
+	mu     sync.Mutex
+	values map[string]float64
+}
+
+func (r *Registry) NewCounter(name, help string, labels ...string) *Counter {
+	c := &Counter{meta: meta{name: name, help: help, labels: labels}, values: make(map[string]float64)}
+	r.register(name, c)
+	return c
+}
+
+func (c *Counter) Inc(labelValues ...string) {
+	c.Add(1, labelValues...)
+}
+
+func (c *Counter) Add(v float64, labelValues ...string) {
+	c.check(labelValues)
+	c.mu.Lock()
+	c.values[key(labelValues)] += v
+	c.mu.Unlock()
+}
+
+func (c *Counter) write(ctx context.Context, w *bufio.Writer) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.header(w, "counter")
+	for _, k := range sortedKeys(c.values) {
+		fmt.Fprintf(w, "%s %s\n", c.series(c.name, splitKey(k, len(c.labels))), formatValue(c.values[k]))
+	}
+	return nil
+}
+
+// Histogram counts observations into cumulative buckets per label
+// combination.
+type Histogram struct {
+	meta
+	buckets []float64
+	mu      sync.Mutex
+	values  map[string]*histogramValue
+}
+
+type histogramValue struct {
+	counts []uint64
+	sum    float64
+	count  uint64
+}
+
+func (r *Registry) NewHistogram(name, help string, buckets []float64, labels ...string) *Histogram {
+	buckets = slices.Clone(buckets)
+	slices.Sort(buckets)
+	h := &Histogram{
+		meta:    meta{name: name, help: help, labels: labels},
+		buckets: buckets,
+		values:  make(map[string]*histogramValue),
+	}
+	r.register(name, h)
+	return h
+}
+
+func (h *Histogram) Observe(v float64, labelValues ...string) {
+	h.check(labelValues)
+	k := key(labelValues)
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	hv, ok := h.values[k]
+	if !ok {
+		hv = &histogramValue{counts: make([]uint64, len(h.buckets))}
+		h.values[k] = hv
+	}
+	for i, upper := range h.buckets {
+		if v <= upper {
+			hv.counts[i]++
+		}
+	}
+	hv.sum += v
+	hv.count++
+}
+
+func (h *Histogram) write(ctx context.Context, w *bufio.Writer) error {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	h.header(w, "histogram")
+	for _, k := range sortedKeys(h.values) {
+		values := splitKey(k, len(h.labels))
+		hv := h.values[k]
+		for i, upper := range h.buckets {
+			fmt.Fprintf(w, "%s %d\n", h.series(h.name+"_bucket", values, "le", formatValue(upper)), hv.counts[i])
+		}
+		fmt.Fprintf(w, "%s %d\n", h.series(h.name+"_bucket", values, "le", "+Inf"), hv.count)
+		fmt.Fprintf(w, "%s %s\n", h.series(h.name+"_sum", values), formatValue(hv.sum))
+		fmt.Fprintf(w, "%s %d\n", h.series(h.name+"_count", values), hv.count)
+	}
+	return nil
+}
+
+// funcFamily computes its samples on every scrape, for values that live
+// elsewhere such as database counts or pool stats.
+type funcFamily struct {
+	meta
+	typ     string
+	collect func(ctx context.Context) ([]Sample, error)
+}
+
+// NewGaugeFunc registers a gauge whose samples collect computes on every
+// scrape.
+func (r *Registry) NewGaugeFunc(name, help string, labels []string, collect func(ctx context.Context) ([]Sample, error)) {
+	r.register(name, &funcFamily{meta: meta{name: name, help: help, labels: labels}, typ: "gauge", collect: collect})
+}
+
+// NewCounterFunc is NewGaugeFunc for totals kept elsewhere, such as the
+// wait counts of database/sql.
+func (r *Registry) NewCounterFunc(name, help string, labels []string, collect func(ctx context.Context) ([]Sample, error)) {
+	r.register(name, &funcFamily{meta: meta{name: name, help: help, labels: labels}, typ: "counter", collect: collect})
+}
+
+func (g *funcFamily) write(ctx context.Context, w *bufio.Writer) error {
+	samples, err := g.collect(ctx)
+	if err != nil {
+		return err
+	}
+	slices.SortFunc(samples, func(a, b Sample) int {
+		return strings.Compare(key(a.Labels), key(b.Labels))
+	})
+
+	g.header(w, g.typ)
+	for _, s := range samples {
+		g.check(s.Labels)
+		fmt.Fprintf(w, "%s %s\n", g.series(g.name, s.Labels), formatValue(s.Value))
+	}
+	return nil
+}
+
+func splitKey(k string, n int) []string {
+	if n == 0 {
+		return nil
+	}
+	return strings.SplitN(k, "\xff", n)
+}
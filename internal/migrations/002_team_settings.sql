@@ -0,0 +1,6 @@
+CREATE TABLE IF NOT EXISTS team_settings (
+    team_name       TEXT PRIMARY KEY REFERENCES teams(team_name) ON DELETE CASCADE,
+    reviewers_count INT  NOT NULL DEFAULT 2 CHECK (reviewers_count >= 0),
+    strategy        TEXT NOT NULL DEFAULT 'random' CHECK (strategy IN ('random', 'least_loaded')),
+    min_pool_size   INT  NOT NULL DEFAULT 0 CHECK (min_pool_size >= 0)
+    );
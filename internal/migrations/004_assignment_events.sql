@@ -0,0 +1,12 @@
+CREATE TABLE IF NOT EXISTS reviewer_assignment_events (
+    event_id        BIGSERIAL PRIMARY KEY,
+    pull_request_id TEXT NOT NULL REFERENCES pull_requests(pull_request_id) ON DELETE CASCADE,
+    event_type      TEXT NOT NULL CHECK (event_type IN ('ASSIGNED', 'UNASSIGNED', 'REPLACED')),
+    user_id         TEXT NOT NULL REFERENCES users(user_id),
+    replaced_by     TEXT REFERENCES users(user_id),
+    actor_id        TEXT,
+    reason          TEXT NOT NULL CHECK (reason IN ('create', 'manual_reassign', 'bulk_deactivation')),
+    created_at      TIMESTAMPTZ NOT NULL DEFAULT now()
+    );
+
+CREATE INDEX IF NOT EXISTS idx_assignment_events_pr ON reviewer_assignment_events(pull_request_id, event_id);
@@ -0,0 +1,34 @@
+CREATE TABLE IF NOT EXISTS outbox_events (
+    event_id      BIGSERIAL PRIMARY KEY,
+    event_type    TEXT NOT NULL,
+    payload       JSONB NOT NULL,
+    created_at    TIMESTAMPTZ NOT NULL DEFAULT now(),
+    dispatched_at TIMESTAMPTZ
+    );
+
+CREATE TABLE IF NOT EXISTS webhook_subscriptions (
+    subscription_id BIGSERIAL PRIMARY KEY,
+    url             TEXT NOT NULL,
+    secret          TEXT NOT NULL,
+    created_at      TIMESTAMPTZ NOT NULL DEFAULT now()
+    );
+
+CREATE TABLE IF NOT EXISTS webhook_subscription_events (
+    subscription_id BIGINT NOT NULL REFERENCES webhook_subscriptions(subscription_id) ON DELETE CASCADE,
+    event_type      TEXT NOT NULL,
+    PRIMARY KEY (subscription_id, event_type)
+    );
+
+CREATE TABLE IF NOT EXISTS webhook_deliveries (
+    delivery_id     BIGSERIAL PRIMARY KEY,
+    subscription_id BIGINT NOT NULL REFERENCES webhook_subscriptions(subscription_id) ON DELETE CASCADE,
+    event_id        BIGINT NOT NULL REFERENCES outbox_events(event_id) ON DELETE CASCADE,
+    status          TEXT NOT NULL CHECK (status IN ('PENDING', 'DELIVERED', 'DEAD')),
+    attempts        INT  NOT NULL DEFAULT 0,
+    next_attempt_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    last_error      TEXT NOT NULL DEFAULT '',
+    UNIQUE (subscription_id, event_id)
+    );
+
+CREATE INDEX IF NOT EXISTS idx_outbox_undispatched ON outbox_events(event_id) WHERE dispatched_at IS NULL;
+CREATE INDEX IF NOT EXISTS idx_webhook_deliveries_due ON webhook_deliveries(next_attempt_at) WHERE status = 'PENDING';
@@ -0,0 +1,5 @@
+ALTER TABLE users
+    ADD COLUMN IF NOT EXISTS slack_handle TEXT NOT NULL DEFAULT '';
+
+ALTER TABLE team_settings
+    ADD COLUMN IF NOT EXISTS slack_webhook_url TEXT NOT NULL DEFAULT '';
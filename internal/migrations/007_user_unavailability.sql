@@ -0,0 +1,19 @@
+CREATE TABLE IF NOT EXISTS user_unavailability (
+    unavailability_id     BIGSERIAL PRIMARY KEY,
+    user_id               TEXT NOT NULL REFERENCES users(user_id) ON DELETE CASCADE,
+    starts_at             TIMESTAMPTZ NOT NULL,
+    ends_at               TIMESTAMPTZ NOT NULL,
+    reason                TEXT NOT NULL DEFAULT '',
+    reassign_open_reviews BOOLEAN NOT NULL DEFAULT FALSE,
+    reassigned_at         TIMESTAMPTZ,
+    CHECK (ends_at > starts_at)
+    );
+
+CREATE INDEX IF NOT EXISTS idx_user_unavailability_user ON user_unavailability(user_id, ends_at);
+
+ALTER TABLE reviewer_assignment_events
+    DROP CONSTRAINT IF EXISTS reviewer_assignment_events_reason_check;
+
+ALTER TABLE reviewer_assignment_events
+    ADD CONSTRAINT reviewer_assignment_events_reason_check
+    CHECK (reason IN ('create', 'manual_reassign', 'bulk_deactivation', 'unavailable'));
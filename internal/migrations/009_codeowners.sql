@@ -0,0 +1,8 @@
+CREATE TABLE IF NOT EXISTS team_codeowners (
+    team_name  TEXT PRIMARY KEY REFERENCES teams(team_name) ON DELETE CASCADE,
+    content    TEXT NOT NULL,
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT now()
+    );
+
+ALTER TABLE pull_request_reviewers
+    ADD COLUMN IF NOT EXISTS matched_rule TEXT;
@@ -0,0 +1,6 @@
+ALTER TABLE pull_request_reviewers
+    ADD COLUMN IF NOT EXISTS review_state TEXT NOT NULL DEFAULT 'PENDING'
+    CHECK (review_state IN ('PENDING', 'APPROVED', 'CHANGES_REQUESTED', 'COMMENTED'));
+
+ALTER TABLE team_settings
+    ADD COLUMN IF NOT EXISTS required_approvals INT NOT NULL DEFAULT 0 CHECK (required_approvals >= 0);
@@ -0,0 +1,16 @@
+ALTER TABLE pull_requests
+    DROP CONSTRAINT IF EXISTS pull_requests_status_check;
+
+ALTER TABLE pull_requests
+    ADD CONSTRAINT pull_requests_status_check
+    CHECK (status IN ('DRAFT', 'OPEN', 'MERGED', 'CLOSED'));
+
+ALTER TABLE pull_requests
+    ADD COLUMN IF NOT EXISTS closed_at TIMESTAMPTZ;
+
+ALTER TABLE reviewer_assignment_events
+    DROP CONSTRAINT IF EXISTS reviewer_assignment_events_reason_check;
+
+ALTER TABLE reviewer_assignment_events
+    ADD CONSTRAINT reviewer_assignment_events_reason_check
+    CHECK (reason IN ('create', 'manual_reassign', 'bulk_deactivation', 'unavailable', 'ready_for_review'));
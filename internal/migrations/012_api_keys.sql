@@ -0,0 +1,10 @@
+CREATE TABLE IF NOT EXISTS api_keys (
+    api_key_id BIGSERIAL PRIMARY KEY,
+    name       TEXT NOT NULL,
+    role       TEXT NOT NULL CHECK (role IN ('admin', 'team_lead', 'read_only')),
+    team_name  TEXT REFERENCES teams(team_name) ON DELETE CASCADE,
+    key_hash   TEXT NOT NULL UNIQUE,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    revoked_at TIMESTAMPTZ,
+    CHECK ((role = 'team_lead') = (team_name IS NOT NULL))
+    );
@@ -0,0 +1,9 @@
+CREATE TABLE IF NOT EXISTS user_status_changes (
+    change_id  BIGSERIAL PRIMARY KEY,
+    user_id    TEXT NOT NULL REFERENCES users(user_id) ON DELETE CASCADE,
+    is_active  BOOLEAN NOT NULL,
+    actor_id   TEXT,
+    changed_at TIMESTAMPTZ NOT NULL DEFAULT now()
+    );
+
+CREATE INDEX IF NOT EXISTS idx_user_status_changes_user ON user_status_changes(user_id, change_id);
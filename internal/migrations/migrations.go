@@ -5,28 +5,208 @@ import (
 	"database/sql"
 	"embed"
 	"fmt"
-	"strings"
+	"io/fs"
+	"regexp"
+	"sort"
+	"strconv"
+	"time"
 )
 
 //go:embed *.sql
 var files embed.FS
 
+// lockKey identifies the advisory lock held while migrating, so replicas
+// starting together apply migrations one at a time.
+const lockKey int64 = 0x7072727673 // "prrvs"
+
+var fileName = regexp.MustCompile(`^(\d{3,})_([a-z0-9_]+)\.sql$`)
+
+type Migration struct {
+	Version int
+	Name    string
+	SQL     string
+}
+
+// MigrationStatus describes a migration and, if applied, when.
+type MigrationStatus struct {
+	Migration
+	AppliedAt *time.Time
+}
+
+// Load returns the embedded Postgres migrations ordered by version.
+func Load() ([]Migration, error) {
+	return LoadFS(files)
+}
+
+// LoadFS reads NNN_name.sql files from the root of fsys, ordered by version.
+func LoadFS(fsys fs.FS) ([]Migration, error) {
+	entries, err := fs.ReadDir(fsys, ".")
+	if err != nil {
+		return nil, fmt.Errorf("read migrations: %w", err)
+	}
+
+	var res []Migration
+	seen := make(map[int]string)
+	for _, e := range entries {
+		if e.IsDir() {
+			continue
+		}
+		m := fileName.FindStringSubmatch(e.Name())
+		if m == nil {
+			return nil, fmt.Errorf("migration %s: name must look like NNN_name.sql", e.Name())
+		}
+
+		version, err := strconv.Atoi(m[1])
+		if err != nil {
+			return nil, fmt.Errorf("migration %s: %w", e.Name(), err)
+		}
+		if prev, ok := seen[version]; ok {
+			return nil, fmt.Errorf("migrations %s and %s share version %d", prev, e.Name(), version)
+		}
+		seen[version] = e.Name()
+
+		script, err := fs.ReadFile(fsys, e.Name())
+		if err != nil {
+			return nil, fmt.Errorf("read migration %s: %w", e.Name(), err)
+		}
+		res = append(res, Migration{Version: version, Name: m[2], SQL: string(script)})
+	}
+
+	sort.Slice(res, func(i, j int) bool { return res[i].Version < res[j].Version })
+	return res, nil
+}
+
+// Run applies pending migrations in version order, each in its own
+// transaction. Databases migrated before versions were tracked have no
+// schema_migrations table; every script is idempotent, so they are simply
+// replayed once and recorded.
 func Run(ctx context.Context, db *sql.DB) error {
-	script, err := files.ReadFile("001_init.sql")
+	all, err := Load()
+	if err != nil {
+		return err
+	}
+
+	conn, err := db.Conn(ctx)
+	if err != nil {
+		return fmt.Errorf("migrations conn: %w", err)
+	}
+	defer func() {
+		_ = conn.Close()
+	}()
+
+	if _, err := conn.ExecContext(ctx, `SELECT pg_advisory_lock($1)`, lockKey); err != nil {
+		return fmt.Errorf("acquire migrations lock: %w", err)
+	}
+	defer func() {
+		_, _ = conn.ExecContext(context.Background(), `SELECT pg_advisory_unlock($1)`, lockKey)
+	}()
+
+	if _, err := conn.ExecContext(ctx, `
+        CREATE TABLE IF NOT EXISTS schema_migrations (
+            version    INT PRIMARY KEY,
+            name       TEXT NOT NULL,
+            applied_at TIMESTAMPTZ NOT NULL DEFAULT now()
+        )
+    `); err != nil {
+		return fmt.Errorf("create schema_migrations: %w", err)
+	}
+
+	applied, err := appliedVersions(ctx, conn)
 	if err != nil {
-		return fmt.Errorf("read migration: %w", err)
+		return err
 	}
 
-	parts := strings.Split(string(script), ";")
-	for _, part := range parts {
-		stmt := strings.TrimSpace(part)
-		if stmt == "" {
+	for _, m := range all {
+		if _, ok := applied[m.Version]; ok {
 			continue
 		}
-		if _, err := db.ExecContext(ctx, stmt); err != nil {
-			return fmt.Errorf("exec migration statement %q: %w", stmt, err)
+		if err := apply(ctx, conn, m); err != nil {
+			return err
 		}
 	}
 
 	return nil
 }
+
+func apply(ctx context.Context, conn *sql.Conn, m Migration) error {
+	tx, err := conn.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("migration %03d begin tx: %w", m.Version, err)
+	}
+	defer func() {
+		_ = tx.Rollback()
+	}()
+
+	if _, err := tx.ExecContext(ctx, m.SQL); err != nil {
+		return fmt.Errorf("migration %03d_%s: %w", m.Version, m.Name, err)
+	}
+	if _, err := tx.ExecContext(ctx, `
+        INSERT INTO schema_migrations (version, name) VALUES ($1, $2)
+    `, m.Version, m.Name); err != nil {
+		return fmt.Errorf("record migration %03d: %w", m.Version, err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("migration %03d commit: %w", m.Version, err)
+	}
+	return nil
+}
+
+// Status reports every embedded migration and whether it has been applied,
+// without changing the database.
+func Status(ctx context.Context, db *sql.DB) ([]MigrationStatus, error) {
+	all, err := Load()
+	if err != nil {
+		return nil, err
+	}
+
+	var table sql.NullString
+	if err := db.QueryRowContext(ctx, `SELECT to_regclass('schema_migrations')::text`).Scan(&table); err != nil {
+		return nil, fmt.Errorf("look up schema_migrations: %w", err)
+	}
+
+	applied := map[int]time.Time{}
+	if table.Valid {
+		if applied, err = appliedVersions(ctx, db); err != nil {
+			return nil, err
+		}
+	}
+
+	res := make([]MigrationStatus, 0, len(all))
+	for _, m := range all {
+		s := MigrationStatus{Migration: m}
+		if at, ok := applied[m.Version]; ok {
+			s.AppliedAt = &at
+		}
+		res = append(res, s)
+	}
+	return res, nil
+}
+
+type queryer interface {
+	QueryContext(ctx context.Context, query string, args ...any) (*sql.Rows, error)
+}
+
+func appliedVersions(ctx context.Context, q queryer) (map[int]time.Time, error) {
+	rows, err := q.QueryContext(ctx, `SELECT version, applied_at FROM schema_migrations`)
+	if err != nil {
+		return nil, fmt.Errorf("list applied migrations: %w", err)
+	}
+	defer func() {
+		_ = rows.Close()
+	}()
+
+	res := make(map[int]time.Time)
+	for rows.Next() {
+		var version int
+		var at time.Time
+		if err := rows.Scan(&version, &at); err != nil {
+			return nil, fmt.Errorf("scan applied migration: %w", err)
+		}
+		res[version] = at
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("iterate applied migrations: %w", err)
+	}
+	return res, nil
+}
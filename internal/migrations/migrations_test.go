@@ -0,0 +1,59 @@
+package migrations
+
+import (
+	"strings"
+	"testing"
+	"testing/fstest"
+)
+
+func TestLoadOrdersByVersion(t *testing.T) {
+	fsys := fstest.MapFS{
+		"010_later.sql":  {Data: []byte("SELECT 10;")},
+		"002_second.sql": {Data: []byte("SELECT 2;")},
+		"001_init.sql":   {Data: []byte("SELECT 1;")},
+	}
+
+	got, err := LoadFS(fsys)
+	if err != nil {
+		t.Fatalf("LoadFS returned error: %v", err)
+	}
+
+	var names []string
+	for _, m := range got {
+		names = append(names, m.Name)
+	}
+	if strings.Join(names, ",") != "init,second,later" {
+		t.Fatalf("unexpected order: %v", names)
+	}
+	if got[2].Version != 10 || got[2].SQL != "SELECT 10;" {
+		t.Fatalf("unexpected migration: %+v", got[2])
+	}
+}
+
+func TestLoadRejectsBadFiles(t *testing.T) {
+	cases := map[string]fstest.MapFS{
+		"unnumbered": {"init.sql": {Data: []byte("SELECT 1;")}},
+		"duplicate": {
+			"001_init.sql":  {Data: []byte("SELECT 1;")},
+			"001_other.sql": {Data: []byte("SELECT 1;")},
+		},
+	}
+
+	for name, fsys := range cases {
+		if _, err := LoadFS(fsys); err == nil {
+			t.Fatalf("%s: expected error", name)
+		}
+	}
+}
+
+func TestEmbeddedMigrationsAreContiguous(t *testing.T) {
+	all, err := Load()
+	if err != nil {
+		t.Fatalf("Load returned error: %v", err)
+	}
+	for i, m := range all {
+		if m.Version != i+1 {
+			t.Fatalf("expected version %d, got %03d_%s", i+1, m.Version, m.Name)
+		}
+	}
+}
@@ -0,0 +1,129 @@
+package notify
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"time"
+
+	"pr-reviewer-service/internal/domain"
+)
+
+type slackText struct {
+	Type string `json:"type"`
+	Text string `json:"text"`
+}
+
+type slackBlock struct {
+	Type     string      `json:"type"`
+	Text     *slackText  `json:"text,omitempty"`
+	Elements []slackText `json:"elements,omitempty"`
+}
+
+type slackMessage struct {
+	Text   string       `json:"text"`
+	Blocks []slackBlock `json:"blocks"`
+}
+
+// SlackNotifier posts assignment notices to Slack incoming webhooks from a
+// background worker, so a Slack outage never reaches the caller.
+type SlackNotifier struct {
+	Client *http.Client
+	queue  chan domain.ReviewerNotice
+}
+
+func NewSlackNotifier(queueSize int) *SlackNotifier {
+	return &SlackNotifier{
+		Client: &http.Client{Timeout: 5 * time.Second},
+		queue:  make(chan domain.ReviewerNotice, queueSize),
+	}
+}
+
+// NotifyReviewer queues the notice, dropping it when the queue is full.
+func (n *SlackNotifier) NotifyReviewer(ctx context.Context, notice domain.ReviewerNotice) {
+	select {
+	case n.queue <- notice:
+	default:
+		log.Printf("slack queue full, dropping notice for %s on %s", notice.Reviewer.ID, notice.PullRequestID)
+	}
+}
+
+// Run delivers queued notices until ctx is cancelled.
+func (n *SlackNotifier) Run(ctx context.Context) {
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case notice := <-n.queue:
+			if err := n.Send(ctx, notice); err != nil && ctx.Err() == nil {
+				log.Printf("slack notification for %s failed: %v", notice.PullRequestID, err)
+			}
+		}
+	}
+}
+
+func (n *SlackNotifier) Send(ctx context.Context, notice domain.ReviewerNotice) error {
+	body, err := json.Marshal(buildMessage(notice))
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, notice.WebhookURL, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := n.Client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		_ = resp.Body.Close()
+	}()
+	_, _ = io.Copy(io.Discard, resp.Body)
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return fmt.Errorf("slack responded with status %d", resp.StatusCode)
+	}
+	return nil
+}
+
+func buildMessage(notice domain.ReviewerNotice) slackMessage {
+	headline := fmt.Sprintf("%s, you were assigned to review *%s* (`%s`) by %s",
+		mention(notice.Reviewer), notice.PullRequestName, notice.PullRequestID, notice.AuthorID)
+	if notice.Replaced != "" {
+		headline += fmt.Sprintf(", replacing %s", notice.Replaced)
+	}
+
+	return slackMessage{
+		Text: fmt.Sprintf("Review requested: %s", notice.PullRequestName),
+		Blocks: []slackBlock{
+			{
+				Type: "section",
+				Text: &slackText{Type: "mrkdwn", Text: headline},
+			},
+			{
+				Type: "context",
+				Elements: []slackText{
+					{Type: "mrkdwn", Text: fmt.Sprintf("Reason: %s", notice.Reason)},
+				},
+			},
+		},
+	}
+}
+
+// mention falls back to the plain username for users without a Slack handle.
+func mention(u domain.User) string {
+	if u.SlackHandle != "" {
+		return "<@" + u.SlackHandle + ">"
+	}
+	if u.Username != "" {
+		return u.Username
+	}
+	return string(u.ID)
+}
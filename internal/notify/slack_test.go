@@ -0,0 +1,87 @@
+package notify
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"pr-reviewer-service/internal/domain"
+)
+
+func TestSlackNotifier_PostsBlockKitMessage(t *testing.T) {
+	received := make(chan slackMessage, 1)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var msg slackMessage
+		if err := json.NewDecoder(r.Body).Decode(&msg); err != nil {
+			t.Errorf("decode slack message: %v", err)
+		}
+		received <- msg
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer srv.Close()
+
+	n := NewSlackNotifier(1)
+	n.Client = srv.Client()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go n.Run(ctx)
+
+	n.NotifyReviewer(ctx, domain.ReviewerNotice{
+		WebhookURL:      srv.URL,
+		PullRequestID:   "pr-1",
+		PullRequestName: "Add search",
+		AuthorID:        "u1",
+		Reviewer:        domain.User{ID: "u2", Username: "bob", SlackHandle: "U024BE7LH"},
+		Reason:          domain.AssignmentReasonCreate,
+	})
+
+	select {
+	case msg := <-received:
+		if len(msg.Blocks) == 0 || msg.Blocks[0].Text == nil {
+			t.Fatalf("expected a section block, got %+v", msg)
+		}
+		if !strings.Contains(msg.Blocks[0].Text.Text, "<@U024BE7LH>") {
+			t.Fatalf("expected reviewer mention, got %q", msg.Blocks[0].Text.Text)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("slack message was not delivered")
+	}
+}
+
+func TestSlackNotifier_DropsWhenQueueFull(t *testing.T) {
+	n := NewSlackNotifier(1)
+	notice := domain.ReviewerNotice{WebhookURL: "http://127.0.0.1:0", PullRequestID: "pr-1"}
+
+	done := make(chan struct{})
+	go func() {
+		n.NotifyReviewer(context.Background(), notice)
+		n.NotifyReviewer(context.Background(), notice)
+		close(done)
+	}()
+
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("NotifyReviewer blocked on a full queue")
+	}
+}
+
+func TestSlackNotifier_SendReportsOutage(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer srv.Close()
+
+	n := NewSlackNotifier(1)
+	n.Client = srv.Client()
+
+	err := n.Send(context.Background(), domain.ReviewerNotice{WebhookURL: srv.URL, PullRequestID: "pr-1"})
+	if err == nil {
+		t.Fatal("expected an error for a failing Slack endpoint")
+	}
+}
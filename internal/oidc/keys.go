@@ -0,0 +1,121 @@
+package oidc
+
+import (
+	"context"
+	"crypto"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	// refreshInterval bounds how long keys from a URL are trusted.
+	refreshInterval = time.Hour
+	// minRefetch rate-limits refetches triggered by unknown key IDs.
+	minRefetch   = time.Minute
+	maxJWKSBytes = 1 << 20
+)
+
+// KeySource loads a JWKS from a file or an http(s) URL. Remote sets are kept
+// for refreshInterval and refetched early when a token names an unknown key,
+// which is how providers roll keys.
+//
+// With a cache file, every successful fetch is written there and the file is
+// used when the URL cannot be reached, so a cached set also serves offline
+// runs and tests.
+type KeySource struct {
+	location  string
+	cacheFile string
+	client    *http.Client
+
+	mu        sync.Mutex
+	keys      KeySet
+	fetchedAt time.Time
+}
+
+func NewKeySource(location, cacheFile string) *KeySource {
+	return &KeySource{
+		location:  location,
+		cacheFile: cacheFile,
+		client:    &http.Client{Timeout: 10 * time.Second},
+	}
+}
+
+func (s *KeySource) remote() bool {
+	return strings.HasPrefix(s.location, "https://") || strings.HasPrefix(s.location, "http://")
+}
+
+// Key returns the public key with the given ID.
+func (s *KeySource) Key(ctx context.Context, kid string) (crypto.PublicKey, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	stale := s.keys == nil || (s.remote() && time.Since(s.fetchedAt) > refreshInterval)
+	_, known := s.keys[kid]
+	if stale || (!known && time.Since(s.fetchedAt) > minRefetch) {
+		if err := s.load(ctx); err != nil && s.keys == nil {
+			return nil, err
+		}
+	}
+
+	key, ok := s.keys[kid]
+	if !ok {
+		return nil, fmt.Errorf("%w: unknown key id %q", ErrInvalidToken, kid)
+	}
+	return key, nil
+}
+
+func (s *KeySource) load(ctx context.Context) error {
+	s.fetchedAt = time.Now()
+
+	var data []byte
+	var err error
+	if s.remote() {
+		data, err = s.fetch(ctx)
+	} else {
+		data, err = os.ReadFile(s.location)
+	}
+	if err == nil {
+		var keys KeySet
+		if keys, err = ParseJWKS(data); err == nil {
+			s.keys = keys
+			if s.remote() && s.cacheFile != "" {
+				_ = os.WriteFile(s.cacheFile, data, 0o600)
+			}
+			return nil
+		}
+	}
+
+	if s.keys == nil && s.cacheFile != "" {
+		if cached, cacheErr := os.ReadFile(s.cacheFile); cacheErr == nil {
+			if keys, cacheErr := ParseJWKS(cached); cacheErr == nil {
+				s.keys = keys
+				return nil
+			}
+		}
+	}
+	return fmt.Errorf("load jwks from %s: %w", s.location, err)
+}
+
+func (s *KeySource) fetch(ctx context.Context) ([]byte, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, s.location, nil)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		_ = resp.Body.Close()
+	}()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("unexpected status %d", resp.StatusCode)
+	}
+	return io.ReadAll(io.LimitReader(resp.Body, maxJWKSBytes))
+}
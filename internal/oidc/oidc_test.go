@@ -0,0 +1,233 @@
+package oidc
+
+import (
+	"context"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"pr-reviewer-service/internal/domain"
+)
+
+var b64 = base64.RawURLEncoding
+
+func rsaJWK(t *testing.T, kid string) (*rsa.PrivateKey, map[string]string) {
+	t.Helper()
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatalf("generate rsa key: %v", err)
+	}
+	return key, map[string]string{
+		"kid": kid,
+		"kty": "RSA",
+		"use": "sig",
+		"n":   b64.EncodeToString(key.N.Bytes()),
+		"e":   b64.EncodeToString(big.NewInt(int64(key.E)).Bytes()),
+	}
+}
+
+func ecJWK(t *testing.T, kid string) (*ecdsa.PrivateKey, map[string]string) {
+	t.Helper()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatalf("generate ec key: %v", err)
+	}
+	return key, map[string]string{
+		"kid": kid,
+		"kty": "EC",
+		"crv": "P-256",
+		"x":   b64.EncodeToString(key.X.FillBytes(make([]byte, 32))),
+		"y":   b64.EncodeToString(key.Y.FillBytes(make([]byte, 32))),
+	}
+}
+
+func jwks(t *testing.T, keys ...map[string]string) []byte {
+	t.Helper()
+	data, err := json.Marshal(map[string]any{"keys": keys})
+	if err != nil {
+		t.Fatalf("marshal jwks: %v", err)
+	}
+	return data
+}
+
+func sign(t *testing.T, alg, kid string, key crypto.Signer, claims map[string]any) string {
+	t.Helper()
+	header, _ := json.Marshal(map[string]string{"alg": alg, "kid": kid, "typ": "JWT"})
+	payload, _ := json.Marshal(claims)
+	signed := b64.EncodeToString(header) + "." + b64.EncodeToString(payload)
+	sum := sha256.Sum256([]byte(signed))
+
+	var sig []byte
+	switch k := key.(type) {
+	case *rsa.PrivateKey:
+		var err error
+		if sig, err = rsa.SignPKCS1v15(rand.Reader, k, crypto.SHA256, sum[:]); err != nil {
+			t.Fatalf("sign: %v", err)
+		}
+	case *ecdsa.PrivateKey:
+		r, s, err := ecdsa.Sign(rand.Reader, k, sum[:])
+		if err != nil {
+			t.Fatalf("sign: %v", err)
+		}
+		sig = append(r.FillBytes(make([]byte, 32)), s.FillBytes(make([]byte, 32))...)
+	}
+	return signed + "." + b64.EncodeToString(sig)
+}
+
+func TestVerifier(t *testing.T) {
+	ctx := context.Background()
+	now := time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC)
+
+	rsaKey, rsaJWK := rsaJWK(t, "rsa-1")
+	ecKey, ecJWK := ecJWK(t, "ec-1")
+	keys, err := ParseJWKS(jwks(t, rsaJWK, ecJWK))
+	if err != nil {
+		t.Fatalf("ParseJWKS returned error: %v", err)
+	}
+
+	v := &Verifier{
+		Keys:     staticKeys(keys),
+		Issuer:   "https://sso.example.com",
+		Audience: "pr-reviewer",
+		Now:      func() time.Time { return now },
+	}
+	claims := func(extra map[string]any) map[string]any {
+		c := map[string]any{
+			"iss": "https://sso.example.com",
+			"aud": []string{"other", "pr-reviewer"},
+			"sub": "u1",
+			"exp": now.Add(time.Hour).Unix(),
+		}
+		for k, val := range extra {
+			c[k] = val
+		}
+		return c
+	}
+
+	id, err := v.Verify(ctx, sign(t, "RS256", "rsa-1", rsaKey, claims(map[string]any{"role": "team_lead"})))
+	if err != nil {
+		t.Fatalf("Verify returned error: %v", err)
+	}
+	if id.User != "u1" || id.Role != domain.RoleTeamLead {
+		t.Fatalf("unexpected identity: %+v", id)
+	}
+
+	id, err = v.Verify(ctx, sign(t, "ES256", "ec-1", ecKey, claims(nil)))
+	if err != nil {
+		t.Fatalf("Verify ES256 returned error: %v", err)
+	}
+	if id.Role != domain.RoleReadOnly {
+		t.Fatalf("expected default role read_only, got %q", id.Role)
+	}
+
+	valid := sign(t, "RS256", "rsa-1", rsaKey, claims(nil))
+	parts := strings.Split(valid, ".")
+	unsigned := b64.EncodeToString([]byte(`{"alg":"none","kid":"rsa-1"}`)) + "." + parts[1] + "."
+	tampered := parts[0] + "." + b64.EncodeToString([]byte(`{"sub":"admin","exp":9999999999}`)) + "." + parts[2]
+
+	for name, token := range map[string]string{
+		"expired":      sign(t, "RS256", "rsa-1", rsaKey, claims(map[string]any{"exp": now.Add(-time.Minute).Unix()})),
+		"not yet":      sign(t, "RS256", "rsa-1", rsaKey, claims(map[string]any{"nbf": now.Add(time.Minute).Unix()})),
+		"issuer":       sign(t, "RS256", "rsa-1", rsaKey, claims(map[string]any{"iss": "https://evil.example.com"})),
+		"audience":     sign(t, "RS256", "rsa-1", rsaKey, claims(map[string]any{"aud": "other"})),
+		"no subject":   sign(t, "RS256", "rsa-1", rsaKey, claims(map[string]any{"sub": ""})),
+		"bad role":     sign(t, "RS256", "rsa-1", rsaKey, claims(map[string]any{"role": "root"})),
+		"unknown kid":  sign(t, "RS256", "rsa-2", rsaKey, claims(nil)),
+		"wrong key":    sign(t, "ES256", "rsa-1", ecKey, claims(nil)),
+		"alg none":     unsigned,
+		"tampered":     tampered,
+		"not a jwt":    "prk_abc",
+		"bad encoding": "a.b.c",
+	} {
+		if _, err := v.Verify(ctx, token); !errors.Is(err, ErrInvalidToken) {
+			t.Errorf("%s: expected ErrInvalidToken, got %v", name, err)
+		}
+	}
+}
+
+type staticKeys KeySet
+
+func (k staticKeys) Key(ctx context.Context, kid string) (crypto.PublicKey, error) {
+	key, ok := k[kid]
+	if !ok {
+		return nil, ErrInvalidToken
+	}
+	return key, nil
+}
+
+func TestParseJWKSSkipsOtherKeys(t *testing.T) {
+	_, sig := rsaJWK(t, "sig")
+	_, enc := rsaJWK(t, "enc")
+	enc["use"] = "enc"
+	oct := map[string]string{"kid": "oct", "kty": "oct"}
+
+	keys, err := ParseJWKS(jwks(t, sig, enc, oct))
+	if err != nil {
+		t.Fatalf("ParseJWKS returned error: %v", err)
+	}
+	if len(keys) != 1 || keys["sig"] == nil {
+		t.Fatalf("expected only the signing key, got %v", keys)
+	}
+
+	if _, err := ParseJWKS(jwks(t, enc)); err == nil {
+		t.Fatalf("expected an error for a set without signing keys")
+	}
+}
+
+func TestKeySourceFallsBackToCache(t *testing.T) {
+	ctx := context.Background()
+	_, jwk := rsaJWK(t, "k1")
+	doc := jwks(t, jwk)
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write(doc)
+	}))
+	cache := filepath.Join(t.TempDir(), "jwks.json")
+
+	if _, err := NewKeySource(srv.URL, cache).Key(ctx, "k1"); err != nil {
+		t.Fatalf("Key returned error: %v", err)
+	}
+	cached, err := os.ReadFile(cache)
+	if err != nil || string(cached) != string(doc) {
+		t.Fatalf("expected the fetched set in the cache file, got %q (%v)", cached, err)
+	}
+
+	srv.Close()
+	offline := NewKeySource(srv.URL, cache)
+	if _, err := offline.Key(ctx, "k1"); err != nil {
+		t.Fatalf("Key from cache returned error: %v", err)
+	}
+	if _, err := offline.Key(ctx, "k2"); !errors.Is(err, ErrInvalidToken) {
+		t.Fatalf("expected ErrInvalidToken for an unknown kid, got %v", err)
+	}
+
+	if _, err := NewKeySource(srv.URL, "").Key(ctx, "k1"); err == nil {
+		t.Fatalf("expected an error without a server or cache")
+	}
+}
+
+func TestKeySourceReadsFile(t *testing.T) {
+	_, jwk := rsaJWK(t, "k1")
+	path := filepath.Join(t.TempDir(), "jwks.json")
+	if err := os.WriteFile(path, jwks(t, jwk), 0o600); err != nil {
+		t.Fatalf("write jwks: %v", err)
+	}
+
+	if _, err := NewKeySource(path, "").Key(context.Background(), "k1"); err != nil {
+		t.Fatalf("Key returned error: %v", err)
+	}
+}
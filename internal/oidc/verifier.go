@@ -0,0 +1,227 @@
+package oidc
+
+import (
+	"context"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/sha512"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"strings"
+	"time"
+
+	"pr-reviewer-service/internal/domain"
+)
+
+var ErrInvalidToken = errors.New("invalid token")
+
+// Keys looks up the public key a token was signed with.
+type Keys interface {
+	Key(ctx context.Context, kid string) (crypto.PublicKey, error)
+}
+
+// Identity is the caller a verified token names.
+type Identity struct {
+	User domain.UserID
+	Role domain.Role
+}
+
+// Verifier checks signed ID or access tokens. Only asymmetric algorithms are
+// accepted: a shared secret would let anyone holding the JWKS mint tokens.
+type Verifier struct {
+	Keys     Keys
+	Issuer   string
+	Audience string
+	// UserClaim names the claim holding the user ID; "sub" when empty.
+	UserClaim string
+	// RoleClaim names the claim holding the role; "role" when empty. Tokens
+	// without it get DefaultRole, or read_only when that is empty too.
+	RoleClaim   string
+	DefaultRole domain.Role
+	// Leeway tolerates clock skew in exp and nbf checks.
+	Leeway time.Duration
+	Now    func() time.Time
+}
+
+type header struct {
+	Alg string `json:"alg"`
+	Kid string `json:"kid"`
+}
+
+func (v *Verifier) Verify(ctx context.Context, token string) (Identity, error) {
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 {
+		return Identity{}, fmt.Errorf("%w: malformed", ErrInvalidToken)
+	}
+
+	var h header
+	if err := decodeSegment(parts[0], &h); err != nil {
+		return Identity{}, err
+	}
+	hash, err := algorithmHash(h.Alg)
+	if err != nil {
+		return Identity{}, err
+	}
+	key, err := v.Keys.Key(ctx, h.Kid)
+	if err != nil {
+		return Identity{}, err
+	}
+	sig, err := base64.RawURLEncoding.DecodeString(parts[2])
+	if err != nil {
+		return Identity{}, fmt.Errorf("%w: malformed signature", ErrInvalidToken)
+	}
+	if err := verifySignature(h.Alg, hash, key, []byte(parts[0]+"."+parts[1]), sig); err != nil {
+		return Identity{}, err
+	}
+
+	var claims map[string]any
+	if err := decodeSegment(parts[1], &claims); err != nil {
+		return Identity{}, err
+	}
+	if err := v.checkClaims(claims); err != nil {
+		return Identity{}, err
+	}
+	return v.identity(claims)
+}
+
+func decodeSegment(s string, dst any) error {
+	b, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil {
+		return fmt.Errorf("%w: malformed segment", ErrInvalidToken)
+	}
+	if err := json.Unmarshal(b, dst); err != nil {
+		return fmt.Errorf("%w: malformed segment", ErrInvalidToken)
+	}
+	return nil
+}
+
+func algorithmHash(alg string) (crypto.Hash, error) {
+	switch alg {
+	case "RS256", "ES256":
+		return crypto.SHA256, nil
+	case "RS384", "ES384":
+		return crypto.SHA384, nil
+	case "RS512", "ES512":
+		return crypto.SHA512, nil
+	default:
+		return 0, fmt.Errorf("%w: unsupported algorithm %q", ErrInvalidToken, alg)
+	}
+}
+
+func digest(hash crypto.Hash, data []byte) []byte {
+	switch hash {
+	case crypto.SHA384:
+		sum := sha512.Sum384(data)
+		return sum[:]
+	case crypto.SHA512:
+		sum := sha512.Sum512(data)
+		return sum[:]
+	default:
+		sum := sha256.Sum256(data)
+		return sum[:]
+	}
+}
+
+func verifySignature(alg string, hash crypto.Hash, key crypto.PublicKey, signed, sig []byte) error {
+	sum := digest(hash, signed)
+	switch k := key.(type) {
+	case *rsa.PublicKey:
+		if alg[:2] == "RS" && rsa.VerifyPKCS1v15(k, hash, sum, sig) == nil {
+			return nil
+		}
+	case *ecdsa.PublicKey:
+		// JWS carries ECDSA signatures as fixed-size r || s.
+		size := (k.Curve.Params().BitSize + 7) / 8
+		if alg[:2] == "ES" && len(sig) == 2*size {
+			r := new(big.Int).SetBytes(sig[:size])
+			s := new(big.Int).SetBytes(sig[size:])
+			if ecdsa.Verify(k, sum, r, s) {
+				return nil
+			}
+		}
+	}
+	return fmt.Errorf("%w: bad signature", ErrInvalidToken)
+}
+
+func (v *Verifier) checkClaims(claims map[string]any) error {
+	now := time.Now()
+	if v.Now != nil {
+		now = v.Now()
+	}
+
+	exp, ok := numericDate(claims["exp"])
+	if !ok {
+		return fmt.Errorf("%w: missing exp", ErrInvalidToken)
+	}
+	if now.After(exp.Add(v.Leeway)) {
+		return fmt.Errorf("%w: expired", ErrInvalidToken)
+	}
+	if nbf, ok := numericDate(claims["nbf"]); ok && now.Add(v.Leeway).Before(nbf) {
+		return fmt.Errorf("%w: not valid yet", ErrInvalidToken)
+	}
+
+	if v.Issuer != "" {
+		if iss, _ := claims["iss"].(string); iss != v.Issuer {
+			return fmt.Errorf("%w: unexpected issuer", ErrInvalidToken)
+		}
+	}
+	if v.Audience != "" && !hasAudience(claims["aud"], v.Audience) {
+		return fmt.Errorf("%w: unexpected audience", ErrInvalidToken)
+	}
+	return nil
+}
+
+func numericDate(v any) (time.Time, bool) {
+	f, ok := v.(float64)
+	if !ok {
+		return time.Time{}, false
+	}
+	return time.Unix(int64(f), 0), true
+}
+
+// hasAudience accepts both forms RFC 7519 allows: a string or an array.
+func hasAudience(aud any, want string) bool {
+	switch a := aud.(type) {
+	case string:
+		return a == want
+	case []any:
+		for _, s := range a {
+			if s == want {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func (v *Verifier) identity(claims map[string]any) (Identity, error) {
+	userClaim := v.UserClaim
+	if userClaim == "" {
+		userClaim = "sub"
+	}
+	user, _ := claims[userClaim].(string)
+	if user == "" {
+		return Identity{}, fmt.Errorf("%w: missing %s claim", ErrInvalidToken, userClaim)
+	}
+
+	roleClaim := v.RoleClaim
+	if roleClaim == "" {
+		roleClaim = "role"
+	}
+	role := v.DefaultRole
+	if role == "" {
+		role = domain.RoleReadOnly
+	}
+	if s, ok := claims[roleClaim].(string); ok {
+		role = domain.Role(s)
+	}
+	if !role.Valid() {
+		return Identity{}, fmt.Errorf("%w: unknown role %q", ErrInvalidToken, role)
+	}
+	return Identity{User: domain.UserID(user), Role: role}, nil
+}
@@ -0,0 +1,77 @@
+package memory
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"pr-reviewer-service/internal/domain"
+)
+
+type APIKeyRepo struct {
+	store *Store
+}
+
+func NewAPIKeyRepo(store *Store) *APIKeyRepo {
+	return &APIKeyRepo{store: store}
+}
+
+func (r *APIKeyRepo) Create(ctx context.Context, key domain.APIKey) (domain.APIKey, error) {
+	err := r.store.write(func(d *data) error {
+		if key.TeamName != "" {
+			if _, ok := d.teams[key.TeamName]; !ok {
+				return fmt.Errorf("insert api key: team %s does not exist", key.TeamName)
+			}
+		}
+		for _, k := range d.apiKeys {
+			if k.Hash == key.Hash {
+				return fmt.Errorf("insert api key: hash already exists")
+			}
+		}
+		d.nextAPIKeyID++
+		key.ID = d.nextAPIKeyID
+		d.apiKeys = append(d.apiKeys, key)
+		return nil
+	})
+	if err != nil {
+		return domain.APIKey{}, err
+	}
+	return key, nil
+}
+
+func (r *APIKeyRepo) GetByHash(ctx context.Context, hash string) (domain.APIKey, error) {
+	var key domain.APIKey
+	var ok bool
+	r.store.read(func(d *data) {
+		for _, k := range d.apiKeys {
+			if k.Hash == hash && k.RevokedAt == nil {
+				key, ok = k, true
+				return
+			}
+		}
+	})
+	if !ok {
+		return domain.APIKey{}, domain.ErrNotFound
+	}
+	return key, nil
+}
+
+func (r *APIKeyRepo) List(ctx context.Context) ([]domain.APIKey, error) {
+	var res []domain.APIKey
+	r.store.read(func(d *data) {
+		res = append(res, d.apiKeys...)
+	})
+	return res, nil
+}
+
+func (r *APIKeyRepo) Revoke(ctx context.Context, id int64, at time.Time) error {
+	return r.store.write(func(d *data) error {
+		for i := range d.apiKeys {
+			if d.apiKeys[i].ID == id && d.apiKeys[i].RevokedAt == nil {
+				d.apiKeys[i].RevokedAt = &at
+				return nil
+			}
+		}
+		return domain.ErrNotFound
+	})
+}
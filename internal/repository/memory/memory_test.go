@@ -0,0 +1,58 @@
+package memory_test
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"testing"
+
+	"pr-reviewer-service/internal/domain"
+	"pr-reviewer-service/internal/repository/memory"
+	"pr-reviewer-service/internal/repository/repotest"
+)
+
+func TestContract(t *testing.T) {
+	repotest.Run(t, func(t *testing.T) repotest.Backend {
+		store := memory.NewStore()
+		return repotest.Backend{
+			Repos: domain.Repositories{
+				Teams: memory.NewTeamRepo(store),
+				Users: memory.NewUserRepo(store),
+				Prs:   memory.NewPullRequestRepo(store),
+			},
+			Tx:      memory.NewUnitOfWork(store),
+			APIKeys: memory.NewAPIKeyRepo(store),
+		}
+	})
+}
+
+func TestConcurrentWritersKeepEveryChange(t *testing.T) {
+	ctx := context.Background()
+	store := memory.NewStore()
+	teams := memory.NewTeamRepo(store)
+	uow := memory.NewUnitOfWork(store)
+
+	var wg sync.WaitGroup
+	for i := 0; i < 50; i++ {
+		wg.Add(2)
+		go func() {
+			defer wg.Done()
+			_ = uow.Do(ctx, func(ctx context.Context, repos domain.Repositories) error {
+				return repos.Teams.CreateTeam(ctx, domain.TeamName(fmt.Sprintf("tx-%d", i)))
+			})
+		}()
+		go func() {
+			defer wg.Done()
+			_ = teams.CreateTeam(ctx, domain.TeamName(fmt.Sprintf("direct-%d", i)))
+		}()
+	}
+	wg.Wait()
+
+	names, err := teams.ListTeams(ctx)
+	if err != nil {
+		t.Fatalf("ListTeams: %v", err)
+	}
+	if len(names) != 100 {
+		t.Fatalf("expected 100 teams, got %d", len(names))
+	}
+}
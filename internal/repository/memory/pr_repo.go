@@ -0,0 +1,340 @@
+package memory
+
+import (
+	"context"
+	"fmt"
+	"maps"
+	"slices"
+	"sort"
+	"time"
+
+	"pr-reviewer-service/internal/domain"
+)
+
+type PullRequestRepo struct {
+	store *Store
+}
+
+func NewPullRequestRepo(store *Store) *PullRequestRepo {
+	return &PullRequestRepo{store: store}
+}
+
+func (r *PullRequestRepo) Create(ctx context.Context, pr domain.PullRequest) error {
+	return r.store.write(func(d *data) error {
+		if _, ok := d.prs[pr.ID]; ok {
+			return domain.ErrPullRequestExists
+		}
+		if _, ok := d.users[pr.AuthorID]; !ok {
+			return fmt.Errorf("insert pull_request: author %s does not exist", pr.AuthorID)
+		}
+		if err := checkReviewers(d, pr, nil); err != nil {
+			return err
+		}
+
+		pr = clonePR(pr)
+		// Reviewer rows only carry states set through SetReviewState.
+		pr.ReviewStates = nil
+		slices.Sort(pr.AssignedReviewers)
+		d.prs[pr.ID] = pr
+		return nil
+	})
+}
+
+func (r *PullRequestRepo) AddReviewers(ctx context.Context, pr domain.PullRequest) error {
+	return r.store.write(func(d *data) error {
+		stored, ok := d.prs[pr.ID]
+		if !ok {
+			return fmt.Errorf("insert reviewers: pull request %s does not exist", pr.ID)
+		}
+		if err := checkReviewers(d, pr, stored.AssignedReviewers); err != nil {
+			return err
+		}
+
+		stored = clonePR(stored)
+		for _, id := range pr.AssignedReviewers {
+			stored.AssignedReviewers = append(stored.AssignedReviewers, id)
+			if team := pr.FallbackReviewers[id]; team != "" {
+				stored.FallbackReviewers = setKey(stored.FallbackReviewers, id, team)
+			}
+			if rule := pr.MatchedRules[id]; rule != "" {
+				stored.MatchedRules = setKey(stored.MatchedRules, id, rule)
+			}
+		}
+		slices.Sort(stored.AssignedReviewers)
+		d.prs[pr.ID] = stored
+		return nil
+	})
+}
+
+// checkReviewers rejects what the postgres schema would: unknown users or
+// fallback teams and reviewers already assigned.
+func checkReviewers(d *data, pr domain.PullRequest, assigned []domain.UserID) error {
+	seen := slices.Clone(assigned)
+	for _, id := range pr.AssignedReviewers {
+		if _, ok := d.users[id]; !ok {
+			return fmt.Errorf("insert reviewer %s: user does not exist", id)
+		}
+		if slices.Contains(seen, id) {
+			return fmt.Errorf("insert reviewer %s: already assigned", id)
+		}
+		seen = append(seen, id)
+		if team := pr.FallbackReviewers[id]; team != "" {
+			if _, ok := d.teams[team]; !ok {
+				return fmt.Errorf("insert reviewer %s: team %s does not exist", id, team)
+			}
+		}
+	}
+	return nil
+}
+
+func (r *PullRequestRepo) Exists(ctx context.Context, id domain.PullRequestID) (bool, error) {
+	var ok bool
+	r.store.read(func(d *data) {
+		_, ok = d.prs[id]
+	})
+	return ok, nil
+}
+
+func (r *PullRequestRepo) Get(ctx context.Context, id domain.PullRequestID) (domain.PullRequest, error) {
+	var pr domain.PullRequest
+	var ok bool
+	r.store.read(func(d *data) {
+		if pr, ok = d.prs[id]; ok {
+			pr = clonePR(pr)
+		}
+	})
+	if !ok {
+		return domain.PullRequest{}, domain.ErrNotFound
+	}
+	return pr, nil
+}
+
+// GetForUpdate needs no lock of its own: a unit of work already excludes
+// other writers.
+func (r *PullRequestRepo) GetForUpdate(ctx context.Context, id domain.PullRequestID) (domain.PullRequest, error) {
+	return r.Get(ctx, id)
+}
+
+func (r *PullRequestRepo) List(ctx context.Context) ([]domain.PullRequest, error) {
+	var res []domain.PullRequest
+	r.store.read(func(d *data) {
+		for _, pr := range d.prs {
+			res = append(res, clonePR(pr))
+		}
+	})
+	sort.Slice(res, func(i, j int) bool {
+		if !res[i].CreatedAt.Equal(res[j].CreatedAt) {
+			return res[i].CreatedAt.Before(res[j].CreatedAt)
+		}
+		return res[i].ID < res[j].ID
+	})
+	return res, nil
+}
+
+func (r *PullRequestRepo) SetReviewState(
+	ctx context.Context,
+	prID domain.PullRequestID,
+	reviewerID domain.UserID,
+	state domain.ReviewState,
+) error {
+	return r.update(prID, domain.ErrNotAssigned, func(d *data, pr *domain.PullRequest) error {
+		if !slices.Contains(pr.AssignedReviewers, reviewerID) {
+			return domain.ErrNotAssigned
+		}
+		if state == domain.ReviewStatePending {
+			delete(pr.ReviewStates, reviewerID)
+			return nil
+		}
+		pr.ReviewStates = setKey(pr.ReviewStates, reviewerID, state)
+		return nil
+	})
+}
+
+func (r *PullRequestRepo) MarkMerged(ctx context.Context, id domain.PullRequestID, mergedAt time.Time) error {
+	return r.update(id, nil, func(d *data, pr *domain.PullRequest) error {
+		pr.Status = domain.PRStatusMerged
+		pr.MergedAt = &mergedAt
+		return nil
+	})
+}
+
+func (r *PullRequestRepo) SetStatus(
+	ctx context.Context,
+	id domain.PullRequestID,
+	status domain.PRStatus,
+	closedAt *time.Time,
+) error {
+	return r.update(id, nil, func(d *data, pr *domain.PullRequest) error {
+		switch {
+		case status != domain.PRStatusClosed:
+			pr.ClosedFrom = ""
+		case pr.Status == domain.PRStatusDraft || pr.Status == domain.PRStatusOpen:
+			pr.ClosedFrom = pr.Status
+		}
+		pr.Status = status
+		pr.ClosedAt = closedAt
+		return nil
+	})
+}
+
+func (r *PullRequestRepo) ReplaceReviewer(
+	ctx context.Context,
+	prID domain.PullRequestID,
+	oldUserID, newUserID domain.UserID,
+	fallbackTeam domain.TeamName,
+) (bool, error) {
+	added := false
+	err := r.update(prID, domain.ErrNotAssigned, func(d *data, pr *domain.PullRequest) error {
+		i := slices.Index(pr.AssignedReviewers, oldUserID)
+		if i < 0 {
+			return domain.ErrNotAssigned
+		}
+		if _, ok := d.users[newUserID]; !ok {
+			return fmt.Errorf("insert new reviewer: user %s does not exist", newUserID)
+		}
+
+		delete(pr.FallbackReviewers, oldUserID)
+		delete(pr.MatchedRules, oldUserID)
+		delete(pr.ReviewStates, oldUserID)
+
+		if slices.Contains(pr.AssignedReviewers, newUserID) {
+			pr.AssignedReviewers = slices.Delete(pr.AssignedReviewers, i, i+1)
+			return nil
+		}
+		pr.AssignedReviewers[i] = newUserID
+		slices.Sort(pr.AssignedReviewers)
+		if fallbackTeam != "" {
+			pr.FallbackReviewers = setKey(pr.FallbackReviewers, newUserID, fallbackTeam)
+		}
+		added = true
+		return nil
+	})
+	return added, err
+}
+
+// update applies fn to a copy of the PR and stores it when fn succeeds.
+// missing is returned for unknown PRs; nil makes that a no-op like an UPDATE
+// matching no rows.
+func (r *PullRequestRepo) update(id domain.PullRequestID, missing error, fn func(d *data, pr *domain.PullRequest) error) error {
+	return r.store.write(func(d *data) error {
+		stored, ok := d.prs[id]
+		if !ok {
+			return missing
+		}
+		pr := clonePR(stored)
+		if err := fn(d, &pr); err != nil {
+			return err
+		}
+		d.prs[id] = pr
+		return nil
+	})
+}
+
+func (r *PullRequestRepo) ListByReviewer(ctx context.Context, reviewerID domain.UserID) ([]domain.PullRequestShort, error) {
+	var prs []domain.PullRequest
+	r.store.read(func(d *data) {
+		for _, pr := range d.prs {
+			if slices.Contains(pr.AssignedReviewers, reviewerID) {
+				prs = append(prs, pr)
+			}
+		}
+	})
+	sort.Slice(prs, func(i, j int) bool {
+		if !prs[i].CreatedAt.Equal(prs[j].CreatedAt) {
+			return prs[i].CreatedAt.After(prs[j].CreatedAt)
+		}
+		return prs[i].ID < prs[j].ID
+	})
+
+	var res []domain.PullRequestShort
+	for _, pr := range prs {
+		res = append(res, domain.PullRequestShort{
+			ID:       pr.ID,
+			Name:     pr.Name,
+			AuthorID: pr.AuthorID,
+			Status:   pr.Status,
+		})
+	}
+	return res, nil
+}
+
+func (r *PullRequestRepo) StatsAssignmentsByUser(ctx context.Context) (map[domain.UserID]int, error) {
+	return r.countAssignments(func(domain.PullRequest) bool { return true }), nil
+}
+
+func (r *PullRequestRepo) OpenAssignmentsByUser(ctx context.Context) (map[domain.UserID]int, error) {
+	return r.countAssignments(func(pr domain.PullRequest) bool {
+		return pr.Status == domain.PRStatusOpen
+	}), nil
+}
+
+func (r *PullRequestRepo) OpenByTeam(ctx context.Context) (map[domain.TeamName]int, error) {
+	res := make(map[domain.TeamName]int)
+	r.store.read(func(d *data) {
+		for _, pr := range d.prs {
+			if pr.Status == domain.PRStatusOpen {
+				res[d.users[pr.AuthorID].TeamName]++
+			}
+		}
+	})
+	return res, nil
+}
+
+func (r *PullRequestRepo) countAssignments(match func(pr domain.PullRequest) bool) map[domain.UserID]int {
+	res := make(map[domain.UserID]int)
+	r.store.read(func(d *data) {
+		for _, pr := range d.prs {
+			if !match(pr) {
+				continue
+			}
+			for _, id := range pr.AssignedReviewers {
+				res[id]++
+			}
+		}
+	})
+	return res
+}
+
+func (r *PullRequestRepo) AppendAssignmentEvents(ctx context.Context, events []domain.AssignmentEvent) error {
+	return r.store.write(func(d *data) error {
+		for _, e := range events {
+			if _, ok := d.prs[e.PullRequestID]; !ok {
+				return fmt.Errorf("insert assignment event for %s: pull request %s does not exist", e.UserID, e.PullRequestID)
+			}
+		}
+		for _, e := range events {
+			d.nextEventID++
+			e.ID = d.nextEventID
+			d.events = append(d.events, e)
+		}
+		return nil
+	})
+}
+
+func (r *PullRequestRepo) ListAssignmentEvents(ctx context.Context, prID domain.PullRequestID) ([]domain.AssignmentEvent, error) {
+	var res []domain.AssignmentEvent
+	r.store.read(func(d *data) {
+		for _, e := range d.events {
+			if e.PullRequestID == prID {
+				res = append(res, e)
+			}
+		}
+	})
+	return res, nil
+}
+
+func clonePR(pr domain.PullRequest) domain.PullRequest {
+	pr.AssignedReviewers = slices.Clone(pr.AssignedReviewers)
+	pr.FallbackReviewers = maps.Clone(pr.FallbackReviewers)
+	pr.MatchedRules = maps.Clone(pr.MatchedRules)
+	pr.ReviewStates = maps.Clone(pr.ReviewStates)
+	return pr
+}
+
+func setKey[K comparable, V any](m map[K]V, k K, v V) map[K]V {
+	if m == nil {
+		m = make(map[K]V)
+	}
+	m[k] = v
+	return m
+}
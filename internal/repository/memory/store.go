@@ -0,0 +1,127 @@
+// Package memory keeps all data in process memory. It implements the same
+// repository contracts as the postgres package and is meant for demos, local
+// development and tests; nothing survives a restart.
+package memory
+
+import (
+	"context"
+	"maps"
+	"slices"
+	"sync"
+
+	"pr-reviewer-service/internal/domain"
+)
+
+type data struct {
+	teams      map[domain.TeamName]struct{}
+	settings   map[domain.TeamName]domain.TeamSettings
+	codeowners map[domain.TeamName]domain.Codeowners
+
+	users          map[domain.UserID]domain.User
+	unavailability []domain.Unavailability
+	nextPeriodID   int64
+
+	statusChanges      []domain.UserStatusChange
+	nextStatusChangeID int64
+
+	prs         map[domain.PullRequestID]domain.PullRequest
+	events      []domain.AssignmentEvent
+	nextEventID int64
+
+	apiKeys      []domain.APIKey
+	nextAPIKeyID int64
+}
+
+func (d *data) clone() *data {
+	c := &data{
+		teams:              maps.Clone(d.teams),
+		settings:           make(map[domain.TeamName]domain.TeamSettings, len(d.settings)),
+		codeowners:         maps.Clone(d.codeowners),
+		users:              maps.Clone(d.users),
+		unavailability:     slices.Clone(d.unavailability),
+		nextPeriodID:       d.nextPeriodID,
+		statusChanges:      slices.Clone(d.statusChanges),
+		nextStatusChangeID: d.nextStatusChangeID,
+		prs:                make(map[domain.PullRequestID]domain.PullRequest, len(d.prs)),
+		events:             slices.Clone(d.events),
+		nextEventID:        d.nextEventID,
+		apiKeys:            slices.Clone(d.apiKeys),
+		nextAPIKeyID:       d.nextAPIKeyID,
+	}
+	for name, s := range d.settings {
+		c.settings[name] = cloneSettings(s)
+	}
+	for id, pr := range d.prs {
+		c.prs[id] = clonePR(pr)
+	}
+	return c
+}
+
+// Store holds the data shared by the repositories created from it.
+type Store struct {
+	// writeMu serialises writers, including whole units of work, so a
+	// committed unit of work never overwrites a concurrent change.
+	writeMu sync.Mutex
+	mu      sync.RWMutex
+	data    *data
+}
+
+func NewStore() *Store {
+	return &Store{data: &data{
+		teams:      make(map[domain.TeamName]struct{}),
+		settings:   make(map[domain.TeamName]domain.TeamSettings),
+		codeowners: make(map[domain.TeamName]domain.Codeowners),
+		users:      make(map[domain.UserID]domain.User),
+		prs:        make(map[domain.PullRequestID]domain.PullRequest),
+	}}
+}
+
+func (s *Store) read(fn func(d *data)) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	fn(s.data)
+}
+
+// write runs fn exclusively. fn must check everything before its first
+// change: there is no rollback outside a unit of work.
+func (s *Store) write(fn func(d *data) error) error {
+	s.writeMu.Lock()
+	defer s.writeMu.Unlock()
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return fn(s.data)
+}
+
+type UnitOfWork struct {
+	store *Store
+}
+
+func NewUnitOfWork(store *Store) *UnitOfWork {
+	return &UnitOfWork{store: store}
+}
+
+// Do runs fn against a private copy of the store and publishes the copy only
+// when fn succeeds. Readers keep seeing the previous state until then; other
+// writers wait.
+func (u *UnitOfWork) Do(ctx context.Context, fn func(ctx context.Context, repos domain.Repositories) error) error {
+	u.store.writeMu.Lock()
+	defer u.store.writeMu.Unlock()
+
+	u.store.mu.RLock()
+	tx := &Store{data: u.store.data.clone()}
+	u.store.mu.RUnlock()
+
+	repos := domain.Repositories{
+		Teams: NewTeamRepo(tx),
+		Users: NewUserRepo(tx),
+		Prs:   NewPullRequestRepo(tx),
+	}
+	if err := fn(ctx, repos); err != nil {
+		return err
+	}
+
+	u.store.mu.Lock()
+	u.store.data = tx.data
+	u.store.mu.Unlock()
+	return nil
+}
@@ -0,0 +1,125 @@
+package memory
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"sort"
+
+	"pr-reviewer-service/internal/domain"
+)
+
+type TeamRepo struct {
+	store *Store
+}
+
+func NewTeamRepo(store *Store) *TeamRepo {
+	return &TeamRepo{store: store}
+}
+
+func (r *TeamRepo) CreateTeam(ctx context.Context, name domain.TeamName) error {
+	return r.store.write(func(d *data) error {
+		d.teams[name] = struct{}{}
+		return nil
+	})
+}
+
+func (r *TeamRepo) ListTeams(ctx context.Context) ([]domain.TeamName, error) {
+	var res []domain.TeamName
+	r.store.read(func(d *data) {
+		for name := range d.teams {
+			res = append(res, name)
+		}
+	})
+	slices.Sort(res)
+	return res, nil
+}
+
+func (r *TeamRepo) GetTeam(ctx context.Context, name domain.TeamName) (domain.Team, error) {
+	team := domain.Team{Name: name}
+	var exists bool
+	r.store.read(func(d *data) {
+		if _, exists = d.teams[name]; !exists {
+			return
+		}
+		for _, u := range d.users {
+			if u.TeamName == name {
+				team.Members = append(team.Members, u)
+			}
+		}
+	})
+	if !exists {
+		return domain.Team{}, domain.ErrNotFound
+	}
+
+	sort.Slice(team.Members, func(i, j int) bool { return team.Members[i].ID < team.Members[j].ID })
+	return team, nil
+}
+
+func (r *TeamRepo) TeamExists(ctx context.Context, name domain.TeamName) (bool, error) {
+	var exists bool
+	r.store.read(func(d *data) {
+		_, exists = d.teams[name]
+	})
+	return exists, nil
+}
+
+func (r *TeamRepo) GetSettings(ctx context.Context, name domain.TeamName) (domain.TeamSettings, error) {
+	var settings domain.TeamSettings
+	var exists bool
+	r.store.read(func(d *data) {
+		if _, exists = d.teams[name]; !exists {
+			return
+		}
+		settings = domain.DefaultTeamSettings(name)
+		if s, ok := d.settings[name]; ok {
+			settings = cloneSettings(s)
+		}
+	})
+	if !exists {
+		return domain.TeamSettings{}, domain.ErrNotFound
+	}
+	return settings, nil
+}
+
+func (r *TeamRepo) UpsertSettings(ctx context.Context, settings domain.TeamSettings) error {
+	return r.store.write(func(d *data) error {
+		if _, ok := d.teams[settings.TeamName]; !ok {
+			return fmt.Errorf("upsert team settings: team %s does not exist", settings.TeamName)
+		}
+		for _, f := range settings.FallbackTeams {
+			if _, ok := d.teams[f]; !ok {
+				return fmt.Errorf("insert team fallback %s: team does not exist", f)
+			}
+		}
+		d.settings[settings.TeamName] = cloneSettings(settings)
+		return nil
+	})
+}
+
+func (r *TeamRepo) GetCodeowners(ctx context.Context, name domain.TeamName) (domain.Codeowners, error) {
+	var c domain.Codeowners
+	var ok bool
+	r.store.read(func(d *data) {
+		c, ok = d.codeowners[name]
+	})
+	if !ok {
+		return domain.Codeowners{}, domain.ErrNotFound
+	}
+	return c, nil
+}
+
+func (r *TeamRepo) PutCodeowners(ctx context.Context, c domain.Codeowners) error {
+	return r.store.write(func(d *data) error {
+		if _, ok := d.teams[c.TeamName]; !ok {
+			return fmt.Errorf("put team codeowners: team %s does not exist", c.TeamName)
+		}
+		d.codeowners[c.TeamName] = c
+		return nil
+	})
+}
+
+func cloneSettings(s domain.TeamSettings) domain.TeamSettings {
+	s.FallbackTeams = slices.Clone(s.FallbackTeams)
+	return s
+}
@@ -0,0 +1,202 @@
+package memory
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"time"
+
+	"pr-reviewer-service/internal/domain"
+)
+
+type UserRepo struct {
+	store *Store
+}
+
+func NewUserRepo(store *Store) *UserRepo {
+	return &UserRepo{store: store}
+}
+
+func (r *UserRepo) UpsertUsers(ctx context.Context, users []domain.User) error {
+	return r.store.write(func(d *data) error {
+		for _, u := range users {
+			if _, ok := d.teams[u.TeamName]; !ok {
+				return fmt.Errorf("exec upsert user %s: team %s does not exist", u.ID, u.TeamName)
+			}
+		}
+		for _, u := range users {
+			d.users[u.ID] = u
+		}
+		return nil
+	})
+}
+
+func (r *UserRepo) GetByID(ctx context.Context, id domain.UserID) (domain.User, error) {
+	var u domain.User
+	var ok bool
+	r.store.read(func(d *data) {
+		u, ok = d.users[id]
+	})
+	if !ok {
+		return domain.User{}, domain.ErrNotFound
+	}
+	return u, nil
+}
+
+func (r *UserRepo) SetIsActive(ctx context.Context, id domain.UserID, isActive bool, actor domain.UserID) (domain.User, error) {
+	var res domain.User
+	err := r.store.write(func(d *data) error {
+		u, ok := d.users[id]
+		if !ok {
+			return domain.ErrNotFound
+		}
+		u.IsActive = isActive
+		d.users[id] = u
+		d.nextStatusChangeID++
+		d.statusChanges = append(d.statusChanges, domain.UserStatusChange{
+			ID:        d.nextStatusChangeID,
+			UserID:    id,
+			IsActive:  isActive,
+			Actor:     actor,
+			ChangedAt: time.Now().UTC(),
+		})
+		res = u
+		return nil
+	})
+	return res, err
+}
+
+func (r *UserRepo) ListStatusChanges(ctx context.Context, id domain.UserID) ([]domain.UserStatusChange, error) {
+	var res []domain.UserStatusChange
+	r.store.read(func(d *data) {
+		for _, c := range d.statusChanges {
+			if c.UserID == id {
+				res = append(res, c)
+			}
+		}
+	})
+	return res, nil
+}
+
+func (r *UserRepo) SetMaxOpenReviews(ctx context.Context, id domain.UserID, maxOpenReviews int) (domain.User, error) {
+	return r.update(id, func(u *domain.User) {
+		u.MaxOpenReviews = maxOpenReviews
+	})
+}
+
+func (r *UserRepo) update(id domain.UserID, fn func(u *domain.User)) (domain.User, error) {
+	var res domain.User
+	err := r.store.write(func(d *data) error {
+		u, ok := d.users[id]
+		if !ok {
+			return domain.ErrNotFound
+		}
+		fn(&u)
+		d.users[id] = u
+		res = u
+		return nil
+	})
+	return res, err
+}
+
+func (r *UserRepo) ListActiveByTeam(ctx context.Context, teamName domain.TeamName) ([]domain.User, error) {
+	now := time.Now()
+	var res []domain.User
+	r.store.read(func(d *data) {
+		for _, u := range d.users {
+			if u.TeamName == teamName && u.IsActive && !unavailableAt(d, u.ID, now) {
+				res = append(res, u)
+			}
+		}
+	})
+	sort.Slice(res, func(i, j int) bool { return res[i].ID < res[j].ID })
+	return res, nil
+}
+
+func unavailableAt(d *data, id domain.UserID, t time.Time) bool {
+	for _, p := range d.unavailability {
+		if p.UserID == id && p.Covers(t) {
+			return true
+		}
+	}
+	return false
+}
+
+func (r *UserRepo) AddUnavailability(ctx context.Context, u domain.Unavailability) (domain.Unavailability, error) {
+	err := r.store.write(func(d *data) error {
+		if _, ok := d.users[u.UserID]; !ok {
+			return fmt.Errorf("insert unavailability: user %s does not exist", u.UserID)
+		}
+		d.nextPeriodID++
+		u.ID = d.nextPeriodID
+		d.unavailability = append(d.unavailability, u)
+		return nil
+	})
+	if err != nil {
+		return domain.Unavailability{}, err
+	}
+	return u, nil
+}
+
+func (r *UserRepo) ListUnavailability(ctx context.Context, id domain.UserID) ([]domain.Unavailability, error) {
+	return r.listUnavailability(func(p domain.Unavailability) bool {
+		return p.UserID == id
+	}), nil
+}
+
+func (r *UserRepo) GetUnavailability(ctx context.Context, id int64) (domain.Unavailability, error) {
+	periods := r.listUnavailability(func(p domain.Unavailability) bool {
+		return p.ID == id
+	})
+	if len(periods) == 0 {
+		return domain.Unavailability{}, domain.ErrNotFound
+	}
+	return periods[0], nil
+}
+
+func (r *UserRepo) DeleteUnavailability(ctx context.Context, id int64) error {
+	return r.store.write(func(d *data) error {
+		for i, p := range d.unavailability {
+			if p.ID == id {
+				d.unavailability = append(d.unavailability[:i:i], d.unavailability[i+1:]...)
+				return nil
+			}
+		}
+		return domain.ErrNotFound
+	})
+}
+
+func (r *UserRepo) ListDueReassignments(ctx context.Context, now time.Time) ([]domain.Unavailability, error) {
+	return r.listUnavailability(func(p domain.Unavailability) bool {
+		return p.ReassignOpenReviews && p.ReassignedAt == nil && p.Covers(now)
+	}), nil
+}
+
+func (r *UserRepo) MarkReassigned(ctx context.Context, id int64, at time.Time) error {
+	return r.store.write(func(d *data) error {
+		for i := range d.unavailability {
+			if d.unavailability[i].ID == id {
+				d.unavailability[i].ReassignedAt = &at
+			}
+		}
+		return nil
+	})
+}
+
+func (r *UserRepo) listUnavailability(match func(p domain.Unavailability) bool) []domain.Unavailability {
+	var res []domain.Unavailability
+	r.store.read(func(d *data) {
+		for _, p := range d.unavailability {
+			if match(p) {
+				res = append(res, p)
+			}
+		}
+	})
+	sort.Slice(res, func(i, j int) bool {
+		if !res[i].StartsAt.Equal(res[j].StartsAt) {
+			return res[i].StartsAt.Before(res[j].StartsAt)
+		}
+		return res[i].ID < res[j].ID
+	})
+	return res
+}
@@ -0,0 +1,111 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"pr-reviewer-service/internal/domain"
+)
+
+type APIKeyRepo struct {
+	db executor
+}
+
+func NewAPIKeyRepo(db *sql.DB) *APIKeyRepo {
+	return &APIKeyRepo{db: executor{db: db}}
+}
+
+func (r *APIKeyRepo) Create(ctx context.Context, key domain.APIKey) (domain.APIKey, error) {
+	err := r.db.QueryRowContext(ctx, `
+        INSERT INTO api_keys (name, role, team_name, key_hash, created_at)
+        VALUES ($1, $2, $3, $4, $5)
+        RETURNING api_key_id
+    `, key.Name, string(key.Role), nullTeamName(key.TeamName), key.Hash, key.CreatedAt).Scan(&key.ID)
+	if err != nil {
+		return domain.APIKey{}, fmt.Errorf("insert api key: %w", err)
+	}
+	return key, nil
+}
+
+func (r *APIKeyRepo) GetByHash(ctx context.Context, hash string) (domain.APIKey, error) {
+	rows, err := r.db.QueryContext(ctx, `
+        SELECT api_key_id, name, role, team_name, key_hash, created_at, revoked_at
+        FROM api_keys
+        WHERE key_hash = $1 AND revoked_at IS NULL
+    `, hash)
+	if err != nil {
+		return domain.APIKey{}, fmt.Errorf("get api key: %w", err)
+	}
+
+	keys, err := scanAPIKeys(rows)
+	if err != nil {
+		return domain.APIKey{}, err
+	}
+	if len(keys) == 0 {
+		return domain.APIKey{}, domain.ErrNotFound
+	}
+	return keys[0], nil
+}
+
+func (r *APIKeyRepo) List(ctx context.Context) ([]domain.APIKey, error) {
+	rows, err := r.db.QueryContext(ctx, `
+        SELECT api_key_id, name, role, team_name, key_hash, created_at, revoked_at
+        FROM api_keys
+        ORDER BY api_key_id
+    `)
+	if err != nil {
+		return nil, fmt.Errorf("list api keys: %w", err)
+	}
+	return scanAPIKeys(rows)
+}
+
+func (r *APIKeyRepo) Revoke(ctx context.Context, id int64, at time.Time) error {
+	res, err := r.db.ExecContext(ctx, `
+        UPDATE api_keys
+        SET revoked_at = $2
+        WHERE api_key_id = $1 AND revoked_at IS NULL
+    `, id, at)
+	if err != nil {
+		return fmt.Errorf("revoke api key: %w", err)
+	}
+
+	n, err := res.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("revoke api key rows affected: %w", err)
+	}
+	if n == 0 {
+		return domain.ErrNotFound
+	}
+	return nil
+}
+
+func scanAPIKeys(rows *sql.Rows) ([]domain.APIKey, error) {
+	defer func() {
+		_ = rows.Close()
+	}()
+
+	var res []domain.APIKey
+	for rows.Next() {
+		var k domain.APIKey
+		var role string
+		var teamName sql.NullString
+		var revokedAt sql.NullTime
+		if err := rows.Scan(&k.ID, &k.Name, &role, &teamName, &k.Hash, &k.CreatedAt, &revokedAt); err != nil {
+			return nil, fmt.Errorf("scan api key: %w", err)
+		}
+		k.Role = domain.Role(role)
+		k.TeamName = domain.TeamName(teamName.String)
+		if revokedAt.Valid {
+			t := revokedAt.Time
+			k.RevokedAt = &t
+		}
+		res = append(res, k)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("iterate api keys: %w", err)
+	}
+
+	return res, nil
+}
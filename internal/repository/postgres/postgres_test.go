@@ -0,0 +1,51 @@
+package postgres_test
+
+import (
+	"context"
+	"os"
+	"testing"
+
+	"pr-reviewer-service/internal/domain"
+	"pr-reviewer-service/internal/migrations"
+	"pr-reviewer-service/internal/repository/postgres"
+	"pr-reviewer-service/internal/repository/repotest"
+)
+
+// TestContract needs a disposable database; every subtest truncates it.
+func TestContract(t *testing.T) {
+	dsn := os.Getenv("TEST_DB_DSN")
+	if dsn == "" {
+		t.Skip("TEST_DB_DSN is not set")
+	}
+
+	db, err := postgres.New(dsn)
+	if err != nil {
+		t.Fatalf("connect: %v", err)
+	}
+	t.Cleanup(func() {
+		_ = db.Close()
+	})
+
+	ctx := context.Background()
+	if err := migrations.Run(ctx, db.Conn()); err != nil {
+		t.Fatalf("migrate: %v", err)
+	}
+
+	repotest.Run(t, func(t *testing.T) repotest.Backend {
+		if _, err := db.Conn().ExecContext(ctx, `
+            TRUNCATE teams, users, pull_requests, user_unavailability, reviewer_assignment_events, user_status_changes, api_keys
+            RESTART IDENTITY CASCADE
+        `); err != nil {
+			t.Fatalf("truncate: %v", err)
+		}
+		return repotest.Backend{
+			Repos: domain.Repositories{
+				Teams: postgres.NewTeamRepo(db.Conn()),
+				Users: postgres.NewUserRepo(db.Conn()),
+				Prs:   postgres.NewPullRequestRepo(db.Conn()),
+			},
+			Tx:      postgres.NewUnitOfWork(db.Conn()),
+			APIKeys: postgres.NewAPIKeyRepo(db.Conn()),
+		}
+	})
+}
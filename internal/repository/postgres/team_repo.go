@@ -9,11 +9,11 @@ import (
 )
 
 type TeamRepo struct {
-	db *sql.DB
+	db executor
 }
 
 func NewTeamRepo(db *sql.DB) *TeamRepo {
-	return &TeamRepo{db: db}
+	return &TeamRepo{db: executor{db: db}}
 }
 
 func (r *TeamRepo) CreateTeam(ctx context.Context, name domain.TeamName) error {
@@ -28,6 +28,34 @@ func (r *TeamRepo) CreateTeam(ctx context.Context, name domain.TeamName) error {
 	return nil
 }
 
+func (r *TeamRepo) ListTeams(ctx context.Context) ([]domain.TeamName, error) {
+	rows, err := r.db.QueryContext(ctx, `
+        SELECT team_name
+        FROM teams
+        ORDER BY team_name
+    `)
+	if err != nil {
+		return nil, fmt.Errorf("list teams: %w", err)
+	}
+	defer func() {
+		_ = rows.Close()
+	}()
+
+	var res []domain.TeamName
+	for rows.Next() {
+		var name string
+		if err := rows.Scan(&name); err != nil {
+			return nil, fmt.Errorf("scan team: %w", err)
+		}
+		res = append(res, domain.TeamName(name))
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("iterate teams: %w", err)
+	}
+
+	return res, nil
+}
+
 func (r *TeamRepo) GetTeam(ctx context.Context, name domain.TeamName) (domain.Team, error) {
 	var team domain.Team
 	team.Name = name
@@ -44,7 +72,7 @@ func (r *TeamRepo) GetTeam(ctx context.Context, name domain.TeamName) (domain.Te
 	}
 
 	rows, err := r.db.QueryContext(ctx, `
-        SELECT user_id, username, is_active
+        SELECT user_id, username, is_active, slack_handle, max_open_reviews
         FROM users
         WHERE team_name = $1
         ORDER BY user_id
@@ -58,16 +86,19 @@ func (r *TeamRepo) GetTeam(ctx context.Context, name domain.TeamName) (domain.Te
 
 	var members []domain.User
 	for rows.Next() {
-		var id, username string
+		var id, username, slackHandle string
 		var active bool
-		if err := rows.Scan(&id, &username, &active); err != nil {
+		var maxOpenReviews int
+		if err := rows.Scan(&id, &username, &active, &slackHandle, &maxOpenReviews); err != nil {
 			return domain.Team{}, fmt.Errorf("scan member: %w", err)
 		}
 		members = append(members, domain.User{
-			ID:       domain.UserID(id),
-			Username: username,
-			TeamName: name,
-			IsActive: active,
+			ID:             domain.UserID(id),
+			Username:       username,
+			TeamName:       name,
+			IsActive:       active,
+			SlackHandle:    slackHandle,
+			MaxOpenReviews: maxOpenReviews,
 		})
 	}
 	if err := rows.Err(); err != nil {
@@ -88,3 +119,139 @@ func (r *TeamRepo) TeamExists(ctx context.Context, name domain.TeamName) (bool,
 	}
 	return exists, nil
 }
+
+func (r *TeamRepo) GetSettings(ctx context.Context, name domain.TeamName) (domain.TeamSettings, error) {
+	var reviewersCount, minPoolSize, requiredApprovals sql.NullInt64
+	var strategy, slackWebhookURL sql.NullString
+
+	err := r.db.QueryRowContext(ctx, `
+        SELECT s.reviewers_count, s.strategy, s.min_pool_size, s.slack_webhook_url, s.required_approvals
+        FROM teams t
+        LEFT JOIN team_settings s ON s.team_name = t.team_name
+        WHERE t.team_name = $1
+    `, string(name)).Scan(&reviewersCount, &strategy, &minPoolSize, &slackWebhookURL, &requiredApprovals)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return domain.TeamSettings{}, domain.ErrNotFound
+		}
+		return domain.TeamSettings{}, fmt.Errorf("get team settings: %w", err)
+	}
+
+	settings := domain.DefaultTeamSettings(name)
+	if strategy.Valid {
+		settings.ReviewersCount = int(reviewersCount.Int64)
+		settings.Strategy = domain.ReviewerStrategy(strategy.String)
+		settings.MinPoolSize = int(minPoolSize.Int64)
+		settings.SlackWebhookURL = slackWebhookURL.String
+		settings.RequiredApprovals = int(requiredApprovals.Int64)
+	}
+
+	rows, err := r.db.QueryContext(ctx, `
+        SELECT fallback_team_name
+        FROM team_fallbacks
+        WHERE team_name = $1
+        ORDER BY position
+    `, string(name))
+	if err != nil {
+		return domain.TeamSettings{}, fmt.Errorf("get team fallbacks: %w", err)
+	}
+	defer func() {
+		_ = rows.Close()
+	}()
+
+	for rows.Next() {
+		var fallback string
+		if err := rows.Scan(&fallback); err != nil {
+			return domain.TeamSettings{}, fmt.Errorf("scan team fallback: %w", err)
+		}
+		settings.FallbackTeams = append(settings.FallbackTeams, domain.TeamName(fallback))
+	}
+	if err := rows.Err(); err != nil {
+		return domain.TeamSettings{}, fmt.Errorf("iterate team fallbacks: %w", err)
+	}
+
+	return settings, nil
+}
+
+func (r *TeamRepo) UpsertSettings(ctx context.Context, settings domain.TeamSettings) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("upsert team settings begin tx: %w", err)
+	}
+	defer func() {
+		_ = tx.Rollback()
+	}()
+
+	_, err = tx.ExecContext(ctx, `
+        INSERT INTO team_settings (team_name, reviewers_count, strategy, min_pool_size, slack_webhook_url, required_approvals)
+        VALUES ($1, $2, $3, $4, $5, $6)
+        ON CONFLICT (team_name) DO UPDATE
+        SET reviewers_count = EXCLUDED.reviewers_count,
+            strategy = EXCLUDED.strategy,
+            min_pool_size = EXCLUDED.min_pool_size,
+            slack_webhook_url = EXCLUDED.slack_webhook_url,
+            required_approvals = EXCLUDED.required_approvals
+    `,
+		string(settings.TeamName),
+		settings.ReviewersCount,
+		string(settings.Strategy),
+		settings.MinPoolSize,
+		settings.SlackWebhookURL,
+		settings.RequiredApprovals,
+	)
+	if err != nil {
+		return fmt.Errorf("upsert team settings: %w", err)
+	}
+
+	if _, err := tx.ExecContext(ctx, `
+        DELETE FROM team_fallbacks
+        WHERE team_name = $1
+    `, string(settings.TeamName)); err != nil {
+		return fmt.Errorf("delete team fallbacks: %w", err)
+	}
+
+	for i, fallback := range settings.FallbackTeams {
+		if _, err := tx.ExecContext(ctx, `
+            INSERT INTO team_fallbacks (team_name, fallback_team_name, position)
+            VALUES ($1, $2, $3)
+        `, string(settings.TeamName), string(fallback), i); err != nil {
+			return fmt.Errorf("insert team fallback %s: %w", fallback, err)
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("upsert team settings commit: %w", err)
+	}
+
+	return nil
+}
+
+func (r *TeamRepo) GetCodeowners(ctx context.Context, name domain.TeamName) (domain.Codeowners, error) {
+	c := domain.Codeowners{TeamName: name}
+	err := r.db.QueryRowContext(ctx, `
+        SELECT content, updated_at
+        FROM team_codeowners
+        WHERE team_name = $1
+    `, string(name)).Scan(&c.Content, &c.UpdatedAt)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return domain.Codeowners{}, domain.ErrNotFound
+		}
+		return domain.Codeowners{}, fmt.Errorf("get team codeowners: %w", err)
+	}
+	return c, nil
+}
+
+func (r *TeamRepo) PutCodeowners(ctx context.Context, c domain.Codeowners) error {
+	_, err := r.db.ExecContext(ctx, `
+        INSERT INTO team_codeowners (team_name, content, updated_at)
+        VALUES ($1, $2, $3)
+        ON CONFLICT (team_name) DO UPDATE
+        SET content = EXCLUDED.content,
+            updated_at = EXCLUDED.updated_at
+    `, string(c.TeamName), c.Content, c.UpdatedAt)
+	if err != nil {
+		return fmt.Errorf("put team codeowners: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,45 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"strings"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/codes"
+	semconv "go.opentelemetry.io/otel/semconv/v1.26.0"
+	"go.opentelemetry.io/otel/trace"
+)
+
+var tracer = otel.Tracer("pr-reviewer-service/internal/repository/postgres")
+
+// startQuerySpan names the span after the statement's leading keyword, such
+// as SELECT or INSERT. The statement goes in as written: arguments are bound
+// separately and never recorded.
+func startQuerySpan(ctx context.Context, query string) (context.Context, trace.Span) {
+	query = strings.TrimSpace(query)
+	op := "QUERY"
+	if fields := strings.Fields(query); len(fields) > 0 {
+		op = strings.ToUpper(fields[0])
+	}
+
+	return tracer.Start(ctx, op,
+		trace.WithSpanKind(trace.SpanKindClient),
+		trace.WithAttributes(
+			semconv.DBSystemPostgreSQL,
+			semconv.DBQueryText(query),
+			semconv.DBOperationName(op),
+		),
+	)
+}
+
+// endSpan marks failures other than a missing row, which callers turn into
+// domain.ErrNotFound.
+func endSpan(span trace.Span, err error) {
+	if err != nil && !errors.Is(err, sql.ErrNoRows) {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	}
+	span.End()
+}
@@ -0,0 +1,175 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"io"
+	"strings"
+	"testing"
+	"time"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+
+	"pr-reviewer-service/internal/domain"
+)
+
+// stubDriver accepts every statement and returns no rows, which is enough to
+// see which statements a repository sends. Queries mentioning not_a_number
+// return one text row instead.
+type stubDriver struct{}
+
+func (stubDriver) Open(string) (driver.Conn, error) { return stubConn{}, nil }
+
+type stubConn struct{}
+
+func (stubConn) Prepare(query string) (driver.Stmt, error) { return stubStmt{query: query}, nil }
+func (stubConn) Close() error                              { return nil }
+func (stubConn) Begin() (driver.Tx, error)                 { return stubTx{}, nil }
+
+type stubTx struct{}
+
+func (stubTx) Commit() error   { return nil }
+func (stubTx) Rollback() error { return nil }
+
+type stubStmt struct {
+	query string
+}
+
+func (stubStmt) Close() error                               { return nil }
+func (stubStmt) NumInput() int                              { return -1 }
+func (stubStmt) Exec([]driver.Value) (driver.Result, error) { return driver.RowsAffected(1), nil }
+
+func (s stubStmt) Query([]driver.Value) (driver.Rows, error) {
+	if strings.Contains(s.query, "not_a_number") {
+		return &stubRows{columns: []string{"n"}, values: []driver.Value{"x"}}, nil
+	}
+	return &stubRows{}, nil
+}
+
+type stubRows struct {
+	columns []string
+	values  []driver.Value
+}
+
+func (r *stubRows) Columns() []string { return r.columns }
+func (r *stubRows) Close() error      { return nil }
+
+func (r *stubRows) Next(dest []driver.Value) error {
+	if r.values == nil {
+		return io.EOF
+	}
+	copy(dest, r.values)
+	r.values = nil
+	return nil
+}
+
+// spanExporter is installed once: the package tracer binds to the first global
+// provider and ignores later ones.
+var spanExporter = tracetest.NewInMemoryExporter()
+
+func init() {
+	sql.Register("postgres-stub", stubDriver{})
+	otel.SetTracerProvider(sdktrace.NewTracerProvider(sdktrace.WithSyncer(spanExporter)))
+}
+
+func recordSpans() *tracetest.InMemoryExporter {
+	spanExporter.Reset()
+	return spanExporter
+}
+
+func TestUnitOfWorkTracesEveryStatement(t *testing.T) {
+	exporter := recordSpans()
+
+	db, err := sql.Open("postgres-stub", "")
+	if err != nil {
+		t.Fatalf("open: %v", err)
+	}
+	t.Cleanup(func() {
+		_ = db.Close()
+	})
+
+	pr := domain.PullRequest{
+		ID:                "pr-1",
+		Name:              "Search",
+		AuthorID:          "u1",
+		Status:            domain.PRStatusOpen,
+		AssignedReviewers: []domain.UserID{"u2", "u3"},
+		CreatedAt:         time.Now(),
+	}
+	err = NewUnitOfWork(db).Do(context.Background(), func(ctx context.Context, repos domain.Repositories) error {
+		return repos.Prs.Create(ctx, pr)
+	})
+	if err != nil {
+		t.Fatalf("create in unit of work: %v", err)
+	}
+
+	spans := exporter.GetSpans()
+	var uow sdktrace.ReadOnlySpan
+	for _, s := range spans.Snapshots() {
+		if s.Name() == "postgres.UnitOfWork" {
+			uow = s
+		}
+	}
+	if uow == nil {
+		t.Fatalf("no unit of work span among %d spans", len(spans))
+	}
+
+	var inserts, prepares int
+	for _, s := range spans.Snapshots() {
+		if s.Name() == "PREPARE" {
+			prepares++
+		}
+		if s.Name() != "INSERT" {
+			continue
+		}
+		inserts++
+		if s.Parent().SpanID() != uow.SpanContext().SpanID() {
+			t.Errorf("INSERT span is not a child of the unit of work")
+		}
+	}
+	// One for the pull request and one per reviewer row.
+	if inserts != 3 || prepares != 1 {
+		t.Fatalf("expected 3 INSERT spans and 1 PREPARE, got %d and %d", inserts, prepares)
+	}
+}
+
+func TestQueryRowSpanCoversScan(t *testing.T) {
+	exporter := recordSpans()
+
+	db, err := sql.Open("postgres-stub", "")
+	if err != nil {
+		t.Fatalf("open: %v", err)
+	}
+	t.Cleanup(func() {
+		_ = db.Close()
+	})
+	ctx := context.Background()
+	e := executor{db: db}
+
+	row := e.QueryRowContext(ctx, "SELECT 1 WHERE false")
+	if n := len(exporter.GetSpans()); n != 0 {
+		t.Fatalf("span must stay open until Scan, got %d ended", n)
+	}
+	var n int
+	if err := row.Scan(&n); !errors.Is(err, sql.ErrNoRows) {
+		t.Fatalf("expected sql.ErrNoRows, got %v", err)
+	}
+	spans := exporter.GetSpans()
+	if len(spans) != 1 || spans[0].Status.Code == codes.Error {
+		t.Fatalf("a missing row must end the span without an error: %+v", spans)
+	}
+
+	exporter.Reset()
+	if err := e.QueryRowContext(ctx, "SELECT not_a_number").Scan(&n); err == nil {
+		t.Fatalf("expected a scan error")
+	}
+	spans = exporter.GetSpans()
+	if len(spans) != 1 || spans[0].Status.Code != codes.Error || len(spans[0].Events) == 0 {
+		t.Fatalf("scan error must be recorded on the span: %+v", spans)
+	}
+}
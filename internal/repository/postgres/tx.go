@@ -0,0 +1,187 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+
+	"github.com/jackc/pgx/v5/pgconn"
+	"go.opentelemetry.io/otel/trace"
+
+	"pr-reviewer-service/internal/domain"
+)
+
+// conn is what *sql.DB and *sql.Tx have in common.
+type conn interface {
+	ExecContext(ctx context.Context, query string, args ...any) (sql.Result, error)
+	QueryContext(ctx context.Context, query string, args ...any) (*sql.Rows, error)
+	QueryRowContext(ctx context.Context, query string, args ...any) *sql.Row
+	PrepareContext(ctx context.Context, query string) (*sql.Stmt, error)
+}
+
+// querier is conn with a span around every statement, including each
+// execution of a prepared one.
+type querier interface {
+	ExecContext(ctx context.Context, query string, args ...any) (sql.Result, error)
+	QueryContext(ctx context.Context, query string, args ...any) (*sql.Rows, error)
+	QueryRowContext(ctx context.Context, query string, args ...any) tracedRow
+	PrepareContext(ctx context.Context, query string) (tracedStmt, error)
+}
+
+type txn interface {
+	querier
+	Commit() error
+	Rollback() error
+}
+
+// executor runs queries on the pool, or on tx when the repository is bound
+// to a unit of work.
+type executor struct {
+	db *sql.DB
+	tx *sql.Tx
+}
+
+func (e executor) q() conn {
+	if e.tx != nil {
+		return e.tx
+	}
+	return e.db
+}
+
+func (e executor) ExecContext(ctx context.Context, query string, args ...any) (sql.Result, error) {
+	ctx, span := startQuerySpan(ctx, query)
+	res, err := e.q().ExecContext(ctx, query, args...)
+	endSpan(span, err)
+	return res, err
+}
+
+// QueryContext's span covers the round trip, not reading the rows.
+func (e executor) QueryContext(ctx context.Context, query string, args ...any) (*sql.Rows, error) {
+	ctx, span := startQuerySpan(ctx, query)
+	rows, err := e.q().QueryContext(ctx, query, args...)
+	endSpan(span, err)
+	return rows, err
+}
+
+// QueryRowContext's span stays open until Scan, so it covers reading the row
+// and records Scan's error.
+func (e executor) QueryRowContext(ctx context.Context, query string, args ...any) tracedRow {
+	ctx, span := startQuerySpan(ctx, query)
+	return tracedRow{row: e.q().QueryRowContext(ctx, query, args...), span: span}
+}
+
+// tracedRow ends its span when scanned; like *sql.Row it must be scanned.
+type tracedRow struct {
+	row  *sql.Row
+	span trace.Span
+}
+
+func (r tracedRow) Scan(dest ...any) error {
+	err := r.row.Scan(dest...)
+	endSpan(r.span, err)
+	return err
+}
+
+func (e executor) PrepareContext(ctx context.Context, query string) (tracedStmt, error) {
+	ctx, span := startQuerySpan(ctx, query)
+	span.SetName("PREPARE")
+	stmt, err := e.q().PrepareContext(ctx, query)
+	endSpan(span, err)
+	return tracedStmt{Stmt: stmt, query: query}, err
+}
+
+// tracedStmt gives every execution of a prepared statement its own span.
+type tracedStmt struct {
+	*sql.Stmt
+	query string
+}
+
+func (s tracedStmt) ExecContext(ctx context.Context, args ...any) (sql.Result, error) {
+	ctx, span := startQuerySpan(ctx, s.query)
+	res, err := s.Stmt.ExecContext(ctx, args...)
+	endSpan(span, err)
+	return res, err
+}
+
+// BeginTx starts a new transaction, or joins the unit of work's one, in which
+// case Commit and Rollback are left to the unit of work. Either way its
+// statements are traced like the executor's.
+func (e executor) BeginTx(ctx context.Context, opts *sql.TxOptions) (txn, error) {
+	if e.tx != nil {
+		return joinedTx{executor{tx: e.tx}}, nil
+	}
+	tx, err := e.db.BeginTx(ctx, opts)
+	if err != nil {
+		return nil, err
+	}
+	return ownTx{executor{tx: tx}}, nil
+}
+
+type ownTx struct {
+	executor
+}
+
+func (t ownTx) Commit() error {
+	return t.tx.Commit()
+}
+
+func (t ownTx) Rollback() error {
+	return t.tx.Rollback()
+}
+
+type joinedTx struct {
+	executor
+}
+
+func (joinedTx) Commit() error {
+	return nil
+}
+
+func (joinedTx) Rollback() error {
+	return nil
+}
+
+type UnitOfWork struct {
+	db *sql.DB
+}
+
+func NewUnitOfWork(db *sql.DB) *UnitOfWork {
+	return &UnitOfWork{db: db}
+}
+
+func (u *UnitOfWork) Do(ctx context.Context, fn func(ctx context.Context, repos domain.Repositories) error) (err error) {
+	ctx, span := tracer.Start(ctx, "postgres.UnitOfWork", trace.WithSpanKind(trace.SpanKindClient))
+	defer func() { endSpan(span, err) }()
+
+	tx, err := u.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("unit of work begin tx: %w", err)
+	}
+	defer func() {
+		_ = tx.Rollback()
+	}()
+
+	ex := executor{db: u.db, tx: tx}
+	repos := domain.Repositories{
+		Teams:  &TeamRepo{db: ex},
+		Users:  &UserRepo{db: ex},
+		Prs:    &PullRequestRepo{db: ex},
+		Outbox: &WebhookRepo{db: ex},
+	}
+
+	if err := fn(ctx, repos); err != nil {
+		return err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("unit of work commit: %w", err)
+	}
+
+	return nil
+}
+
+func isUniqueViolation(err error) bool {
+	var pgErr *pgconn.PgError
+	return errors.As(err, &pgErr) && pgErr.Code == "23505"
+}
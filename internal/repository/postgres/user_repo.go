@@ -4,16 +4,17 @@ import (
 	"context"
 	"database/sql"
 	"fmt"
+	"time"
 
 	"pr-reviewer-service/internal/domain"
 )
 
 type UserRepo struct {
-	db *sql.DB
+	db executor
 }
 
 func NewUserRepo(db *sql.DB) *UserRepo {
-	return &UserRepo{db: db}
+	return &UserRepo{db: executor{db: db}}
 }
 
 func (r *UserRepo) UpsertUsers(ctx context.Context, users []domain.User) error {
@@ -30,12 +31,14 @@ func (r *UserRepo) UpsertUsers(ctx context.Context, users []domain.User) error {
 	}()
 
 	stmt, err := tx.PrepareContext(ctx, `
-        INSERT INTO users (user_id, username, team_name, is_active)
-        VALUES ($1, $2, $3, $4)
+        INSERT INTO users (user_id, username, team_name, is_active, slack_handle, max_open_reviews)
+        VALUES ($1, $2, $3, $4, $5, $6)
         ON CONFLICT (user_id) DO UPDATE
         SET username = EXCLUDED.username,
             team_name = EXCLUDED.team_name,
-            is_active = EXCLUDED.is_active
+            is_active = EXCLUDED.is_active,
+            slack_handle = EXCLUDED.slack_handle,
+            max_open_reviews = EXCLUDED.max_open_reviews
     `)
 	if err != nil {
 		return fmt.Errorf("prepare upsert users: %w", err)
@@ -50,6 +53,8 @@ func (r *UserRepo) UpsertUsers(ctx context.Context, users []domain.User) error {
 			u.Username,
 			string(u.TeamName),
 			u.IsActive,
+			u.SlackHandle,
+			u.MaxOpenReviews,
 		); err != nil {
 			return fmt.Errorf("exec upsert user %s: %w", u.ID, err)
 		}
@@ -63,14 +68,15 @@ func (r *UserRepo) UpsertUsers(ctx context.Context, users []domain.User) error {
 }
 
 func (r *UserRepo) GetByID(ctx context.Context, id domain.UserID) (domain.User, error) {
-	var userID, username, teamName string
+	var userID, username, teamName, slackHandle string
 	var isActive bool
+	var maxOpenReviews int
 
 	err := r.db.QueryRowContext(ctx, `
-        SELECT user_id, username, team_name, is_active
+        SELECT user_id, username, team_name, is_active, slack_handle, max_open_reviews
         FROM users
         WHERE user_id = $1
-    `, string(id)).Scan(&userID, &username, &teamName, &isActive)
+    `, string(id)).Scan(&userID, &username, &teamName, &isActive, &slackHandle, &maxOpenReviews)
 	if err != nil {
 		if err == sql.ErrNoRows {
 			return domain.User{}, domain.ErrNotFound
@@ -79,15 +85,25 @@ func (r *UserRepo) GetByID(ctx context.Context, id domain.UserID) (domain.User,
 	}
 
 	return domain.User{
-		ID:       domain.UserID(userID),
-		Username: username,
-		TeamName: domain.TeamName(teamName),
-		IsActive: isActive,
+		ID:             domain.UserID(userID),
+		Username:       username,
+		TeamName:       domain.TeamName(teamName),
+		IsActive:       isActive,
+		SlackHandle:    slackHandle,
+		MaxOpenReviews: maxOpenReviews,
 	}, nil
 }
 
-func (r *UserRepo) SetIsActive(ctx context.Context, id domain.UserID, isActive bool) (domain.User, error) {
-	_, err := r.db.ExecContext(ctx, `
+func (r *UserRepo) SetIsActive(ctx context.Context, id domain.UserID, isActive bool, actor domain.UserID) (domain.User, error) {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return domain.User{}, fmt.Errorf("set is_active begin tx: %w", err)
+	}
+	defer func() {
+		_ = tx.Rollback()
+	}()
+
+	res, err := tx.ExecContext(ctx, `
         UPDATE users
         SET is_active = $2
         WHERE user_id = $1
@@ -95,16 +111,92 @@ func (r *UserRepo) SetIsActive(ctx context.Context, id domain.UserID, isActive b
 	if err != nil {
 		return domain.User{}, fmt.Errorf("set is_active: %w", err)
 	}
+	n, err := res.RowsAffected()
+	if err != nil {
+		return domain.User{}, fmt.Errorf("set is_active rows affected: %w", err)
+	}
+	if n == 0 {
+		return domain.User{}, domain.ErrNotFound
+	}
+
+	_, err = tx.ExecContext(ctx, `
+        INSERT INTO user_status_changes (user_id, is_active, actor_id, changed_at)
+        VALUES ($1, $2, $3, $4)
+    `, string(id), isActive, nullUserID(actor), time.Now().UTC())
+	if err != nil {
+		return domain.User{}, fmt.Errorf("insert status change: %w", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return domain.User{}, fmt.Errorf("set is_active commit: %w", err)
+	}
+
+	return r.GetByID(ctx, id)
+}
+
+func (r *UserRepo) ListStatusChanges(ctx context.Context, id domain.UserID) ([]domain.UserStatusChange, error) {
+	rows, err := r.db.QueryContext(ctx, `
+        SELECT change_id, is_active, actor_id, changed_at
+        FROM user_status_changes
+        WHERE user_id = $1
+        ORDER BY change_id
+    `, string(id))
+	if err != nil {
+		return nil, fmt.Errorf("list status changes: %w", err)
+	}
+	defer func() {
+		_ = rows.Close()
+	}()
+
+	var res []domain.UserStatusChange
+	for rows.Next() {
+		var changeID int64
+		var isActive bool
+		var actor sql.NullString
+		var changedAt time.Time
+		if err := rows.Scan(&changeID, &isActive, &actor, &changedAt); err != nil {
+			return nil, fmt.Errorf("scan status change: %w", err)
+		}
+		res = append(res, domain.UserStatusChange{
+			ID:        changeID,
+			UserID:    id,
+			IsActive:  isActive,
+			Actor:     domain.UserID(actor.String),
+			ChangedAt: changedAt,
+		})
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("iterate status changes: %w", err)
+	}
+
+	return res, nil
+}
+
+func (r *UserRepo) SetMaxOpenReviews(ctx context.Context, id domain.UserID, maxOpenReviews int) (domain.User, error) {
+	_, err := r.db.ExecContext(ctx, `
+        UPDATE users
+        SET max_open_reviews = $2
+        WHERE user_id = $1
+    `, string(id), maxOpenReviews)
+	if err != nil {
+		return domain.User{}, fmt.Errorf("set max_open_reviews: %w", err)
+	}
 
 	return r.GetByID(ctx, id)
 }
 
 func (r *UserRepo) ListActiveByTeam(ctx context.Context, teamName domain.TeamName) ([]domain.User, error) {
 	rows, err := r.db.QueryContext(ctx, `
-        SELECT user_id, username, is_active
+        SELECT user_id, username, is_active, slack_handle, max_open_reviews
         FROM users
         WHERE team_name = $1
           AND is_active = TRUE
+          AND NOT EXISTS (
+              SELECT 1 FROM user_unavailability ua
+              WHERE ua.user_id = users.user_id
+                AND ua.starts_at <= now()
+                AND ua.ends_at > now()
+          )
         ORDER BY user_id
     `, string(teamName))
 	if err != nil {
@@ -116,16 +208,19 @@ func (r *UserRepo) ListActiveByTeam(ctx context.Context, teamName domain.TeamNam
 
 	var res []domain.User
 	for rows.Next() {
-		var id, username string
+		var id, username, slackHandle string
 		var active bool
-		if err := rows.Scan(&id, &username, &active); err != nil {
+		var maxOpenReviews int
+		if err := rows.Scan(&id, &username, &active, &slackHandle, &maxOpenReviews); err != nil {
 			return nil, fmt.Errorf("scan active user: %w", err)
 		}
 		res = append(res, domain.User{
-			ID:       domain.UserID(id),
-			Username: username,
-			TeamName: teamName,
-			IsActive: active,
+			ID:             domain.UserID(id),
+			Username:       username,
+			TeamName:       teamName,
+			IsActive:       active,
+			SlackHandle:    slackHandle,
+			MaxOpenReviews: maxOpenReviews,
 		})
 	}
 	if err := rows.Err(); err != nil {
@@ -134,3 +229,124 @@ func (r *UserRepo) ListActiveByTeam(ctx context.Context, teamName domain.TeamNam
 
 	return res, nil
 }
+
+func (r *UserRepo) AddUnavailability(ctx context.Context, u domain.Unavailability) (domain.Unavailability, error) {
+	err := r.db.QueryRowContext(ctx, `
+        INSERT INTO user_unavailability (user_id, starts_at, ends_at, reason, reassign_open_reviews)
+        VALUES ($1, $2, $3, $4, $5)
+        RETURNING unavailability_id
+    `, string(u.UserID), u.StartsAt, u.EndsAt, u.Reason, u.ReassignOpenReviews).Scan(&u.ID)
+	if err != nil {
+		return domain.Unavailability{}, fmt.Errorf("insert unavailability: %w", err)
+	}
+	return u, nil
+}
+
+func (r *UserRepo) ListUnavailability(ctx context.Context, id domain.UserID) ([]domain.Unavailability, error) {
+	rows, err := r.db.QueryContext(ctx, `
+        SELECT unavailability_id, user_id, starts_at, ends_at, reason, reassign_open_reviews, reassigned_at
+        FROM user_unavailability
+        WHERE user_id = $1
+        ORDER BY starts_at, unavailability_id
+    `, string(id))
+	if err != nil {
+		return nil, fmt.Errorf("list unavailability: %w", err)
+	}
+	return scanUnavailability(rows)
+}
+
+func (r *UserRepo) GetUnavailability(ctx context.Context, id int64) (domain.Unavailability, error) {
+	rows, err := r.db.QueryContext(ctx, `
+        SELECT unavailability_id, user_id, starts_at, ends_at, reason, reassign_open_reviews, reassigned_at
+        FROM user_unavailability
+        WHERE unavailability_id = $1
+    `, id)
+	if err != nil {
+		return domain.Unavailability{}, fmt.Errorf("get unavailability: %w", err)
+	}
+
+	periods, err := scanUnavailability(rows)
+	if err != nil {
+		return domain.Unavailability{}, err
+	}
+	if len(periods) == 0 {
+		return domain.Unavailability{}, domain.ErrNotFound
+	}
+	return periods[0], nil
+}
+
+func (r *UserRepo) DeleteUnavailability(ctx context.Context, id int64) error {
+	res, err := r.db.ExecContext(ctx, `
+        DELETE FROM user_unavailability
+        WHERE unavailability_id = $1
+    `, id)
+	if err != nil {
+		return fmt.Errorf("delete unavailability: %w", err)
+	}
+
+	n, err := res.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("delete unavailability rows affected: %w", err)
+	}
+	if n == 0 {
+		return domain.ErrNotFound
+	}
+	return nil
+}
+
+func (r *UserRepo) ListDueReassignments(ctx context.Context, now time.Time) ([]domain.Unavailability, error) {
+	rows, err := r.db.QueryContext(ctx, `
+        SELECT unavailability_id, user_id, starts_at, ends_at, reason, reassign_open_reviews, reassigned_at
+        FROM user_unavailability
+        WHERE reassign_open_reviews = TRUE
+          AND reassigned_at IS NULL
+          AND starts_at <= $1
+          AND ends_at > $1
+        ORDER BY starts_at, unavailability_id
+    `, now)
+	if err != nil {
+		return nil, fmt.Errorf("list due reassignments: %w", err)
+	}
+	return scanUnavailability(rows)
+}
+
+func (r *UserRepo) MarkReassigned(ctx context.Context, id int64, at time.Time) error {
+	_, err := r.db.ExecContext(ctx, `
+        UPDATE user_unavailability
+        SET reassigned_at = $2
+        WHERE unavailability_id = $1
+    `, id, at)
+	if err != nil {
+		return fmt.Errorf("mark unavailability reassigned: %w", err)
+	}
+	return nil
+}
+
+func scanUnavailability(rows *sql.Rows) ([]domain.Unavailability, error) {
+	defer func() {
+		_ = rows.Close()
+	}()
+
+	var res []domain.Unavailability
+	for rows.Next() {
+		var u domain.Unavailability
+		var userID string
+		var reassignedAt sql.NullTime
+		if err := rows.Scan(
+			&u.ID, &userID, &u.StartsAt, &u.EndsAt, &u.Reason, &u.ReassignOpenReviews, &reassignedAt,
+		); err != nil {
+			return nil, fmt.Errorf("scan unavailability: %w", err)
+		}
+		u.UserID = domain.UserID(userID)
+		if reassignedAt.Valid {
+			t := reassignedAt.Time
+			u.ReassignedAt = &t
+		}
+		res = append(res, u)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("iterate unavailability: %w", err)
+	}
+
+	return res, nil
+}
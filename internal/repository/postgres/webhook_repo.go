@@ -0,0 +1,340 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"pr-reviewer-service/internal/domain"
+)
+
+type WebhookRepo struct {
+	db executor
+}
+
+func NewWebhookRepo(db *sql.DB) *WebhookRepo {
+	return &WebhookRepo{db: executor{db: db}}
+}
+
+func (r *WebhookRepo) AppendOutbox(ctx context.Context, events []domain.OutboxEvent) error {
+	if len(events) == 0 {
+		return nil
+	}
+
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("append outbox begin tx: %w", err)
+	}
+	defer func() {
+		_ = tx.Rollback()
+	}()
+
+	stmt, err := tx.PrepareContext(ctx, `
+        INSERT INTO outbox_events (event_type, payload, created_at)
+        VALUES ($1, $2, $3)
+    `)
+	if err != nil {
+		return fmt.Errorf("prepare insert outbox: %w", err)
+	}
+	defer func() {
+		_ = stmt.Close()
+	}()
+
+	for _, e := range events {
+		if _, err := stmt.ExecContext(ctx, string(e.Type), string(e.Payload), e.CreatedAt); err != nil {
+			return fmt.Errorf("insert outbox event %s: %w", e.Type, err)
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("append outbox commit: %w", err)
+	}
+
+	return nil
+}
+
+func (r *WebhookRepo) CreateSubscription(ctx context.Context, sub domain.WebhookSubscription) (domain.WebhookSubscription, error) {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return domain.WebhookSubscription{}, fmt.Errorf("create subscription begin tx: %w", err)
+	}
+	defer func() {
+		_ = tx.Rollback()
+	}()
+
+	err = tx.QueryRowContext(ctx, `
+        INSERT INTO webhook_subscriptions (url, secret, created_at)
+        VALUES ($1, $2, $3)
+        RETURNING subscription_id
+    `, sub.URL, sub.Secret, sub.CreatedAt).Scan(&sub.ID)
+	if err != nil {
+		return domain.WebhookSubscription{}, fmt.Errorf("insert subscription: %w", err)
+	}
+
+	for _, t := range sub.Events {
+		if _, err := tx.ExecContext(ctx, `
+            INSERT INTO webhook_subscription_events (subscription_id, event_type)
+            VALUES ($1, $2)
+            ON CONFLICT DO NOTHING
+        `, sub.ID, string(t)); err != nil {
+			return domain.WebhookSubscription{}, fmt.Errorf("insert subscription event %s: %w", t, err)
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return domain.WebhookSubscription{}, fmt.Errorf("create subscription commit: %w", err)
+	}
+
+	return sub, nil
+}
+
+func (r *WebhookRepo) ListSubscriptions(ctx context.Context) ([]domain.WebhookSubscription, error) {
+	rows, err := r.db.QueryContext(ctx, `
+        SELECT s.subscription_id, s.url, s.secret, s.created_at, e.event_type
+        FROM webhook_subscriptions s
+        LEFT JOIN webhook_subscription_events e ON e.subscription_id = s.subscription_id
+        ORDER BY s.subscription_id, e.event_type
+    `)
+	if err != nil {
+		return nil, fmt.Errorf("list subscriptions: %w", err)
+	}
+	defer func() {
+		_ = rows.Close()
+	}()
+
+	var res []domain.WebhookSubscription
+	for rows.Next() {
+		var sub domain.WebhookSubscription
+		var eventType sql.NullString
+		if err := rows.Scan(&sub.ID, &sub.URL, &sub.Secret, &sub.CreatedAt, &eventType); err != nil {
+			return nil, fmt.Errorf("scan subscription: %w", err)
+		}
+		if n := len(res); n == 0 || res[n-1].ID != sub.ID {
+			res = append(res, sub)
+		}
+		if eventType.Valid {
+			last := &res[len(res)-1]
+			last.Events = append(last.Events, domain.OutboxEventType(eventType.String))
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("iterate subscriptions: %w", err)
+	}
+
+	return res, nil
+}
+
+func (r *WebhookRepo) DeleteSubscription(ctx context.Context, id int64) error {
+	res, err := r.db.ExecContext(ctx, `
+        DELETE FROM webhook_subscriptions
+        WHERE subscription_id = $1
+    `, id)
+	if err != nil {
+		return fmt.Errorf("delete subscription: %w", err)
+	}
+
+	n, err := res.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("delete subscription rows affected: %w", err)
+	}
+	if n == 0 {
+		return domain.ErrNotFound
+	}
+	return nil
+}
+
+func (r *WebhookRepo) EnqueueDeliveries(ctx context.Context, limit int) (int, error) {
+	var n int
+	err := r.db.QueryRowContext(ctx, `
+        WITH batch AS (
+            SELECT event_id, event_type
+            FROM outbox_events
+            WHERE dispatched_at IS NULL
+            ORDER BY event_id
+            LIMIT $1
+            FOR UPDATE SKIP LOCKED
+        ),
+        fanout AS (
+            INSERT INTO webhook_deliveries (subscription_id, event_id, status, next_attempt_at)
+            SELECT s.subscription_id, b.event_id, 'PENDING', now()
+            FROM batch b
+            JOIN webhook_subscriptions s ON
+                NOT EXISTS (
+                    SELECT 1 FROM webhook_subscription_events f
+                    WHERE f.subscription_id = s.subscription_id
+                )
+                OR EXISTS (
+                    SELECT 1 FROM webhook_subscription_events f
+                    WHERE f.subscription_id = s.subscription_id
+                      AND f.event_type = b.event_type
+                )
+            ON CONFLICT DO NOTHING
+        ),
+        done AS (
+            UPDATE outbox_events
+            SET dispatched_at = now()
+            WHERE event_id IN (SELECT event_id FROM batch)
+            RETURNING 1
+        )
+        SELECT COUNT(*) FROM done
+    `, limit).Scan(&n)
+	if err != nil {
+		return 0, fmt.Errorf("enqueue deliveries: %w", err)
+	}
+	return n, nil
+}
+
+func (r *WebhookRepo) ClaimDueDeliveries(
+	ctx context.Context,
+	now, leaseUntil time.Time,
+	limit int,
+) ([]domain.WebhookDelivery, error) {
+	rows, err := r.db.QueryContext(ctx, `
+        UPDATE webhook_deliveries d
+        SET next_attempt_at = $2
+        FROM webhook_subscriptions s, outbox_events e
+        WHERE d.delivery_id IN (
+                SELECT delivery_id
+                FROM webhook_deliveries
+                WHERE status = 'PENDING' AND next_attempt_at <= $1
+                ORDER BY next_attempt_at
+                LIMIT $3
+                FOR UPDATE SKIP LOCKED
+            )
+          AND s.subscription_id = d.subscription_id
+          AND e.event_id = d.event_id
+        RETURNING d.delivery_id, d.subscription_id, s.url, s.secret, d.attempts,
+                  e.event_id, e.event_type, e.payload, e.created_at
+    `, now, leaseUntil, limit)
+	if err != nil {
+		return nil, fmt.Errorf("claim deliveries: %w", err)
+	}
+	defer func() {
+		_ = rows.Close()
+	}()
+
+	var res []domain.WebhookDelivery
+	for rows.Next() {
+		var d domain.WebhookDelivery
+		var eventType, payload string
+		if err := rows.Scan(
+			&d.ID, &d.SubscriptionID, &d.URL, &d.Secret, &d.Attempts,
+			&d.Event.ID, &eventType, &payload, &d.Event.CreatedAt,
+		); err != nil {
+			return nil, fmt.Errorf("scan delivery: %w", err)
+		}
+		d.Status = domain.DeliveryPending
+		d.NextAttemptAt = leaseUntil
+		d.Event.Type = domain.OutboxEventType(eventType)
+		d.Event.Payload = []byte(payload)
+		res = append(res, d)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("iterate deliveries: %w", err)
+	}
+
+	return res, nil
+}
+
+func (r *WebhookRepo) MarkDelivered(ctx context.Context, id int64) error {
+	_, err := r.db.ExecContext(ctx, `
+        UPDATE webhook_deliveries
+        SET status = 'DELIVERED',
+            attempts = attempts + 1,
+            last_error = ''
+        WHERE delivery_id = $1
+    `, id)
+	if err != nil {
+		return fmt.Errorf("mark delivered: %w", err)
+	}
+	return nil
+}
+
+func (r *WebhookRepo) MarkFailed(
+	ctx context.Context,
+	id int64,
+	attempts int,
+	nextAttemptAt time.Time,
+	lastError string,
+	dead bool,
+) error {
+	status := domain.DeliveryPending
+	if dead {
+		status = domain.DeliveryDead
+	}
+
+	_, err := r.db.ExecContext(ctx, `
+        UPDATE webhook_deliveries
+        SET status = $2,
+            attempts = $3,
+            next_attempt_at = $4,
+            last_error = $5
+        WHERE delivery_id = $1
+    `, id, string(status), attempts, nextAttemptAt, lastError)
+	if err != nil {
+		return fmt.Errorf("mark failed: %w", err)
+	}
+	return nil
+}
+
+func (r *WebhookRepo) ListDeadLetters(ctx context.Context) ([]domain.WebhookDelivery, error) {
+	rows, err := r.db.QueryContext(ctx, `
+        SELECT d.delivery_id, d.subscription_id, s.url, d.attempts, d.next_attempt_at, d.last_error,
+               e.event_id, e.event_type, e.payload, e.created_at
+        FROM webhook_deliveries d
+        JOIN webhook_subscriptions s ON s.subscription_id = d.subscription_id
+        JOIN outbox_events e ON e.event_id = d.event_id
+        WHERE d.status = 'DEAD'
+        ORDER BY d.delivery_id
+    `)
+	if err != nil {
+		return nil, fmt.Errorf("list dead letters: %w", err)
+	}
+	defer func() {
+		_ = rows.Close()
+	}()
+
+	var res []domain.WebhookDelivery
+	for rows.Next() {
+		var d domain.WebhookDelivery
+		var eventType, payload string
+		if err := rows.Scan(
+			&d.ID, &d.SubscriptionID, &d.URL, &d.Attempts, &d.NextAttemptAt, &d.LastError,
+			&d.Event.ID, &eventType, &payload, &d.Event.CreatedAt,
+		); err != nil {
+			return nil, fmt.Errorf("scan dead letter: %w", err)
+		}
+		d.Status = domain.DeliveryDead
+		d.Event.Type = domain.OutboxEventType(eventType)
+		d.Event.Payload = []byte(payload)
+		res = append(res, d)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("iterate dead letters: %w", err)
+	}
+
+	return res, nil
+}
+
+func (r *WebhookRepo) Redrive(ctx context.Context, id int64, at time.Time) error {
+	res, err := r.db.ExecContext(ctx, `
+        UPDATE webhook_deliveries
+        SET status = 'PENDING',
+            attempts = 0,
+            next_attempt_at = $2
+        WHERE delivery_id = $1 AND status = 'DEAD'
+    `, id, at)
+	if err != nil {
+		return fmt.Errorf("redrive delivery: %w", err)
+	}
+
+	n, err := res.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("redrive rows affected: %w", err)
+	}
+	if n == 0 {
+		return domain.ErrNotFound
+	}
+	return nil
+}
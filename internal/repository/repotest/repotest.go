@@ -0,0 +1,661 @@
+// Package repotest holds the contract every repository backend must satisfy.
+// Backends run it from their own tests:
+//
+//	repotest.Run(t, func(t *testing.T) repotest.Backend { ... })
+package repotest
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"pr-reviewer-service/internal/domain"
+)
+
+// Backend is a freshly emptied set of repositories and a unit of work over
+// the same storage.
+type Backend struct {
+	Repos   domain.Repositories
+	Tx      domain.UnitOfWork
+	APIKeys domain.APIKeyRepository
+}
+
+func Run(t *testing.T, open func(t *testing.T) Backend) {
+	tests := []struct {
+		name string
+		fn   func(t *testing.T, b Backend)
+	}{
+		{"Teams", testTeams},
+		{"Settings", testSettings},
+		{"Codeowners", testCodeowners},
+		{"Users", testUsers},
+		{"Unavailability", testUnavailability},
+		{"PullRequests", testPullRequests},
+		{"Reviewers", testReviewers},
+		{"AssignmentEvents", testAssignmentEvents},
+		{"UnitOfWork", testUnitOfWork},
+		{"APIKeys", testAPIKeys},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.fn(t, open(t))
+		})
+	}
+}
+
+// fixedTime is truncated to microseconds, the precision postgres keeps.
+var fixedTime = time.Date(2025, 6, 1, 10, 0, 0, 0, time.UTC)
+
+func seed(t *testing.T, b Backend) {
+	t.Helper()
+	ctx := context.Background()
+
+	for _, name := range []domain.TeamName{"backend", "platform"} {
+		if err := b.Repos.Teams.CreateTeam(ctx, name); err != nil {
+			t.Fatalf("CreateTeam(%s): %v", name, err)
+		}
+	}
+	if err := b.Repos.Users.UpsertUsers(ctx, []domain.User{
+		{ID: "u1", Username: "Alice", TeamName: "backend", IsActive: true},
+		{ID: "u2", Username: "Bob", TeamName: "backend", IsActive: true},
+		{ID: "u3", Username: "Carol", TeamName: "backend", IsActive: true},
+		{ID: "u4", Username: "Dave", TeamName: "platform", IsActive: true},
+	}); err != nil {
+		t.Fatalf("UpsertUsers: %v", err)
+	}
+}
+
+func createPR(t *testing.T, b Backend, id domain.PullRequestID, createdAt time.Time, reviewers ...domain.UserID) {
+	t.Helper()
+	err := b.Repos.Prs.Create(context.Background(), domain.PullRequest{
+		ID:                id,
+		Name:              "PR " + string(id),
+		AuthorID:          "u1",
+		Status:            domain.PRStatusOpen,
+		AssignedReviewers: reviewers,
+		CreatedAt:         createdAt,
+	})
+	if err != nil {
+		t.Fatalf("Create(%s): %v", id, err)
+	}
+}
+
+func getPR(t *testing.T, b Backend, id domain.PullRequestID) domain.PullRequest {
+	t.Helper()
+	pr, err := b.Repos.Prs.Get(context.Background(), id)
+	if err != nil {
+		t.Fatalf("Get(%s): %v", id, err)
+	}
+	return pr
+}
+
+func equalIDs[T comparable](got, want []T) bool {
+	if len(got) != len(want) {
+		return false
+	}
+	for i := range got {
+		if got[i] != want[i] {
+			return false
+		}
+	}
+	return true
+}
+
+func testTeams(t *testing.T, b Backend) {
+	ctx := context.Background()
+	teams := b.Repos.Teams
+
+	if _, err := teams.GetTeam(ctx, "backend"); !errors.Is(err, domain.ErrNotFound) {
+		t.Fatalf("GetTeam on unknown team: expected ErrNotFound, got %v", err)
+	}
+	if ok, err := teams.TeamExists(ctx, "backend"); err != nil || ok {
+		t.Fatalf("TeamExists on unknown team = %v, %v", ok, err)
+	}
+
+	seed(t, b)
+	if err := teams.CreateTeam(ctx, "backend"); err != nil {
+		t.Fatalf("CreateTeam on existing team must be a no-op, got %v", err)
+	}
+
+	names, err := teams.ListTeams(ctx)
+	if err != nil {
+		t.Fatalf("ListTeams: %v", err)
+	}
+	if !equalIDs(names, []domain.TeamName{"backend", "platform"}) {
+		t.Fatalf("ListTeams = %v", names)
+	}
+
+	team, err := teams.GetTeam(ctx, "backend")
+	if err != nil {
+		t.Fatalf("GetTeam: %v", err)
+	}
+	var ids []domain.UserID
+	for _, m := range team.Members {
+		ids = append(ids, m.ID)
+		if m.TeamName != "backend" {
+			t.Fatalf("member %s has team %q", m.ID, m.TeamName)
+		}
+	}
+	if team.Name != "backend" || !equalIDs(ids, []domain.UserID{"u1", "u2", "u3"}) {
+		t.Fatalf("GetTeam = %+v", team)
+	}
+}
+
+func testSettings(t *testing.T, b Backend) {
+	ctx := context.Background()
+	teams := b.Repos.Teams
+
+	if _, err := teams.GetSettings(ctx, "backend"); !errors.Is(err, domain.ErrNotFound) {
+		t.Fatalf("GetSettings on unknown team: expected ErrNotFound, got %v", err)
+	}
+
+	seed(t, b)
+	got, err := teams.GetSettings(ctx, "backend")
+	if err != nil {
+		t.Fatalf("GetSettings: %v", err)
+	}
+	def := domain.DefaultTeamSettings("backend")
+	if got.ReviewersCount != def.ReviewersCount || got.Strategy != def.Strategy || len(got.FallbackTeams) != 0 {
+		t.Fatalf("expected defaults, got %+v", got)
+	}
+
+	want := domain.TeamSettings{
+		TeamName:          "backend",
+		ReviewersCount:    3,
+		Strategy:          domain.ReviewerStrategyLeastLoaded,
+		MinPoolSize:       1,
+		FallbackTeams:     []domain.TeamName{"platform"},
+		SlackWebhookURL:   "https://hooks.example.com/x",
+		RequiredApprovals: 2,
+	}
+	if err := teams.UpsertSettings(ctx, want); err != nil {
+		t.Fatalf("UpsertSettings: %v", err)
+	}
+	got, err = teams.GetSettings(ctx, "backend")
+	if err != nil {
+		t.Fatalf("GetSettings: %v", err)
+	}
+	if got.ReviewersCount != 3 || got.Strategy != want.Strategy || got.MinPoolSize != 1 ||
+		got.SlackWebhookURL != want.SlackWebhookURL || got.RequiredApprovals != 2 ||
+		!equalIDs(got.FallbackTeams, want.FallbackTeams) {
+		t.Fatalf("GetSettings = %+v, want %+v", got, want)
+	}
+
+	want.FallbackTeams = nil
+	if err := teams.UpsertSettings(ctx, want); err != nil {
+		t.Fatalf("UpsertSettings: %v", err)
+	}
+	if got, _ := teams.GetSettings(ctx, "backend"); len(got.FallbackTeams) != 0 {
+		t.Fatalf("fallback teams must be replaced, got %v", got.FallbackTeams)
+	}
+
+	if err := teams.UpsertSettings(ctx, domain.TeamSettings{
+		TeamName: "backend", Strategy: domain.ReviewerStrategyRandom, FallbackTeams: []domain.TeamName{"unknown"},
+	}); err == nil {
+		t.Fatalf("UpsertSettings must reject unknown fallback teams")
+	}
+}
+
+func testCodeowners(t *testing.T, b Backend) {
+	ctx := context.Background()
+	teams := b.Repos.Teams
+	seed(t, b)
+
+	if _, err := teams.GetCodeowners(ctx, "backend"); !errors.Is(err, domain.ErrNotFound) {
+		t.Fatalf("GetCodeowners: expected ErrNotFound, got %v", err)
+	}
+
+	for _, content := range []string{"/api/ @u2", "/api/ @u3"} {
+		if err := teams.PutCodeowners(ctx, domain.Codeowners{TeamName: "backend", Content: content, UpdatedAt: fixedTime}); err != nil {
+			t.Fatalf("PutCodeowners: %v", err)
+		}
+	}
+	got, err := teams.GetCodeowners(ctx, "backend")
+	if err != nil {
+		t.Fatalf("GetCodeowners: %v", err)
+	}
+	if got.Content != "/api/ @u3" || !got.UpdatedAt.Equal(fixedTime) {
+		t.Fatalf("GetCodeowners = %+v", got)
+	}
+}
+
+func testUsers(t *testing.T, b Backend) {
+	ctx := context.Background()
+	users := b.Repos.Users
+
+	if err := users.UpsertUsers(ctx, []domain.User{{ID: "u1", Username: "Alice", TeamName: "nope"}}); err == nil {
+		t.Fatalf("UpsertUsers must reject unknown teams")
+	}
+
+	seed(t, b)
+	if _, err := users.GetByID(ctx, "nope"); !errors.Is(err, domain.ErrNotFound) {
+		t.Fatalf("GetByID on unknown user: expected ErrNotFound, got %v", err)
+	}
+
+	if err := users.UpsertUsers(ctx, []domain.User{
+		{ID: "u3", Username: "Caroline", TeamName: "platform", IsActive: true, SlackHandle: "@caro", MaxOpenReviews: 4},
+	}); err != nil {
+		t.Fatalf("UpsertUsers: %v", err)
+	}
+	u, err := users.GetByID(ctx, "u3")
+	if err != nil {
+		t.Fatalf("GetByID: %v", err)
+	}
+	want := domain.User{ID: "u3", Username: "Caroline", TeamName: "platform", IsActive: true, SlackHandle: "@caro", MaxOpenReviews: 4}
+	if u != want {
+		t.Fatalf("GetByID = %+v, want %+v", u, want)
+	}
+
+	if u, err = users.SetIsActive(ctx, "u2", false, "lead"); err != nil || u.IsActive {
+		t.Fatalf("SetIsActive = %+v, %v", u, err)
+	}
+	changes, err := users.ListStatusChanges(ctx, "u2")
+	if err != nil {
+		t.Fatalf("ListStatusChanges: %v", err)
+	}
+	if len(changes) != 1 || changes[0].UserID != "u2" || changes[0].IsActive || changes[0].Actor != "lead" || changes[0].ChangedAt.IsZero() {
+		t.Fatalf("unexpected status changes: %+v", changes)
+	}
+	if u, err = users.SetMaxOpenReviews(ctx, "u1", 2); err != nil || u.MaxOpenReviews != 2 {
+		t.Fatalf("SetMaxOpenReviews = %+v, %v", u, err)
+	}
+	if _, err := users.SetIsActive(ctx, "nope", true, "lead"); !errors.Is(err, domain.ErrNotFound) {
+		t.Fatalf("SetIsActive on unknown user: expected ErrNotFound, got %v", err)
+	}
+	if _, err := users.SetMaxOpenReviews(ctx, "nope", 1); !errors.Is(err, domain.ErrNotFound) {
+		t.Fatalf("SetMaxOpenReviews on unknown user: expected ErrNotFound, got %v", err)
+	}
+
+	active, err := users.ListActiveByTeam(ctx, "backend")
+	if err != nil {
+		t.Fatalf("ListActiveByTeam: %v", err)
+	}
+	if len(active) != 1 || active[0].ID != "u1" || active[0].MaxOpenReviews != 2 {
+		t.Fatalf("ListActiveByTeam = %+v", active)
+	}
+}
+
+func testUnavailability(t *testing.T, b Backend) {
+	ctx := context.Background()
+	users := b.Repos.Users
+	seed(t, b)
+
+	now := time.Now().UTC().Truncate(time.Microsecond)
+	current, err := users.AddUnavailability(ctx, domain.Unavailability{
+		UserID: "u2", StartsAt: now.Add(-time.Hour), EndsAt: now.Add(time.Hour), Reason: "vacation", ReassignOpenReviews: true,
+	})
+	if err != nil {
+		t.Fatalf("AddUnavailability: %v", err)
+	}
+	future, err := users.AddUnavailability(ctx, domain.Unavailability{
+		UserID: "u2", StartsAt: now.Add(24 * time.Hour), EndsAt: now.Add(48 * time.Hour), ReassignOpenReviews: true,
+	})
+	if err != nil {
+		t.Fatalf("AddUnavailability: %v", err)
+	}
+	if current.ID == 0 || future.ID == current.ID {
+		t.Fatalf("periods need distinct ids, got %d and %d", current.ID, future.ID)
+	}
+	if _, err := users.AddUnavailability(ctx, domain.Unavailability{
+		UserID: "nope", StartsAt: now, EndsAt: now.Add(time.Hour),
+	}); err == nil {
+		t.Fatalf("AddUnavailability must reject unknown users")
+	}
+
+	periods, err := users.ListUnavailability(ctx, "u2")
+	if err != nil {
+		t.Fatalf("ListUnavailability: %v", err)
+	}
+	if len(periods) != 2 || periods[0].ID != current.ID || periods[0].Reason != "vacation" || !periods[0].StartsAt.Equal(current.StartsAt) {
+		t.Fatalf("ListUnavailability = %+v", periods)
+	}
+
+	got, err := users.GetUnavailability(ctx, future.ID)
+	if err != nil {
+		t.Fatalf("GetUnavailability: %v", err)
+	}
+	if got.UserID != "u2" || !got.EndsAt.Equal(future.EndsAt) {
+		t.Fatalf("GetUnavailability = %+v", got)
+	}
+	if _, err := users.GetUnavailability(ctx, future.ID+100); !errors.Is(err, domain.ErrNotFound) {
+		t.Fatalf("GetUnavailability unknown: expected ErrNotFound, got %v", err)
+	}
+
+	active, err := users.ListActiveByTeam(ctx, "backend")
+	if err != nil {
+		t.Fatalf("ListActiveByTeam: %v", err)
+	}
+	for _, u := range active {
+		if u.ID == "u2" {
+			t.Fatalf("unavailable user must not be listed as active")
+		}
+	}
+
+	due, err := users.ListDueReassignments(ctx, now)
+	if err != nil {
+		t.Fatalf("ListDueReassignments: %v", err)
+	}
+	if len(due) != 1 || due[0].ID != current.ID {
+		t.Fatalf("ListDueReassignments = %+v", due)
+	}
+	if err := users.MarkReassigned(ctx, current.ID, now); err != nil {
+		t.Fatalf("MarkReassigned: %v", err)
+	}
+	if due, _ := users.ListDueReassignments(ctx, now); len(due) != 0 {
+		t.Fatalf("reassigned period must not be due again, got %+v", due)
+	}
+
+	if err := users.DeleteUnavailability(ctx, future.ID); err != nil {
+		t.Fatalf("DeleteUnavailability: %v", err)
+	}
+	if err := users.DeleteUnavailability(ctx, future.ID); !errors.Is(err, domain.ErrNotFound) {
+		t.Fatalf("DeleteUnavailability twice: expected ErrNotFound, got %v", err)
+	}
+	if periods, _ := users.ListUnavailability(ctx, "u2"); len(periods) != 1 || periods[0].ReassignedAt == nil {
+		t.Fatalf("ListUnavailability after delete = %+v", periods)
+	}
+}
+
+func testPullRequests(t *testing.T, b Backend) {
+	ctx := context.Background()
+	prs := b.Repos.Prs
+	seed(t, b)
+
+	if _, err := prs.Get(ctx, "pr-1"); !errors.Is(err, domain.ErrNotFound) {
+		t.Fatalf("Get on unknown PR: expected ErrNotFound, got %v", err)
+	}
+
+	createPR(t, b, "pr-2", fixedTime.Add(time.Hour), "u3")
+	createPR(t, b, "pr-1", fixedTime, "u3", "u2")
+	if err := prs.Create(ctx, domain.PullRequest{ID: "pr-1", Name: "dup", AuthorID: "u1", Status: domain.PRStatusOpen, CreatedAt: fixedTime}); !errors.Is(err, domain.ErrPullRequestExists) {
+		t.Fatalf("Create duplicate: expected ErrPullRequestExists, got %v", err)
+	}
+	if err := prs.Create(ctx, domain.PullRequest{ID: "pr-x", Name: "x", AuthorID: "nope", Status: domain.PRStatusOpen, CreatedAt: fixedTime}); err == nil {
+		t.Fatalf("Create must reject unknown authors")
+	}
+	if ok, err := prs.Exists(ctx, "pr-1"); err != nil || !ok {
+		t.Fatalf("Exists = %v, %v", ok, err)
+	}
+
+	pr := getPR(t, b, "pr-1")
+	if pr.Name != "PR pr-1" || pr.AuthorID != "u1" || pr.Status != domain.PRStatusOpen ||
+		!pr.CreatedAt.Equal(fixedTime) || pr.MergedAt != nil || pr.ClosedAt != nil {
+		t.Fatalf("Get = %+v", pr)
+	}
+	if !equalIDs(pr.AssignedReviewers, []domain.UserID{"u2", "u3"}) {
+		t.Fatalf("reviewers must be ordered by user id, got %v", pr.AssignedReviewers)
+	}
+
+	list, err := prs.List(ctx)
+	if err != nil {
+		t.Fatalf("List: %v", err)
+	}
+	if len(list) != 2 || list[0].ID != "pr-1" || list[1].ID != "pr-2" || len(list[0].AssignedReviewers) != 2 {
+		t.Fatalf("List = %+v", list)
+	}
+
+	short, err := prs.ListByReviewer(ctx, "u3")
+	if err != nil {
+		t.Fatalf("ListByReviewer: %v", err)
+	}
+	if len(short) != 2 || short[0].ID != "pr-2" || short[1].ID != "pr-1" {
+		t.Fatalf("ListByReviewer must put newest first, got %+v", short)
+	}
+
+	closedAt := fixedTime.Add(2 * time.Hour)
+	if err := prs.SetStatus(ctx, "pr-2", domain.PRStatusClosed, &closedAt); err != nil {
+		t.Fatalf("SetStatus: %v", err)
+	}
+	if pr := getPR(t, b, "pr-2"); pr.Status != domain.PRStatusClosed || pr.ClosedAt == nil || !pr.ClosedAt.Equal(closedAt) ||
+		pr.ClosedFrom != domain.PRStatusOpen {
+		t.Fatalf("SetStatus not stored: %+v", pr)
+	}
+	if err := prs.SetStatus(ctx, "pr-2", domain.PRStatusOpen, nil); err != nil {
+		t.Fatalf("SetStatus: %v", err)
+	}
+	if pr := getPR(t, b, "pr-2"); pr.Status != domain.PRStatusOpen || pr.ClosedAt != nil || pr.ClosedFrom != "" {
+		t.Fatalf("SetStatus must clear closed_at and closed_from: %+v", pr)
+	}
+
+	mergedAt := fixedTime.Add(3 * time.Hour)
+	if err := prs.MarkMerged(ctx, "pr-1", mergedAt); err != nil {
+		t.Fatalf("MarkMerged: %v", err)
+	}
+	if pr := getPR(t, b, "pr-1"); pr.Status != domain.PRStatusMerged || pr.MergedAt == nil || !pr.MergedAt.Equal(mergedAt) {
+		t.Fatalf("MarkMerged not stored: %+v", pr)
+	}
+
+	stats, err := prs.StatsAssignmentsByUser(ctx)
+	if err != nil {
+		t.Fatalf("StatsAssignmentsByUser: %v", err)
+	}
+	if stats["u2"] != 1 || stats["u3"] != 2 || len(stats) != 2 {
+		t.Fatalf("StatsAssignmentsByUser = %v", stats)
+	}
+	open, err := prs.OpenAssignmentsByUser(ctx)
+	if err != nil {
+		t.Fatalf("OpenAssignmentsByUser: %v", err)
+	}
+	if open["u3"] != 1 || len(open) != 1 {
+		t.Fatalf("OpenAssignmentsByUser = %v", open)
+	}
+	byTeam, err := prs.OpenByTeam(ctx)
+	if err != nil {
+		t.Fatalf("OpenByTeam: %v", err)
+	}
+	if byTeam["backend"] != 1 || len(byTeam) != 1 {
+		t.Fatalf("OpenByTeam = %v", byTeam)
+	}
+}
+
+func testReviewers(t *testing.T, b Backend) {
+	ctx := context.Background()
+	prs := b.Repos.Prs
+	seed(t, b)
+
+	createPR(t, b, "pr-1", fixedTime)
+
+	if err := prs.AddReviewers(ctx, domain.PullRequest{
+		ID:                "pr-1",
+		AssignedReviewers: []domain.UserID{"u4", "u2"},
+		FallbackReviewers: map[domain.UserID]domain.TeamName{"u4": "platform"},
+		MatchedRules:      map[domain.UserID]string{"u2": "/api/"},
+	}); err != nil {
+		t.Fatalf("AddReviewers: %v", err)
+	}
+	pr := getPR(t, b, "pr-1")
+	if !equalIDs(pr.AssignedReviewers, []domain.UserID{"u2", "u4"}) ||
+		pr.FallbackReviewers["u4"] != "platform" || pr.MatchedRules["u2"] != "/api/" {
+		t.Fatalf("AddReviewers not stored: %+v", pr)
+	}
+	if err := prs.AddReviewers(ctx, domain.PullRequest{ID: "pr-1", AssignedReviewers: []domain.UserID{"u2"}}); err == nil {
+		t.Fatalf("AddReviewers must reject reviewers already assigned")
+	}
+	if err := prs.AddReviewers(ctx, domain.PullRequest{ID: "pr-1", AssignedReviewers: []domain.UserID{"nope"}}); err == nil {
+		t.Fatalf("AddReviewers must reject unknown users")
+	}
+
+	if err := prs.SetReviewState(ctx, "pr-1", "u2", domain.ReviewStateApproved); err != nil {
+		t.Fatalf("SetReviewState: %v", err)
+	}
+	if pr := getPR(t, b, "pr-1"); pr.ReviewState("u2") != domain.ReviewStateApproved || pr.ReviewState("u4") != domain.ReviewStatePending {
+		t.Fatalf("review states = %v", pr.ReviewStates)
+	}
+	if err := prs.SetReviewState(ctx, "pr-1", "u3", domain.ReviewStateApproved); !errors.Is(err, domain.ErrNotAssigned) {
+		t.Fatalf("SetReviewState for non-reviewer: expected ErrNotAssigned, got %v", err)
+	}
+
+	if added, err := prs.ReplaceReviewer(ctx, "pr-1", "u4", "u3", ""); err != nil || !added {
+		t.Fatalf("ReplaceReviewer = %v, %v", added, err)
+	}
+	pr = getPR(t, b, "pr-1")
+	if !equalIDs(pr.AssignedReviewers, []domain.UserID{"u2", "u3"}) || pr.FallbackReviewers["u4"] != "" || pr.FallbackReviewers["u3"] != "" {
+		t.Fatalf("ReplaceReviewer without fallback: %+v", pr)
+	}
+
+	if added, err := prs.ReplaceReviewer(ctx, "pr-1", "u2", "u4", "platform"); err != nil || !added {
+		t.Fatalf("ReplaceReviewer = %v, %v", added, err)
+	}
+	pr = getPR(t, b, "pr-1")
+	if !equalIDs(pr.AssignedReviewers, []domain.UserID{"u3", "u4"}) || pr.FallbackReviewers["u4"] != "platform" {
+		t.Fatalf("ReplaceReviewer with fallback: %+v", pr)
+	}
+	if pr.ReviewState("u4") != domain.ReviewStatePending || pr.MatchedRules["u4"] != "" {
+		t.Fatalf("replacement must start from a clean review: %+v", pr)
+	}
+
+	if _, err := prs.ReplaceReviewer(ctx, "pr-1", "u1", "u2", ""); !errors.Is(err, domain.ErrNotAssigned) {
+		t.Fatalf("ReplaceReviewer for non-reviewer: expected ErrNotAssigned, got %v", err)
+	}
+
+	if added, err := prs.ReplaceReviewer(ctx, "pr-1", "u3", "u4", ""); err != nil || added {
+		t.Fatalf("ReplaceReviewer with an assigned successor = %v, %v", added, err)
+	}
+	pr = getPR(t, b, "pr-1")
+	if !equalIDs(pr.AssignedReviewers, []domain.UserID{"u4"}) || pr.FallbackReviewers["u4"] != "platform" {
+		t.Fatalf("ReplaceReviewer with an assigned successor must only remove the old reviewer: %+v", pr)
+	}
+}
+
+func testAssignmentEvents(t *testing.T, b Backend) {
+	ctx := context.Background()
+	prs := b.Repos.Prs
+	seed(t, b)
+	createPR(t, b, "pr-1", fixedTime, "u2")
+	createPR(t, b, "pr-2", fixedTime, "u2")
+
+	if err := prs.AppendAssignmentEvents(ctx, []domain.AssignmentEvent{
+		{PullRequestID: "pr-1", Type: domain.AssignmentEventAssigned, UserID: "u2", Reason: domain.AssignmentReasonCreate, CreatedAt: fixedTime},
+		{PullRequestID: "pr-2", Type: domain.AssignmentEventAssigned, UserID: "u2", Reason: domain.AssignmentReasonCreate, CreatedAt: fixedTime},
+		{PullRequestID: "pr-1", Type: domain.AssignmentEventReplaced, UserID: "u2", ReplacedBy: "u3", Actor: "u1", Reason: domain.AssignmentReasonManualReassign, CreatedAt: fixedTime},
+	}); err != nil {
+		t.Fatalf("AppendAssignmentEvents: %v", err)
+	}
+
+	events, err := prs.ListAssignmentEvents(ctx, "pr-1")
+	if err != nil {
+		t.Fatalf("ListAssignmentEvents: %v", err)
+	}
+	if len(events) != 2 || events[0].ID >= events[1].ID {
+		t.Fatalf("ListAssignmentEvents = %+v", events)
+	}
+	last := events[1]
+	if last.PullRequestID != "pr-1" || last.Type != domain.AssignmentEventReplaced || last.ReplacedBy != "u3" ||
+		last.Actor != "u1" || last.Reason != domain.AssignmentReasonManualReassign || !last.CreatedAt.Equal(fixedTime) {
+		t.Fatalf("event not stored: %+v", last)
+	}
+}
+
+func testUnitOfWork(t *testing.T, b Backend) {
+	ctx := context.Background()
+	seed(t, b)
+
+	errBoom := errors.New("boom")
+	err := b.Tx.Do(ctx, func(ctx context.Context, repos domain.Repositories) error {
+		if err := repos.Teams.CreateTeam(ctx, "frontend"); err != nil {
+			return err
+		}
+		if _, err := repos.Users.SetIsActive(ctx, "u1", false, ""); err != nil {
+			return err
+		}
+		if ok, err := repos.Teams.TeamExists(ctx, "frontend"); err != nil || !ok {
+			t.Fatalf("writes must be visible inside the unit of work, got %v, %v", ok, err)
+		}
+		return errBoom
+	})
+	if !errors.Is(err, errBoom) {
+		t.Fatalf("Do must return fn's error, got %v", err)
+	}
+	if ok, _ := b.Repos.Teams.TeamExists(ctx, "frontend"); ok {
+		t.Fatalf("failed unit of work must be rolled back")
+	}
+	if u, _ := b.Repos.Users.GetByID(ctx, "u1"); !u.IsActive {
+		t.Fatalf("failed unit of work must be rolled back")
+	}
+	if changes, _ := b.Repos.Users.ListStatusChanges(ctx, "u1"); len(changes) != 0 {
+		t.Fatalf("failed unit of work must not keep status changes, got %+v", changes)
+	}
+
+	err = b.Tx.Do(ctx, func(ctx context.Context, repos domain.Repositories) error {
+		if err := repos.Teams.CreateTeam(ctx, "frontend"); err != nil {
+			return err
+		}
+		return repos.Prs.Create(ctx, domain.PullRequest{
+			ID: "pr-1", Name: "PR", AuthorID: "u1", Status: domain.PRStatusOpen, CreatedAt: fixedTime,
+			AssignedReviewers: []domain.UserID{"u2"},
+		})
+	})
+	if err != nil {
+		t.Fatalf("Do: %v", err)
+	}
+	if ok, _ := b.Repos.Teams.TeamExists(ctx, "frontend"); !ok {
+		t.Fatalf("committed unit of work must be visible")
+	}
+	if pr := getPR(t, b, "pr-1"); !equalIDs(pr.AssignedReviewers, []domain.UserID{"u2"}) {
+		t.Fatalf("committed PR = %+v", pr)
+	}
+}
+
+func testAPIKeys(t *testing.T, b Backend) {
+	seed(t, b)
+	ctx := context.Background()
+	keys := b.APIKeys
+
+	admin, err := keys.Create(ctx, domain.APIKey{
+		Name: "ops", Role: domain.RoleAdmin, Hash: "hash-admin", CreatedAt: fixedTime,
+	})
+	if err != nil {
+		t.Fatalf("Create admin: %v", err)
+	}
+	lead, err := keys.Create(ctx, domain.APIKey{
+		Name: "backend lead", Role: domain.RoleTeamLead, TeamName: "backend", Hash: "hash-lead", CreatedAt: fixedTime,
+	})
+	if err != nil {
+		t.Fatalf("Create lead: %v", err)
+	}
+	if admin.ID == 0 || lead.ID == admin.ID {
+		t.Fatalf("ids = %d, %d", admin.ID, lead.ID)
+	}
+	if _, err := keys.Create(ctx, domain.APIKey{
+		Name: "dup", Role: domain.RoleReadOnly, Hash: "hash-admin", CreatedAt: fixedTime,
+	}); err == nil {
+		t.Fatalf("Create must reject a duplicate hash")
+	}
+	if _, err := keys.Create(ctx, domain.APIKey{
+		Name: "ghost", Role: domain.RoleTeamLead, TeamName: "ghost", Hash: "hash-ghost", CreatedAt: fixedTime,
+	}); err == nil {
+		t.Fatalf("Create must reject unknown teams")
+	}
+
+	got, err := keys.GetByHash(ctx, "hash-lead")
+	if err != nil {
+		t.Fatalf("GetByHash: %v", err)
+	}
+	if got.ID != lead.ID || got.Role != domain.RoleTeamLead || got.TeamName != "backend" || !got.CreatedAt.Equal(fixedTime) {
+		t.Fatalf("GetByHash = %+v", got)
+	}
+	if _, err := keys.GetByHash(ctx, "nope"); !errors.Is(err, domain.ErrNotFound) {
+		t.Fatalf("GetByHash unknown: expected ErrNotFound, got %v", err)
+	}
+
+	revokedAt := fixedTime.Add(time.Hour)
+	if err := keys.Revoke(ctx, lead.ID, revokedAt); err != nil {
+		t.Fatalf("Revoke: %v", err)
+	}
+	if err := keys.Revoke(ctx, lead.ID, revokedAt); !errors.Is(err, domain.ErrNotFound) {
+		t.Fatalf("Revoke twice: expected ErrNotFound, got %v", err)
+	}
+	if _, err := keys.GetByHash(ctx, "hash-lead"); !errors.Is(err, domain.ErrNotFound) {
+		t.Fatalf("GetByHash revoked: expected ErrNotFound, got %v", err)
+	}
+
+	all, err := keys.List(ctx)
+	if err != nil {
+		t.Fatalf("List: %v", err)
+	}
+	if len(all) != 2 || all[0].ID != admin.ID || all[0].RevokedAt != nil ||
+		all[1].RevokedAt == nil || !all[1].RevokedAt.Equal(revokedAt) {
+		t.Fatalf("List = %+v", all)
+	}
+}
@@ -0,0 +1,135 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"embed"
+	"fmt"
+	"io/fs"
+	"time"
+
+	"pr-reviewer-service/internal/migrations"
+)
+
+//go:embed migrations/*.sql
+var migrationFiles embed.FS
+
+func loadMigrations() ([]migrations.Migration, error) {
+	sub, err := fs.Sub(migrationFiles, "migrations")
+	if err != nil {
+		return nil, err
+	}
+	return migrations.LoadFS(sub)
+}
+
+// Migrate applies pending SQLite migrations in version order. Each runs in
+// its own immediate transaction, which also keeps two processes from
+// applying the same version.
+func Migrate(ctx context.Context, db *sql.DB) error {
+	all, err := loadMigrations()
+	if err != nil {
+		return err
+	}
+
+	if _, err := db.ExecContext(ctx, `
+        CREATE TABLE IF NOT EXISTS schema_migrations (
+            version    INTEGER PRIMARY KEY,
+            name       TEXT NOT NULL,
+            applied_at TEXT NOT NULL
+        )
+    `); err != nil {
+		return fmt.Errorf("create schema_migrations: %w", err)
+	}
+
+	for _, m := range all {
+		if err := applyMigration(ctx, db, m); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func applyMigration(ctx context.Context, db *sql.DB, m migrations.Migration) error {
+	tx, err := db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("migration %03d begin tx: %w", m.Version, err)
+	}
+	defer func() {
+		_ = tx.Rollback()
+	}()
+
+	var applied bool
+	if err := tx.QueryRowContext(ctx, `
+        SELECT EXISTS (SELECT 1 FROM schema_migrations WHERE version = ?)
+    `, m.Version).Scan(&applied); err != nil {
+		return fmt.Errorf("migration %03d check: %w", m.Version, err)
+	}
+	if applied {
+		return nil
+	}
+
+	if _, err := tx.ExecContext(ctx, m.SQL); err != nil {
+		return fmt.Errorf("migration %03d_%s: %w", m.Version, m.Name, err)
+	}
+	if _, err := tx.ExecContext(ctx, `
+        INSERT INTO schema_migrations (version, name, applied_at) VALUES (?, ?, ?)
+    `, m.Version, m.Name, formatTime(time.Now())); err != nil {
+		return fmt.Errorf("record migration %03d: %w", m.Version, err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("migration %03d commit: %w", m.Version, err)
+	}
+	return nil
+}
+
+// MigrationStatus reports every SQLite migration and whether it has been
+// applied, without changing the database.
+func MigrationStatus(ctx context.Context, db *sql.DB) ([]migrations.MigrationStatus, error) {
+	all, err := loadMigrations()
+	if err != nil {
+		return nil, err
+	}
+
+	var hasTable bool
+	if err := db.QueryRowContext(ctx, `
+        SELECT EXISTS (SELECT 1 FROM sqlite_master WHERE type = 'table' AND name = 'schema_migrations')
+    `).Scan(&hasTable); err != nil {
+		return nil, fmt.Errorf("look up schema_migrations: %w", err)
+	}
+
+	applied := map[int]time.Time{}
+	if hasTable {
+		rows, err := db.QueryContext(ctx, `SELECT version, applied_at FROM schema_migrations`)
+		if err != nil {
+			return nil, fmt.Errorf("list applied migrations: %w", err)
+		}
+		defer func() {
+			_ = rows.Close()
+		}()
+
+		for rows.Next() {
+			var version int
+			var at string
+			if err := rows.Scan(&version, &at); err != nil {
+				return nil, fmt.Errorf("scan applied migration: %w", err)
+			}
+			if applied[version], err = parseTime(at); err != nil {
+				return nil, err
+			}
+		}
+		if err := rows.Err(); err != nil {
+			return nil, fmt.Errorf("iterate applied migrations: %w", err)
+		}
+	}
+
+	res := make([]migrations.MigrationStatus, 0, len(all))
+	for _, m := range all {
+		s := migrations.MigrationStatus{Migration: m}
+		if at, ok := applied[m.Version]; ok {
+			s.AppliedAt = &at
+		}
+		res = append(res, s)
+	}
+	return res, nil
+}
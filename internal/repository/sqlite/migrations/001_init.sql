@@ -0,0 +1,86 @@
+CREATE TABLE IF NOT EXISTS teams (
+    team_name TEXT PRIMARY KEY
+);
+
+CREATE TABLE IF NOT EXISTS team_settings (
+    team_name          TEXT PRIMARY KEY REFERENCES teams(team_name) ON DELETE CASCADE,
+    reviewers_count    INTEGER NOT NULL DEFAULT 2 CHECK (reviewers_count >= 0),
+    strategy           TEXT    NOT NULL DEFAULT 'random' CHECK (strategy IN ('random', 'least_loaded')),
+    min_pool_size      INTEGER NOT NULL DEFAULT 0 CHECK (min_pool_size >= 0),
+    slack_webhook_url  TEXT    NOT NULL DEFAULT '',
+    required_approvals INTEGER NOT NULL DEFAULT 0 CHECK (required_approvals >= 0)
+);
+
+CREATE TABLE IF NOT EXISTS team_fallbacks (
+    team_name          TEXT    NOT NULL REFERENCES teams(team_name) ON DELETE CASCADE,
+    fallback_team_name TEXT    NOT NULL REFERENCES teams(team_name) ON DELETE CASCADE,
+    position           INTEGER NOT NULL,
+    PRIMARY KEY (team_name, fallback_team_name),
+    CHECK (team_name <> fallback_team_name)
+);
+
+CREATE TABLE IF NOT EXISTS team_codeowners (
+    team_name  TEXT PRIMARY KEY REFERENCES teams(team_name) ON DELETE CASCADE,
+    content    TEXT NOT NULL,
+    updated_at TEXT NOT NULL
+);
+
+CREATE TABLE IF NOT EXISTS users (
+    user_id          TEXT    PRIMARY KEY,
+    username         TEXT    NOT NULL,
+    team_name        TEXT    NOT NULL REFERENCES teams(team_name) ON DELETE RESTRICT,
+    is_active        INTEGER NOT NULL DEFAULT 1,
+    slack_handle     TEXT    NOT NULL DEFAULT '',
+    max_open_reviews INTEGER NOT NULL DEFAULT 0 CHECK (max_open_reviews >= 0)
+);
+
+CREATE INDEX IF NOT EXISTS idx_users_team_name ON users(team_name);
+
+CREATE TABLE IF NOT EXISTS user_unavailability (
+    unavailability_id     INTEGER PRIMARY KEY AUTOINCREMENT,
+    user_id               TEXT    NOT NULL REFERENCES users(user_id) ON DELETE CASCADE,
+    starts_at             TEXT    NOT NULL,
+    ends_at               TEXT    NOT NULL,
+    reason                TEXT    NOT NULL DEFAULT '',
+    reassign_open_reviews INTEGER NOT NULL DEFAULT 0,
+    reassigned_at         TEXT,
+    CHECK (ends_at > starts_at)
+);
+
+CREATE INDEX IF NOT EXISTS idx_user_unavailability_user ON user_unavailability(user_id, ends_at);
+
+CREATE TABLE IF NOT EXISTS pull_requests (
+    pull_request_id   TEXT PRIMARY KEY,
+    pull_request_name TEXT NOT NULL,
+    author_id         TEXT NOT NULL REFERENCES users(user_id),
+    status            TEXT NOT NULL CHECK (status IN ('DRAFT', 'OPEN', 'MERGED', 'CLOSED')),
+    created_at        TEXT NOT NULL,
+    merged_at         TEXT,
+    closed_at         TEXT
+);
+
+CREATE TABLE IF NOT EXISTS pull_request_reviewers (
+    pull_request_id TEXT NOT NULL REFERENCES pull_requests(pull_request_id) ON DELETE CASCADE,
+    user_id         TEXT NOT NULL REFERENCES users(user_id),
+    fallback_team   TEXT REFERENCES teams(team_name),
+    matched_rule    TEXT,
+    review_state    TEXT NOT NULL DEFAULT 'PENDING'
+        CHECK (review_state IN ('PENDING', 'APPROVED', 'CHANGES_REQUESTED', 'COMMENTED')),
+    PRIMARY KEY (pull_request_id, user_id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_pr_reviewers_user ON pull_request_reviewers(user_id);
+
+CREATE TABLE IF NOT EXISTS reviewer_assignment_events (
+    event_id        INTEGER PRIMARY KEY AUTOINCREMENT,
+    pull_request_id TEXT NOT NULL REFERENCES pull_requests(pull_request_id) ON DELETE CASCADE,
+    event_type      TEXT NOT NULL CHECK (event_type IN ('ASSIGNED', 'UNASSIGNED', 'REPLACED')),
+    user_id         TEXT NOT NULL REFERENCES users(user_id),
+    replaced_by     TEXT REFERENCES users(user_id),
+    actor_id        TEXT,
+    reason          TEXT NOT NULL
+        CHECK (reason IN ('create', 'manual_reassign', 'bulk_deactivation', 'unavailable', 'ready_for_review')),
+    created_at      TEXT NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS idx_assignment_events_pr ON reviewer_assignment_events(pull_request_id, event_id);
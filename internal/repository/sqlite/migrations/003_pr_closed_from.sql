@@ -0,0 +1,2 @@
+ALTER TABLE pull_requests
+    ADD COLUMN closed_from TEXT CHECK (closed_from IN ('DRAFT', 'OPEN'));
@@ -0,0 +1,631 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"pr-reviewer-service/internal/domain"
+)
+
+type PullRequestRepo struct {
+	db executor
+}
+
+func NewPullRequestRepo(db *sql.DB) *PullRequestRepo {
+	return &PullRequestRepo{db: executor{db: db}}
+}
+
+func (r *PullRequestRepo) Create(ctx context.Context, pr domain.PullRequest) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("create pr begin tx: %w", err)
+	}
+	defer func() {
+		_ = tx.Rollback()
+	}()
+
+	_, err = tx.ExecContext(ctx, `
+        INSERT INTO pull_requests (pull_request_id, pull_request_name, author_id, status, created_at, merged_at, closed_at, closed_from)
+        VALUES (?, ?, ?, ?, ?, ?, ?, NULLIF(?, ''))
+    `,
+		string(pr.ID),
+		pr.Name,
+		string(pr.AuthorID),
+		string(pr.Status),
+		formatTime(pr.CreatedAt),
+		nullTime(pr.MergedAt),
+		nullTime(pr.ClosedAt),
+		string(pr.ClosedFrom),
+	)
+	if err != nil {
+		if isUniqueViolation(err) {
+			return domain.ErrPullRequestExists
+		}
+		return fmt.Errorf("insert pull_request: %w", err)
+	}
+
+	if err := insertReviewers(ctx, tx, pr); err != nil {
+		return err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("create pr commit: %w", err)
+	}
+
+	return nil
+}
+
+func (r *PullRequestRepo) AddReviewers(ctx context.Context, pr domain.PullRequest) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("add reviewers begin tx: %w", err)
+	}
+	defer func() {
+		_ = tx.Rollback()
+	}()
+
+	if err := insertReviewers(ctx, tx, pr); err != nil {
+		return err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("add reviewers commit: %w", err)
+	}
+
+	return nil
+}
+
+func insertReviewers(ctx context.Context, tx querier, pr domain.PullRequest) error {
+	if len(pr.AssignedReviewers) == 0 {
+		return nil
+	}
+
+	stmt, err := tx.PrepareContext(ctx, `
+        INSERT INTO pull_request_reviewers (pull_request_id, user_id, fallback_team, matched_rule)
+        VALUES (?, ?, ?, ?)
+    `)
+	if err != nil {
+		return fmt.Errorf("prepare insert reviewers: %w", err)
+	}
+	defer func() {
+		_ = stmt.Close()
+	}()
+
+	for _, reviewerID := range pr.AssignedReviewers {
+		fallbackTeam := nullTeamName(pr.FallbackReviewers[reviewerID])
+		matchedRule := nullString(pr.MatchedRules[reviewerID])
+		if _, err := stmt.ExecContext(ctx, string(pr.ID), string(reviewerID), fallbackTeam, matchedRule); err != nil {
+			return fmt.Errorf("insert reviewer %s: %w", reviewerID, err)
+		}
+	}
+	return nil
+}
+
+func (r *PullRequestRepo) Exists(ctx context.Context, id domain.PullRequestID) (bool, error) {
+	var exists bool
+	err := r.db.QueryRowContext(ctx, `
+        SELECT EXISTS (SELECT 1 FROM pull_requests WHERE pull_request_id = ?)
+    `, string(id)).Scan(&exists)
+	if err != nil {
+		return false, fmt.Errorf("pr exists: %w", err)
+	}
+	return exists, nil
+}
+
+func (r *PullRequestRepo) Get(ctx context.Context, id domain.PullRequestID) (domain.PullRequest, error) {
+	var prID, name, authorID, statusStr, createdAt, closedFrom string
+	var mergedAt, closedAt sql.NullString
+
+	err := r.db.QueryRowContext(ctx, `
+        SELECT pull_request_id, pull_request_name, author_id, status, created_at, merged_at, closed_at, COALESCE(closed_from, '')
+        FROM pull_requests
+        WHERE pull_request_id = ?
+    `, string(id)).Scan(&prID, &name, &authorID, &statusStr, &createdAt, &mergedAt, &closedAt, &closedFrom)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return domain.PullRequest{}, domain.ErrNotFound
+		}
+		return domain.PullRequest{}, fmt.Errorf("get pr: %w", err)
+	}
+
+	pr, err := newPullRequest(prID, name, authorID, statusStr, createdAt, closedFrom, mergedAt, closedAt)
+	if err != nil {
+		return domain.PullRequest{}, err
+	}
+
+	rows, err := r.db.QueryContext(ctx, `
+        SELECT user_id, fallback_team, matched_rule, review_state
+        FROM pull_request_reviewers
+        WHERE pull_request_id = ?
+        ORDER BY user_id
+    `, string(id))
+	if err != nil {
+		return domain.PullRequest{}, fmt.Errorf("get pr reviewers: %w", err)
+	}
+	defer func() {
+		_ = rows.Close()
+	}()
+
+	for rows.Next() {
+		var uid string
+		var fallbackTeam, matchedRule sql.NullString
+		var state string
+		if err := rows.Scan(&uid, &fallbackTeam, &matchedRule, &state); err != nil {
+			return domain.PullRequest{}, fmt.Errorf("scan reviewer: %w", err)
+		}
+		addReviewer(&pr, uid, fallbackTeam, matchedRule, state)
+	}
+	if err := rows.Err(); err != nil {
+		return domain.PullRequest{}, fmt.Errorf("iterate reviewers: %w", err)
+	}
+
+	return pr, nil
+}
+
+// GetForUpdate needs no row lock: a unit of work begins with BEGIN IMMEDIATE,
+// which already keeps every other writer out of the database.
+func (r *PullRequestRepo) GetForUpdate(ctx context.Context, id domain.PullRequestID) (domain.PullRequest, error) {
+	return r.Get(ctx, id)
+}
+
+func newPullRequest(
+	id, name, authorID, status, createdAt, closedFrom string,
+	mergedAt, closedAt sql.NullString,
+) (domain.PullRequest, error) {
+	pr := domain.PullRequest{
+		ID:         domain.PullRequestID(id),
+		Name:       name,
+		AuthorID:   domain.UserID(authorID),
+		Status:     domain.PRStatus(status),
+		ClosedFrom: domain.PRStatus(closedFrom),
+	}
+
+	var err error
+	if pr.CreatedAt, err = parseTime(createdAt); err != nil {
+		return domain.PullRequest{}, err
+	}
+	if pr.MergedAt, err = parseNullTime(mergedAt); err != nil {
+		return domain.PullRequest{}, err
+	}
+	if pr.ClosedAt, err = parseNullTime(closedAt); err != nil {
+		return domain.PullRequest{}, err
+	}
+	return pr, nil
+}
+
+func addReviewer(pr *domain.PullRequest, uid string, fallbackTeam, matchedRule sql.NullString, state string) {
+	pr.AssignedReviewers = append(pr.AssignedReviewers, domain.UserID(uid))
+	if fallbackTeam.Valid {
+		if pr.FallbackReviewers == nil {
+			pr.FallbackReviewers = make(map[domain.UserID]domain.TeamName)
+		}
+		pr.FallbackReviewers[domain.UserID(uid)] = domain.TeamName(fallbackTeam.String)
+	}
+	if matchedRule.Valid {
+		if pr.MatchedRules == nil {
+			pr.MatchedRules = make(map[domain.UserID]string)
+		}
+		pr.MatchedRules[domain.UserID(uid)] = matchedRule.String
+	}
+	if domain.ReviewState(state) != domain.ReviewStatePending {
+		if pr.ReviewStates == nil {
+			pr.ReviewStates = make(map[domain.UserID]domain.ReviewState)
+		}
+		pr.ReviewStates[domain.UserID(uid)] = domain.ReviewState(state)
+	}
+}
+
+func (r *PullRequestRepo) List(ctx context.Context) ([]domain.PullRequest, error) {
+	rows, err := r.db.QueryContext(ctx, `
+        SELECT pull_request_id, pull_request_name, author_id, status, created_at, merged_at, closed_at, COALESCE(closed_from, '')
+        FROM pull_requests
+        ORDER BY created_at, pull_request_id
+    `)
+	if err != nil {
+		return nil, fmt.Errorf("list prs: %w", err)
+	}
+	defer func() {
+		_ = rows.Close()
+	}()
+
+	var res []domain.PullRequest
+	index := make(map[string]int)
+	for rows.Next() {
+		var id, name, authorID, statusStr, createdAt, closedFrom string
+		var mergedAt, closedAt sql.NullString
+		if err := rows.Scan(&id, &name, &authorID, &statusStr, &createdAt, &mergedAt, &closedAt, &closedFrom); err != nil {
+			return nil, fmt.Errorf("scan pr: %w", err)
+		}
+
+		pr, err := newPullRequest(id, name, authorID, statusStr, createdAt, closedFrom, mergedAt, closedAt)
+		if err != nil {
+			return nil, err
+		}
+		index[id] = len(res)
+		res = append(res, pr)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("iterate prs: %w", err)
+	}
+
+	reviewerRows, err := r.db.QueryContext(ctx, `
+        SELECT pull_request_id, user_id, fallback_team, matched_rule, review_state
+        FROM pull_request_reviewers
+        ORDER BY pull_request_id, user_id
+    `)
+	if err != nil {
+		return nil, fmt.Errorf("list pr reviewers: %w", err)
+	}
+	defer func() {
+		_ = reviewerRows.Close()
+	}()
+
+	for reviewerRows.Next() {
+		var prID, uid, state string
+		var fallbackTeam, matchedRule sql.NullString
+		if err := reviewerRows.Scan(&prID, &uid, &fallbackTeam, &matchedRule, &state); err != nil {
+			return nil, fmt.Errorf("scan reviewer: %w", err)
+		}
+		if i, ok := index[prID]; ok {
+			addReviewer(&res[i], uid, fallbackTeam, matchedRule, state)
+		}
+	}
+	if err := reviewerRows.Err(); err != nil {
+		return nil, fmt.Errorf("iterate reviewers: %w", err)
+	}
+
+	return res, nil
+}
+
+func (r *PullRequestRepo) SetReviewState(
+	ctx context.Context,
+	prID domain.PullRequestID,
+	reviewerID domain.UserID,
+	state domain.ReviewState,
+) error {
+	res, err := r.db.ExecContext(ctx, `
+        UPDATE pull_request_reviewers
+        SET review_state = ?
+        WHERE pull_request_id = ? AND user_id = ?
+    `, string(state), string(prID), string(reviewerID))
+	if err != nil {
+		return fmt.Errorf("set review state: %w", err)
+	}
+
+	n, err := res.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("set review state rows affected: %w", err)
+	}
+	if n == 0 {
+		return domain.ErrNotAssigned
+	}
+	return nil
+}
+
+func (r *PullRequestRepo) MarkMerged(ctx context.Context, id domain.PullRequestID, mergedAt time.Time) error {
+	_, err := r.db.ExecContext(ctx, `
+        UPDATE pull_requests
+        SET status = 'MERGED',
+            merged_at = ?
+        WHERE pull_request_id = ?
+    `, formatTime(mergedAt), string(id))
+	if err != nil {
+		return fmt.Errorf("mark merged: %w", err)
+	}
+	return nil
+}
+
+func (r *PullRequestRepo) SetStatus(
+	ctx context.Context,
+	id domain.PullRequestID,
+	status domain.PRStatus,
+	closedAt *time.Time,
+) error {
+	_, err := r.db.ExecContext(ctx, `
+        UPDATE pull_requests
+        SET closed_from = CASE
+                WHEN ? <> 'CLOSED' THEN NULL
+                WHEN status IN ('DRAFT', 'OPEN') THEN status
+                ELSE closed_from
+            END,
+            status = ?,
+            closed_at = ?
+        WHERE pull_request_id = ?
+    `, string(status), string(status), nullTime(closedAt), string(id))
+	if err != nil {
+		return fmt.Errorf("set pr status: %w", err)
+	}
+	return nil
+}
+
+func (r *PullRequestRepo) ReplaceReviewer(
+	ctx context.Context,
+	prID domain.PullRequestID,
+	oldUserID, newUserID domain.UserID,
+	fallbackTeam domain.TeamName,
+) (bool, error) {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return false, fmt.Errorf("replace reviewer begin tx: %w", err)
+	}
+	defer func() {
+		_ = tx.Rollback()
+	}()
+
+	res, err := tx.ExecContext(ctx, `
+        DELETE FROM pull_request_reviewers
+        WHERE pull_request_id = ? AND user_id = ?
+    `, string(prID), string(oldUserID))
+	if err != nil {
+		return false, fmt.Errorf("delete old reviewer: %w", err)
+	}
+	n, err := res.RowsAffected()
+	if err != nil {
+		return false, fmt.Errorf("delete old reviewer rows affected: %w", err)
+	}
+	if n == 0 {
+		return false, domain.ErrNotAssigned
+	}
+
+	res, err = tx.ExecContext(ctx, `
+        INSERT INTO pull_request_reviewers (pull_request_id, user_id, fallback_team)
+        VALUES (?, ?, ?)
+        ON CONFLICT DO NOTHING
+    `, string(prID), string(newUserID), nullTeamName(fallbackTeam))
+	if err != nil {
+		return false, fmt.Errorf("insert new reviewer: %w", err)
+	}
+	added, err := res.RowsAffected()
+	if err != nil {
+		return false, fmt.Errorf("insert new reviewer rows affected: %w", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return false, fmt.Errorf("replace reviewer commit: %w", err)
+	}
+
+	return added > 0, nil
+}
+
+func (r *PullRequestRepo) ListByReviewer(ctx context.Context, reviewerID domain.UserID) ([]domain.PullRequestShort, error) {
+	rows, err := r.db.QueryContext(ctx, `
+        SELECT pr.pull_request_id,
+               pr.pull_request_name,
+               pr.author_id,
+               pr.status
+        FROM pull_requests pr
+        JOIN pull_request_reviewers r ON pr.pull_request_id = r.pull_request_id
+        WHERE r.user_id = ?
+        ORDER BY pr.created_at DESC, pr.pull_request_id
+    `, string(reviewerID))
+	if err != nil {
+		return nil, fmt.Errorf("list prs by reviewer: %w", err)
+	}
+	defer func() {
+		_ = rows.Close()
+	}()
+
+	var res []domain.PullRequestShort
+	for rows.Next() {
+		var id, name, authorID, statusStr string
+		if err := rows.Scan(&id, &name, &authorID, &statusStr); err != nil {
+			return nil, fmt.Errorf("scan pr short: %w", err)
+		}
+		res = append(res, domain.PullRequestShort{
+			ID:       domain.PullRequestID(id),
+			Name:     name,
+			AuthorID: domain.UserID(authorID),
+			Status:   domain.PRStatus(statusStr),
+		})
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("iterate prs by reviewer: %w", err)
+	}
+
+	return res, nil
+}
+
+func (r *PullRequestRepo) StatsAssignmentsByUser(
+	ctx context.Context,
+) (map[domain.UserID]int, error) {
+	const q = `
+        SELECT user_id, COUNT(*)
+        FROM pull_request_reviewers
+        GROUP BY user_id
+    `
+
+	rows, err := r.db.QueryContext(ctx, q)
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		_ = rows.Close()
+	}()
+
+	res := make(map[domain.UserID]int)
+	for rows.Next() {
+		var id string
+		var cnt int
+		if err := rows.Scan(&id, &cnt); err != nil {
+			return nil, err
+		}
+		res[domain.UserID(id)] = cnt
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return res, nil
+}
+
+func (r *PullRequestRepo) OpenAssignmentsByUser(
+	ctx context.Context,
+) (map[domain.UserID]int, error) {
+	const q = `
+        SELECT r.user_id, COUNT(*)
+        FROM pull_request_reviewers r
+        JOIN pull_requests pr ON pr.pull_request_id = r.pull_request_id
+        WHERE pr.status = 'OPEN'
+        GROUP BY r.user_id
+    `
+
+	rows, err := r.db.QueryContext(ctx, q)
+	if err != nil {
+		return nil, fmt.Errorf("open assignments by user: %w", err)
+	}
+	defer func() {
+		_ = rows.Close()
+	}()
+
+	res := make(map[domain.UserID]int)
+	for rows.Next() {
+		var id string
+		var cnt int
+		if err := rows.Scan(&id, &cnt); err != nil {
+			return nil, fmt.Errorf("scan open assignments: %w", err)
+		}
+		res[domain.UserID(id)] = cnt
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("iterate open assignments: %w", err)
+	}
+
+	return res, nil
+}
+
+func (r *PullRequestRepo) OpenByTeam(ctx context.Context) (map[domain.TeamName]int, error) {
+	const q = `
+        SELECT u.team_name, COUNT(*)
+        FROM pull_requests pr
+        JOIN users u ON u.user_id = pr.author_id
+        WHERE pr.status = 'OPEN'
+        GROUP BY u.team_name
+    `
+
+	rows, err := r.db.QueryContext(ctx, q)
+	if err != nil {
+		return nil, fmt.Errorf("open prs by team: %w", err)
+	}
+	defer func() {
+		_ = rows.Close()
+	}()
+
+	res := make(map[domain.TeamName]int)
+	for rows.Next() {
+		var team string
+		var cnt int
+		if err := rows.Scan(&team, &cnt); err != nil {
+			return nil, fmt.Errorf("scan open prs by team: %w", err)
+		}
+		res[domain.TeamName(team)] = cnt
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("iterate open prs by team: %w", err)
+	}
+	return res, nil
+}
+
+func nullTeamName(name domain.TeamName) sql.NullString {
+	return sql.NullString{String: string(name), Valid: name != ""}
+}
+
+func nullString(s string) sql.NullString {
+	return sql.NullString{String: s, Valid: s != ""}
+}
+
+func (r *PullRequestRepo) AppendAssignmentEvents(ctx context.Context, events []domain.AssignmentEvent) error {
+	if len(events) == 0 {
+		return nil
+	}
+
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("append assignment events begin tx: %w", err)
+	}
+	defer func() {
+		_ = tx.Rollback()
+	}()
+
+	stmt, err := tx.PrepareContext(ctx, `
+        INSERT INTO reviewer_assignment_events
+            (pull_request_id, event_type, user_id, replaced_by, actor_id, reason, created_at)
+        VALUES (?, ?, ?, ?, ?, ?, ?)
+    `)
+	if err != nil {
+		return fmt.Errorf("prepare insert assignment events: %w", err)
+	}
+	defer func() {
+		_ = stmt.Close()
+	}()
+
+	for _, e := range events {
+		if _, err := stmt.ExecContext(ctx,
+			string(e.PullRequestID),
+			string(e.Type),
+			string(e.UserID),
+			nullUserID(e.ReplacedBy),
+			nullUserID(e.Actor),
+			string(e.Reason),
+			formatTime(e.CreatedAt),
+		); err != nil {
+			return fmt.Errorf("insert assignment event for %s: %w", e.UserID, err)
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("append assignment events commit: %w", err)
+	}
+
+	return nil
+}
+
+func (r *PullRequestRepo) ListAssignmentEvents(ctx context.Context, prID domain.PullRequestID) ([]domain.AssignmentEvent, error) {
+	rows, err := r.db.QueryContext(ctx, `
+        SELECT event_id, event_type, user_id, replaced_by, actor_id, reason, created_at
+        FROM reviewer_assignment_events
+        WHERE pull_request_id = ?
+        ORDER BY event_id
+    `, string(prID))
+	if err != nil {
+		return nil, fmt.Errorf("list assignment events: %w", err)
+	}
+	defer func() {
+		_ = rows.Close()
+	}()
+
+	var res []domain.AssignmentEvent
+	for rows.Next() {
+		var id int64
+		var eventType, userID, reason string
+		var replacedBy, actor sql.NullString
+		var createdAt string
+		if err := rows.Scan(&id, &eventType, &userID, &replacedBy, &actor, &reason, &createdAt); err != nil {
+			return nil, fmt.Errorf("scan assignment event: %w", err)
+		}
+		at, err := parseTime(createdAt)
+		if err != nil {
+			return nil, err
+		}
+		res = append(res, domain.AssignmentEvent{
+			ID:            id,
+			PullRequestID: prID,
+			Type:          domain.AssignmentEventType(eventType),
+			UserID:        domain.UserID(userID),
+			ReplacedBy:    domain.UserID(replacedBy.String),
+			Actor:         domain.UserID(actor.String),
+			Reason:        domain.AssignmentReason(reason),
+			CreatedAt:     at,
+		})
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("iterate assignment events: %w", err)
+	}
+
+	return res, nil
+}
+
+func nullUserID(id domain.UserID) sql.NullString {
+	return sql.NullString{String: string(id), Valid: id != ""}
+}
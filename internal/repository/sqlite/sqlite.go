@@ -0,0 +1,111 @@
+// Package sqlite stores data in a single SQLite file, for small installs
+// that do not want to run Postgres. It implements the team, user and pull
+// request repositories; outgoing webhooks still need Postgres.
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"net/url"
+	"strings"
+	"time"
+
+	"modernc.org/sqlite"
+	sqlite3 "modernc.org/sqlite/lib"
+)
+
+// Scheme prefixes DSNs handled by this package, e.g.
+// sqlite:///var/lib/pr-reviewer/data.db or sqlite://data.db for a path
+// relative to the working directory.
+const Scheme = "sqlite://"
+
+type DB struct {
+	conn *sql.DB
+}
+
+func New(dsn string) (*DB, error) {
+	path, ok := strings.CutPrefix(dsn, Scheme)
+	if !ok || path == "" {
+		return nil, fmt.Errorf("sqlite dsn must look like %s<path>", Scheme)
+	}
+
+	// WAL lets readers run next to the single writer; BEGIN IMMEDIATE takes
+	// the write lock up front so transactions wait on busy_timeout instead
+	// of failing when they later try to write.
+	params := url.Values{}
+	params.Add("_pragma", "foreign_keys(1)")
+	params.Add("_pragma", "busy_timeout(5000)")
+	params.Add("_pragma", "journal_mode(WAL)")
+	params.Set("_txlock", "immediate")
+
+	db, err := sql.Open("sqlite", "file:"+path+"?"+params.Encode())
+	if err != nil {
+		return nil, err
+	}
+
+	db.SetMaxOpenConns(4)
+	db.SetConnMaxLifetime(30 * time.Minute)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	if err := db.PingContext(ctx); err != nil {
+		_ = db.Close()
+		return nil, err
+	}
+
+	return &DB{conn: db}, nil
+}
+
+func (d *DB) Conn() *sql.DB {
+	return d.conn
+}
+
+func (d *DB) Close() error {
+	return d.conn.Close()
+}
+
+func isUniqueViolation(err error) bool {
+	var sqliteErr *sqlite.Error
+	if !errors.As(err, &sqliteErr) {
+		return false
+	}
+	code := sqliteErr.Code()
+	return code == sqlite3.SQLITE_CONSTRAINT_PRIMARYKEY || code == sqlite3.SQLITE_CONSTRAINT_UNIQUE
+}
+
+// Times are stored as fixed-width UTC text with microsecond precision, like
+// Postgres keeps them, so they compare correctly as strings.
+const timeLayout = "2006-01-02T15:04:05.000000Z"
+
+func formatTime(t time.Time) string {
+	return t.UTC().Format(timeLayout)
+}
+
+func nullTime(t *time.Time) sql.NullString {
+	if t == nil {
+		return sql.NullString{}
+	}
+	return sql.NullString{String: formatTime(*t), Valid: true}
+}
+
+func parseTime(s string) (time.Time, error) {
+	t, err := time.Parse(timeLayout, s)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("parse time %q: %w", s, err)
+	}
+	return t, nil
+}
+
+func parseNullTime(s sql.NullString) (*time.Time, error) {
+	if !s.Valid {
+		return nil, nil
+	}
+	t, err := parseTime(s.String)
+	if err != nil {
+		return nil, err
+	}
+	return &t, nil
+}
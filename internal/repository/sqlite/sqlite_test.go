@@ -0,0 +1,71 @@
+package sqlite_test
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+
+	"pr-reviewer-service/internal/domain"
+	"pr-reviewer-service/internal/repository/repotest"
+	"pr-reviewer-service/internal/repository/sqlite"
+)
+
+func open(t *testing.T) *sqlite.DB {
+	t.Helper()
+
+	db, err := sqlite.New(sqlite.Scheme + filepath.Join(t.TempDir(), "test.db"))
+	if err != nil {
+		t.Fatalf("open: %v", err)
+	}
+	t.Cleanup(func() {
+		_ = db.Close()
+	})
+
+	if err := sqlite.Migrate(context.Background(), db.Conn()); err != nil {
+		t.Fatalf("migrate: %v", err)
+	}
+	return db
+}
+
+func TestContract(t *testing.T) {
+	repotest.Run(t, func(t *testing.T) repotest.Backend {
+		db := open(t)
+		return repotest.Backend{
+			Repos: domain.Repositories{
+				Teams: sqlite.NewTeamRepo(db.Conn()),
+				Users: sqlite.NewUserRepo(db.Conn()),
+				Prs:   sqlite.NewPullRequestRepo(db.Conn()),
+			},
+			Tx:      sqlite.NewUnitOfWork(db.Conn()),
+			APIKeys: sqlite.NewAPIKeyRepo(db.Conn()),
+		}
+	})
+}
+
+func TestMigrateIsIdempotent(t *testing.T) {
+	ctx := context.Background()
+	db := open(t)
+
+	if err := sqlite.Migrate(ctx, db.Conn()); err != nil {
+		t.Fatalf("second migrate: %v", err)
+	}
+
+	status, err := sqlite.MigrationStatus(ctx, db.Conn())
+	if err != nil {
+		t.Fatalf("status: %v", err)
+	}
+	if len(status) == 0 {
+		t.Fatal("expected at least one migration")
+	}
+	for _, s := range status {
+		if s.AppliedAt == nil {
+			t.Errorf("migration %03d_%s not applied", s.Version, s.Name)
+		}
+	}
+}
+
+func TestNewRejectsOtherSchemes(t *testing.T) {
+	if _, err := sqlite.New("postgres://localhost/db"); err == nil {
+		t.Fatal("expected error for non-sqlite dsn")
+	}
+}
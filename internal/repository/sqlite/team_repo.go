@@ -0,0 +1,247 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+
+	"pr-reviewer-service/internal/domain"
+)
+
+type TeamRepo struct {
+	db executor
+}
+
+func NewTeamRepo(db *sql.DB) *TeamRepo {
+	return &TeamRepo{db: executor{db: db}}
+}
+
+func (r *TeamRepo) CreateTeam(ctx context.Context, name domain.TeamName) error {
+	_, err := r.db.ExecContext(ctx, `
+        INSERT INTO teams (team_name)
+        VALUES (?)
+        ON CONFLICT (team_name) DO NOTHING
+    `, string(name))
+	if err != nil {
+		return fmt.Errorf("create team: %w", err)
+	}
+	return nil
+}
+
+func (r *TeamRepo) ListTeams(ctx context.Context) ([]domain.TeamName, error) {
+	rows, err := r.db.QueryContext(ctx, `
+        SELECT team_name
+        FROM teams
+        ORDER BY team_name
+    `)
+	if err != nil {
+		return nil, fmt.Errorf("list teams: %w", err)
+	}
+	defer func() {
+		_ = rows.Close()
+	}()
+
+	var res []domain.TeamName
+	for rows.Next() {
+		var name string
+		if err := rows.Scan(&name); err != nil {
+			return nil, fmt.Errorf("scan team: %w", err)
+		}
+		res = append(res, domain.TeamName(name))
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("iterate teams: %w", err)
+	}
+
+	return res, nil
+}
+
+func (r *TeamRepo) GetTeam(ctx context.Context, name domain.TeamName) (domain.Team, error) {
+	exists, err := r.TeamExists(ctx, name)
+	if err != nil {
+		return domain.Team{}, err
+	}
+	if !exists {
+		return domain.Team{}, domain.ErrNotFound
+	}
+
+	rows, err := r.db.QueryContext(ctx, `
+        SELECT user_id, username, is_active, slack_handle, max_open_reviews
+        FROM users
+        WHERE team_name = ?
+        ORDER BY user_id
+    `, string(name))
+	if err != nil {
+		return domain.Team{}, fmt.Errorf("get team members: %w", err)
+	}
+	defer func() {
+		_ = rows.Close()
+	}()
+
+	team := domain.Team{Name: name}
+	for rows.Next() {
+		u := domain.User{TeamName: name}
+		var id string
+		if err := rows.Scan(&id, &u.Username, &u.IsActive, &u.SlackHandle, &u.MaxOpenReviews); err != nil {
+			return domain.Team{}, fmt.Errorf("scan member: %w", err)
+		}
+		u.ID = domain.UserID(id)
+		team.Members = append(team.Members, u)
+	}
+	if err := rows.Err(); err != nil {
+		return domain.Team{}, fmt.Errorf("iterate members: %w", err)
+	}
+
+	return team, nil
+}
+
+func (r *TeamRepo) TeamExists(ctx context.Context, name domain.TeamName) (bool, error) {
+	var exists bool
+	err := r.db.QueryRowContext(ctx, `
+        SELECT EXISTS (SELECT 1 FROM teams WHERE team_name = ?)
+    `, string(name)).Scan(&exists)
+	if err != nil {
+		return false, fmt.Errorf("team exists: %w", err)
+	}
+	return exists, nil
+}
+
+func (r *TeamRepo) GetSettings(ctx context.Context, name domain.TeamName) (domain.TeamSettings, error) {
+	var reviewersCount, minPoolSize, requiredApprovals sql.NullInt64
+	var strategy, slackWebhookURL sql.NullString
+
+	err := r.db.QueryRowContext(ctx, `
+        SELECT s.reviewers_count, s.strategy, s.min_pool_size, s.slack_webhook_url, s.required_approvals
+        FROM teams t
+        LEFT JOIN team_settings s ON s.team_name = t.team_name
+        WHERE t.team_name = ?
+    `, string(name)).Scan(&reviewersCount, &strategy, &minPoolSize, &slackWebhookURL, &requiredApprovals)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return domain.TeamSettings{}, domain.ErrNotFound
+		}
+		return domain.TeamSettings{}, fmt.Errorf("get team settings: %w", err)
+	}
+
+	settings := domain.DefaultTeamSettings(name)
+	if strategy.Valid {
+		settings.ReviewersCount = int(reviewersCount.Int64)
+		settings.Strategy = domain.ReviewerStrategy(strategy.String)
+		settings.MinPoolSize = int(minPoolSize.Int64)
+		settings.SlackWebhookURL = slackWebhookURL.String
+		settings.RequiredApprovals = int(requiredApprovals.Int64)
+	}
+
+	rows, err := r.db.QueryContext(ctx, `
+        SELECT fallback_team_name
+        FROM team_fallbacks
+        WHERE team_name = ?
+        ORDER BY position
+    `, string(name))
+	if err != nil {
+		return domain.TeamSettings{}, fmt.Errorf("get team fallbacks: %w", err)
+	}
+	defer func() {
+		_ = rows.Close()
+	}()
+
+	for rows.Next() {
+		var fallback string
+		if err := rows.Scan(&fallback); err != nil {
+			return domain.TeamSettings{}, fmt.Errorf("scan team fallback: %w", err)
+		}
+		settings.FallbackTeams = append(settings.FallbackTeams, domain.TeamName(fallback))
+	}
+	if err := rows.Err(); err != nil {
+		return domain.TeamSettings{}, fmt.Errorf("iterate team fallbacks: %w", err)
+	}
+
+	return settings, nil
+}
+
+func (r *TeamRepo) UpsertSettings(ctx context.Context, settings domain.TeamSettings) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("upsert team settings begin tx: %w", err)
+	}
+	defer func() {
+		_ = tx.Rollback()
+	}()
+
+	_, err = tx.ExecContext(ctx, `
+        INSERT INTO team_settings (team_name, reviewers_count, strategy, min_pool_size, slack_webhook_url, required_approvals)
+        VALUES (?, ?, ?, ?, ?, ?)
+        ON CONFLICT (team_name) DO UPDATE
+        SET reviewers_count = excluded.reviewers_count,
+            strategy = excluded.strategy,
+            min_pool_size = excluded.min_pool_size,
+            slack_webhook_url = excluded.slack_webhook_url,
+            required_approvals = excluded.required_approvals
+    `,
+		string(settings.TeamName),
+		settings.ReviewersCount,
+		string(settings.Strategy),
+		settings.MinPoolSize,
+		settings.SlackWebhookURL,
+		settings.RequiredApprovals,
+	)
+	if err != nil {
+		return fmt.Errorf("upsert team settings: %w", err)
+	}
+
+	if _, err := tx.ExecContext(ctx, `
+        DELETE FROM team_fallbacks
+        WHERE team_name = ?
+    `, string(settings.TeamName)); err != nil {
+		return fmt.Errorf("delete team fallbacks: %w", err)
+	}
+
+	for i, fallback := range settings.FallbackTeams {
+		if _, err := tx.ExecContext(ctx, `
+            INSERT INTO team_fallbacks (team_name, fallback_team_name, position)
+            VALUES (?, ?, ?)
+        `, string(settings.TeamName), string(fallback), i); err != nil {
+			return fmt.Errorf("insert team fallback %s: %w", fallback, err)
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("upsert team settings commit: %w", err)
+	}
+
+	return nil
+}
+
+func (r *TeamRepo) GetCodeowners(ctx context.Context, name domain.TeamName) (domain.Codeowners, error) {
+	c := domain.Codeowners{TeamName: name}
+	var updatedAt string
+	err := r.db.QueryRowContext(ctx, `
+        SELECT content, updated_at
+        FROM team_codeowners
+        WHERE team_name = ?
+    `, string(name)).Scan(&c.Content, &updatedAt)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return domain.Codeowners{}, domain.ErrNotFound
+		}
+		return domain.Codeowners{}, fmt.Errorf("get team codeowners: %w", err)
+	}
+	if c.UpdatedAt, err = parseTime(updatedAt); err != nil {
+		return domain.Codeowners{}, err
+	}
+	return c, nil
+}
+
+func (r *TeamRepo) PutCodeowners(ctx context.Context, c domain.Codeowners) error {
+	_, err := r.db.ExecContext(ctx, `
+        INSERT INTO team_codeowners (team_name, content, updated_at)
+        VALUES (?, ?, ?)
+        ON CONFLICT (team_name) DO UPDATE
+        SET content = excluded.content,
+            updated_at = excluded.updated_at
+    `, string(c.TeamName), c.Content, formatTime(c.UpdatedAt))
+	if err != nil {
+		return fmt.Errorf("put team codeowners: %w", err)
+	}
+	return nil
+}
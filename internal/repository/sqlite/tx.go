@@ -0,0 +1,108 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+
+	"pr-reviewer-service/internal/domain"
+)
+
+type querier interface {
+	ExecContext(ctx context.Context, query string, args ...any) (sql.Result, error)
+	QueryContext(ctx context.Context, query string, args ...any) (*sql.Rows, error)
+	QueryRowContext(ctx context.Context, query string, args ...any) *sql.Row
+	PrepareContext(ctx context.Context, query string) (*sql.Stmt, error)
+}
+
+type txn interface {
+	querier
+	Commit() error
+	Rollback() error
+}
+
+// executor runs queries on the pool, or on tx when the repository is bound
+// to a unit of work.
+type executor struct {
+	db *sql.DB
+	tx *sql.Tx
+}
+
+func (e executor) q() querier {
+	if e.tx != nil {
+		return e.tx
+	}
+	return e.db
+}
+
+func (e executor) ExecContext(ctx context.Context, query string, args ...any) (sql.Result, error) {
+	return e.q().ExecContext(ctx, query, args...)
+}
+
+func (e executor) QueryContext(ctx context.Context, query string, args ...any) (*sql.Rows, error) {
+	return e.q().QueryContext(ctx, query, args...)
+}
+
+func (e executor) QueryRowContext(ctx context.Context, query string, args ...any) *sql.Row {
+	return e.q().QueryRowContext(ctx, query, args...)
+}
+
+func (e executor) PrepareContext(ctx context.Context, query string) (*sql.Stmt, error) {
+	return e.q().PrepareContext(ctx, query)
+}
+
+// BeginTx starts a new transaction, or joins the unit of work's one, in which
+// case Commit and Rollback are left to the unit of work.
+func (e executor) BeginTx(ctx context.Context, opts *sql.TxOptions) (txn, error) {
+	if e.tx != nil {
+		return joinedTx{e.tx}, nil
+	}
+	return e.db.BeginTx(ctx, opts)
+}
+
+type joinedTx struct {
+	*sql.Tx
+}
+
+func (joinedTx) Commit() error {
+	return nil
+}
+
+func (joinedTx) Rollback() error {
+	return nil
+}
+
+type UnitOfWork struct {
+	db *sql.DB
+}
+
+func NewUnitOfWork(db *sql.DB) *UnitOfWork {
+	return &UnitOfWork{db: db}
+}
+
+func (u *UnitOfWork) Do(ctx context.Context, fn func(ctx context.Context, repos domain.Repositories) error) error {
+	tx, err := u.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("unit of work begin tx: %w", err)
+	}
+	defer func() {
+		_ = tx.Rollback()
+	}()
+
+	ex := executor{db: u.db, tx: tx}
+	repos := domain.Repositories{
+		Teams: &TeamRepo{db: ex},
+		Users: &UserRepo{db: ex},
+		Prs:   &PullRequestRepo{db: ex},
+	}
+
+	if err := fn(ctx, repos); err != nil {
+		return err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("unit of work commit: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,358 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"pr-reviewer-service/internal/domain"
+)
+
+type UserRepo struct {
+	db executor
+}
+
+func NewUserRepo(db *sql.DB) *UserRepo {
+	return &UserRepo{db: executor{db: db}}
+}
+
+func (r *UserRepo) UpsertUsers(ctx context.Context, users []domain.User) error {
+	if len(users) == 0 {
+		return nil
+	}
+
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("upsert users begin tx: %w", err)
+	}
+	defer func() {
+		_ = tx.Rollback()
+	}()
+
+	stmt, err := tx.PrepareContext(ctx, `
+        INSERT INTO users (user_id, username, team_name, is_active, slack_handle, max_open_reviews)
+        VALUES (?, ?, ?, ?, ?, ?)
+        ON CONFLICT (user_id) DO UPDATE
+        SET username = excluded.username,
+            team_name = excluded.team_name,
+            is_active = excluded.is_active,
+            slack_handle = excluded.slack_handle,
+            max_open_reviews = excluded.max_open_reviews
+    `)
+	if err != nil {
+		return fmt.Errorf("prepare upsert users: %w", err)
+	}
+	defer func() {
+		_ = stmt.Close()
+	}()
+
+	for _, u := range users {
+		if _, err := stmt.ExecContext(ctx,
+			string(u.ID),
+			u.Username,
+			string(u.TeamName),
+			u.IsActive,
+			u.SlackHandle,
+			u.MaxOpenReviews,
+		); err != nil {
+			return fmt.Errorf("exec upsert user %s: %w", u.ID, err)
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("upsert users commit: %w", err)
+	}
+
+	return nil
+}
+
+func (r *UserRepo) GetByID(ctx context.Context, id domain.UserID) (domain.User, error) {
+	var userID, username, teamName, slackHandle string
+	var isActive bool
+	var maxOpenReviews int
+
+	err := r.db.QueryRowContext(ctx, `
+        SELECT user_id, username, team_name, is_active, slack_handle, max_open_reviews
+        FROM users
+        WHERE user_id = ?
+    `, string(id)).Scan(&userID, &username, &teamName, &isActive, &slackHandle, &maxOpenReviews)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return domain.User{}, domain.ErrNotFound
+		}
+		return domain.User{}, fmt.Errorf("get user by id: %w", err)
+	}
+
+	return domain.User{
+		ID:             domain.UserID(userID),
+		Username:       username,
+		TeamName:       domain.TeamName(teamName),
+		IsActive:       isActive,
+		SlackHandle:    slackHandle,
+		MaxOpenReviews: maxOpenReviews,
+	}, nil
+}
+
+func (r *UserRepo) SetIsActive(ctx context.Context, id domain.UserID, isActive bool, actor domain.UserID) (domain.User, error) {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return domain.User{}, fmt.Errorf("set is_active begin tx: %w", err)
+	}
+	defer func() {
+		_ = tx.Rollback()
+	}()
+
+	res, err := tx.ExecContext(ctx, `
+        UPDATE users
+        SET is_active = ?
+        WHERE user_id = ?
+    `, isActive, string(id))
+	if err != nil {
+		return domain.User{}, fmt.Errorf("set is_active: %w", err)
+	}
+	n, err := res.RowsAffected()
+	if err != nil {
+		return domain.User{}, fmt.Errorf("set is_active rows affected: %w", err)
+	}
+	if n == 0 {
+		return domain.User{}, domain.ErrNotFound
+	}
+
+	_, err = tx.ExecContext(ctx, `
+        INSERT INTO user_status_changes (user_id, is_active, actor_id, changed_at)
+        VALUES (?, ?, ?, ?)
+    `, string(id), isActive, nullUserID(actor), formatTime(time.Now()))
+	if err != nil {
+		return domain.User{}, fmt.Errorf("insert status change: %w", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return domain.User{}, fmt.Errorf("set is_active commit: %w", err)
+	}
+
+	return r.GetByID(ctx, id)
+}
+
+func (r *UserRepo) ListStatusChanges(ctx context.Context, id domain.UserID) ([]domain.UserStatusChange, error) {
+	rows, err := r.db.QueryContext(ctx, `
+        SELECT change_id, is_active, actor_id, changed_at
+        FROM user_status_changes
+        WHERE user_id = ?
+        ORDER BY change_id
+    `, string(id))
+	if err != nil {
+		return nil, fmt.Errorf("list status changes: %w", err)
+	}
+	defer func() {
+		_ = rows.Close()
+	}()
+
+	var res []domain.UserStatusChange
+	for rows.Next() {
+		var changeID int64
+		var isActive bool
+		var actor sql.NullString
+		var changedAt string
+		if err := rows.Scan(&changeID, &isActive, &actor, &changedAt); err != nil {
+			return nil, fmt.Errorf("scan status change: %w", err)
+		}
+		at, err := parseTime(changedAt)
+		if err != nil {
+			return nil, err
+		}
+		res = append(res, domain.UserStatusChange{
+			ID:        changeID,
+			UserID:    id,
+			IsActive:  isActive,
+			Actor:     domain.UserID(actor.String),
+			ChangedAt: at,
+		})
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("iterate status changes: %w", err)
+	}
+
+	return res, nil
+}
+
+func (r *UserRepo) SetMaxOpenReviews(ctx context.Context, id domain.UserID, maxOpenReviews int) (domain.User, error) {
+	_, err := r.db.ExecContext(ctx, `
+        UPDATE users
+        SET max_open_reviews = ?
+        WHERE user_id = ?
+    `, maxOpenReviews, string(id))
+	if err != nil {
+		return domain.User{}, fmt.Errorf("set max_open_reviews: %w", err)
+	}
+
+	return r.GetByID(ctx, id)
+}
+
+func (r *UserRepo) ListActiveByTeam(ctx context.Context, teamName domain.TeamName) ([]domain.User, error) {
+	now := formatTime(time.Now())
+	rows, err := r.db.QueryContext(ctx, `
+        SELECT user_id, username, is_active, slack_handle, max_open_reviews
+        FROM users
+        WHERE team_name = ?
+          AND is_active = 1
+          AND NOT EXISTS (
+              SELECT 1 FROM user_unavailability ua
+              WHERE ua.user_id = users.user_id
+                AND ua.starts_at <= ?
+                AND ua.ends_at > ?
+          )
+        ORDER BY user_id
+    `, string(teamName), now, now)
+	if err != nil {
+		return nil, fmt.Errorf("list active users by team: %w", err)
+	}
+	defer func() {
+		_ = rows.Close()
+	}()
+
+	var res []domain.User
+	for rows.Next() {
+		u := domain.User{TeamName: teamName}
+		var id string
+		if err := rows.Scan(&id, &u.Username, &u.IsActive, &u.SlackHandle, &u.MaxOpenReviews); err != nil {
+			return nil, fmt.Errorf("scan active user: %w", err)
+		}
+		u.ID = domain.UserID(id)
+		res = append(res, u)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("iterate active users: %w", err)
+	}
+
+	return res, nil
+}
+
+func (r *UserRepo) AddUnavailability(ctx context.Context, u domain.Unavailability) (domain.Unavailability, error) {
+	err := r.db.QueryRowContext(ctx, `
+        INSERT INTO user_unavailability (user_id, starts_at, ends_at, reason, reassign_open_reviews)
+        VALUES (?, ?, ?, ?, ?)
+        RETURNING unavailability_id
+    `, string(u.UserID), formatTime(u.StartsAt), formatTime(u.EndsAt), u.Reason, u.ReassignOpenReviews).Scan(&u.ID)
+	if err != nil {
+		return domain.Unavailability{}, fmt.Errorf("insert unavailability: %w", err)
+	}
+	return u, nil
+}
+
+func (r *UserRepo) ListUnavailability(ctx context.Context, id domain.UserID) ([]domain.Unavailability, error) {
+	rows, err := r.db.QueryContext(ctx, `
+        SELECT unavailability_id, user_id, starts_at, ends_at, reason, reassign_open_reviews, reassigned_at
+        FROM user_unavailability
+        WHERE user_id = ?
+        ORDER BY starts_at, unavailability_id
+    `, string(id))
+	if err != nil {
+		return nil, fmt.Errorf("list unavailability: %w", err)
+	}
+	return scanUnavailability(rows)
+}
+
+func (r *UserRepo) GetUnavailability(ctx context.Context, id int64) (domain.Unavailability, error) {
+	rows, err := r.db.QueryContext(ctx, `
+        SELECT unavailability_id, user_id, starts_at, ends_at, reason, reassign_open_reviews, reassigned_at
+        FROM user_unavailability
+        WHERE unavailability_id = ?
+    `, id)
+	if err != nil {
+		return domain.Unavailability{}, fmt.Errorf("get unavailability: %w", err)
+	}
+
+	periods, err := scanUnavailability(rows)
+	if err != nil {
+		return domain.Unavailability{}, err
+	}
+	if len(periods) == 0 {
+		return domain.Unavailability{}, domain.ErrNotFound
+	}
+	return periods[0], nil
+}
+
+func (r *UserRepo) DeleteUnavailability(ctx context.Context, id int64) error {
+	res, err := r.db.ExecContext(ctx, `
+        DELETE FROM user_unavailability
+        WHERE unavailability_id = ?
+    `, id)
+	if err != nil {
+		return fmt.Errorf("delete unavailability: %w", err)
+	}
+
+	n, err := res.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("delete unavailability rows affected: %w", err)
+	}
+	if n == 0 {
+		return domain.ErrNotFound
+	}
+	return nil
+}
+
+func (r *UserRepo) ListDueReassignments(ctx context.Context, now time.Time) ([]domain.Unavailability, error) {
+	at := formatTime(now)
+	rows, err := r.db.QueryContext(ctx, `
+        SELECT unavailability_id, user_id, starts_at, ends_at, reason, reassign_open_reviews, reassigned_at
+        FROM user_unavailability
+        WHERE reassign_open_reviews = 1
+          AND reassigned_at IS NULL
+          AND starts_at <= ?
+          AND ends_at > ?
+        ORDER BY starts_at, unavailability_id
+    `, at, at)
+	if err != nil {
+		return nil, fmt.Errorf("list due reassignments: %w", err)
+	}
+	return scanUnavailability(rows)
+}
+
+func (r *UserRepo) MarkReassigned(ctx context.Context, id int64, at time.Time) error {
+	_, err := r.db.ExecContext(ctx, `
+        UPDATE user_unavailability
+        SET reassigned_at = ?
+        WHERE unavailability_id = ?
+    `, formatTime(at), id)
+	if err != nil {
+		return fmt.Errorf("mark unavailability reassigned: %w", err)
+	}
+	return nil
+}
+
+func scanUnavailability(rows *sql.Rows) ([]domain.Unavailability, error) {
+	defer func() {
+		_ = rows.Close()
+	}()
+
+	var res []domain.Unavailability
+	for rows.Next() {
+		var u domain.Unavailability
+		var userID, startsAt, endsAt string
+		var reassignedAt sql.NullString
+		if err := rows.Scan(
+			&u.ID, &userID, &startsAt, &endsAt, &u.Reason, &u.ReassignOpenReviews, &reassignedAt,
+		); err != nil {
+			return nil, fmt.Errorf("scan unavailability: %w", err)
+		}
+		u.UserID = domain.UserID(userID)
+
+		var err error
+		if u.StartsAt, err = parseTime(startsAt); err != nil {
+			return nil, err
+		}
+		if u.EndsAt, err = parseTime(endsAt); err != nil {
+			return nil, err
+		}
+		if u.ReassignedAt, err = parseNullTime(reassignedAt); err != nil {
+			return nil, err
+		}
+		res = append(res, u)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("iterate unavailability: %w", err)
+	}
+
+	return res, nil
+}
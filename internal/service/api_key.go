@@ -0,0 +1,95 @@
+package service
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"strings"
+	"time"
+
+	"pr-reviewer-service/internal/domain"
+)
+
+// APIKeyPrefix starts every secret issued here, which tells API keys apart
+// from other bearer tokens.
+const APIKeyPrefix = "prk_"
+
+type APIKeyService struct {
+	keys  domain.APIKeyRepository
+	teams domain.TeamRepository
+}
+
+func NewAPIKeyService(keys domain.APIKeyRepository, teams domain.TeamRepository) *APIKeyService {
+	return &APIKeyService{
+		keys:  keys,
+		teams: teams,
+	}
+}
+
+// Create issues a key and returns it with its secret. Only a hash is stored,
+// so the secret cannot be shown again.
+func (s *APIKeyService) Create(
+	ctx context.Context,
+	name string,
+	role domain.Role,
+	team domain.TeamName,
+) (domain.APIKey, string, error) {
+	if name == "" || !role.Valid() || (role == domain.RoleTeamLead) != (team != "") {
+		return domain.APIKey{}, "", domain.ErrInvalidAPIKey
+	}
+	if team != "" {
+		exists, err := s.teams.TeamExists(ctx, team)
+		if err != nil {
+			return domain.APIKey{}, "", err
+		}
+		if !exists {
+			return domain.APIKey{}, "", domain.ErrNotFound
+		}
+	}
+
+	buf := make([]byte, 32)
+	if _, err := rand.Read(buf); err != nil {
+		return domain.APIKey{}, "", err
+	}
+	secret := APIKeyPrefix + hex.EncodeToString(buf)
+
+	key, err := s.keys.Create(ctx, domain.APIKey{
+		Name:      name,
+		Role:      role,
+		TeamName:  team,
+		Hash:      hashAPIKey(secret),
+		CreatedAt: time.Now().UTC(),
+	})
+	if err != nil {
+		return domain.APIKey{}, "", err
+	}
+	return key, secret, nil
+}
+
+// Authenticate returns the active key for secret, or ErrUnauthenticated.
+func (s *APIKeyService) Authenticate(ctx context.Context, secret string) (domain.APIKey, error) {
+	if !strings.HasPrefix(secret, APIKeyPrefix) {
+		return domain.APIKey{}, domain.ErrUnauthenticated
+	}
+
+	key, err := s.keys.GetByHash(ctx, hashAPIKey(secret))
+	if errors.Is(err, domain.ErrNotFound) {
+		return domain.APIKey{}, domain.ErrUnauthenticated
+	}
+	return key, err
+}
+
+func (s *APIKeyService) List(ctx context.Context) ([]domain.APIKey, error) {
+	return s.keys.List(ctx)
+}
+
+func (s *APIKeyService) Revoke(ctx context.Context, id int64) error {
+	return s.keys.Revoke(ctx, id, time.Now().UTC())
+}
+
+func hashAPIKey(secret string) string {
+	sum := sha256.Sum256([]byte(secret))
+	return hex.EncodeToString(sum[:])
+}
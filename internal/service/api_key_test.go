@@ -0,0 +1,78 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"pr-reviewer-service/internal/domain"
+	"pr-reviewer-service/internal/repository/memory"
+)
+
+func TestAPIKeyService_CreateAndAuthenticate(t *testing.T) {
+	ctx := context.Background()
+	repos := newTestRepos(t, []domain.User{{ID: "u1", TeamName: "backend"}})
+	repo := memory.NewAPIKeyRepo(repos.store)
+	svc := NewAPIKeyService(repo, repos.teams)
+
+	key, secret, err := svc.Create(ctx, "backend lead", domain.RoleTeamLead, "backend")
+	if err != nil {
+		t.Fatalf("Create: %v", err)
+	}
+	if !strings.HasPrefix(secret, APIKeyPrefix) {
+		t.Fatalf("secret %q lacks prefix", secret)
+	}
+	stored, err := repo.List(ctx)
+	if err != nil {
+		t.Fatalf("List: %v", err)
+	}
+	if strings.Contains(stored[0].Hash, secret) || stored[0].Hash == "" {
+		t.Fatalf("stored hash %q must not contain the secret", stored[0].Hash)
+	}
+
+	got, err := svc.Authenticate(ctx, secret)
+	if err != nil {
+		t.Fatalf("Authenticate: %v", err)
+	}
+	if got.ID != key.ID || !got.Principal().CanManageTeam("backend") || got.Principal().CanManageTeam("platform") {
+		t.Fatalf("Authenticate = %+v", got)
+	}
+
+	for _, bad := range []string{"", "prk_wrong", secret[len(APIKeyPrefix):]} {
+		if _, err := svc.Authenticate(ctx, bad); !errors.Is(err, domain.ErrUnauthenticated) {
+			t.Fatalf("Authenticate(%q): expected ErrUnauthenticated, got %v", bad, err)
+		}
+	}
+
+	if err := svc.Revoke(ctx, key.ID); err != nil {
+		t.Fatalf("Revoke: %v", err)
+	}
+	if _, err := svc.Authenticate(ctx, secret); !errors.Is(err, domain.ErrUnauthenticated) {
+		t.Fatalf("revoked key: expected ErrUnauthenticated, got %v", err)
+	}
+}
+
+func TestAPIKeyService_CreateValidates(t *testing.T) {
+	ctx := context.Background()
+	repos := newTestRepos(t, []domain.User{{ID: "u1", TeamName: "backend"}})
+	svc := NewAPIKeyService(memory.NewAPIKeyRepo(repos.store), repos.teams)
+
+	cases := []struct {
+		name string
+		role domain.Role
+		team domain.TeamName
+		want error
+	}{
+		{"", domain.RoleAdmin, "", domain.ErrInvalidAPIKey},
+		{"k", "owner", "", domain.ErrInvalidAPIKey},
+		{"k", domain.RoleTeamLead, "", domain.ErrInvalidAPIKey},
+		{"k", domain.RoleAdmin, "backend", domain.ErrInvalidAPIKey},
+		{"k", domain.RoleTeamLead, "ghost", domain.ErrNotFound},
+	}
+	for _, c := range cases {
+		if _, _, err := svc.Create(ctx, c.name, c.role, c.team); !errors.Is(err, c.want) {
+			t.Errorf("Create(%q, %q, %q) = %v, want %v", c.name, c.role, c.team, err, c.want)
+		}
+	}
+}
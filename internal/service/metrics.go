@@ -0,0 +1,59 @@
+package service
+
+import (
+	"context"
+	"errors"
+
+	"pr-reviewer-service/internal/domain"
+)
+
+// Metrics counts assignment outcomes for monitoring. It is told about
+// changes once they are committed; implementations must not block.
+type Metrics interface {
+	ReviewerReplaced(reason domain.AssignmentReason)
+	// NoCandidate is a reassignment that found nobody to take over, whether
+	// the pool was empty, too small or at capacity.
+	NoCandidate(reason domain.AssignmentReason)
+	// Understaffed is a PR opened with fewer reviewers than its team asks for.
+	Understaffed(team domain.TeamName)
+}
+
+func (s *PRService) recordReassignments(reason domain.AssignmentReason, replaced, stuck int) {
+	if s.Metrics == nil {
+		return
+	}
+	for range replaced {
+		s.Metrics.ReviewerReplaced(reason)
+	}
+	for range stuck {
+		s.Metrics.NoCandidate(reason)
+	}
+}
+
+// noReplacement reports whether a reassignment failed for lack of a reviewer
+// to take over, leaving the old one on the PR.
+func noReplacement(err error) bool {
+	return errors.Is(err, domain.ErrNoCandidate) ||
+		errors.Is(err, domain.ErrPoolTooSmall) ||
+		errors.Is(err, domain.ErrAllReviewersAtCapacity)
+}
+
+// recordStaffing compares a newly opened PR with its team's reviewer count.
+// Like notifications it is best effort: lookup failures skip it.
+func (s *PRService) recordStaffing(ctx context.Context, pr domain.PullRequest) {
+	if s.Metrics == nil || pr.Status != domain.PRStatusOpen {
+		return
+	}
+
+	author, err := s.Users.GetByID(ctx, pr.AuthorID)
+	if err != nil {
+		return
+	}
+	settings, err := s.Teams.GetSettings(ctx, author.TeamName)
+	if err != nil {
+		return
+	}
+	if len(pr.AssignedReviewers) < settings.ReviewersCount {
+		s.Metrics.Understaffed(author.TeamName)
+	}
+}
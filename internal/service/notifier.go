@@ -0,0 +1,58 @@
+package service
+
+import (
+	"context"
+
+	"pr-reviewer-service/internal/domain"
+)
+
+// Notifier is told about reviewer assignments once they are committed.
+// Implementations must not block on delivery.
+type Notifier interface {
+	NotifyReviewer(ctx context.Context, notice domain.ReviewerNotice)
+}
+
+// notifyReviewers posts to the webhook of the author's team. Notifications
+// are best effort: lookup failures only skip them.
+func (s *PRService) notifyReviewers(
+	ctx context.Context,
+	pr domain.PullRequest,
+	reviewers []domain.UserID,
+	replaced domain.UserID,
+	reason domain.AssignmentReason,
+) {
+	if s.Notifier == nil || len(reviewers) == 0 {
+		return
+	}
+
+	author, err := s.Users.GetByID(ctx, pr.AuthorID)
+	if err != nil {
+		return
+	}
+	settings, err := s.Teams.GetSettings(ctx, author.TeamName)
+	if err != nil || settings.SlackWebhookURL == "" {
+		return
+	}
+
+	for _, id := range reviewers {
+		reviewer, err := s.Users.GetByID(ctx, id)
+		if err != nil {
+			continue
+		}
+		s.Notifier.NotifyReviewer(ctx, domain.ReviewerNotice{
+			WebhookURL:      settings.SlackWebhookURL,
+			PullRequestID:   pr.ID,
+			PullRequestName: pr.Name,
+			AuthorID:        pr.AuthorID,
+			Reviewer:        reviewer,
+			Replaced:        replaced,
+			Reason:          reason,
+		})
+	}
+}
+
+func (s *PRService) notifyHandovers(ctx context.Context, handovers []handover, reason domain.AssignmentReason) {
+	for _, h := range handovers {
+		s.notifyReviewers(ctx, h.pr, []domain.UserID{h.to}, h.from, reason)
+	}
+}
@@ -0,0 +1,68 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+
+	"pr-reviewer-service/internal/domain"
+)
+
+type prCreatedPayload struct {
+	PullRequestID     domain.PullRequestID `json:"pull_request_id"`
+	PullRequestName   string               `json:"pull_request_name"`
+	AuthorID          domain.UserID        `json:"author_id"`
+	Status            domain.PRStatus      `json:"status"`
+	AssignedReviewers []domain.UserID      `json:"assigned_reviewers"`
+	CreatedAt         time.Time            `json:"created_at"`
+}
+
+type reviewerAssignedPayload struct {
+	PullRequestID domain.PullRequestID    `json:"pull_request_id"`
+	ReviewerID    domain.UserID           `json:"reviewer_id"`
+	Reason        domain.AssignmentReason `json:"reason"`
+}
+
+type reviewerReplacedPayload struct {
+	PullRequestID domain.PullRequestID    `json:"pull_request_id"`
+	OldReviewerID domain.UserID           `json:"old_reviewer_id"`
+	NewReviewerID domain.UserID           `json:"new_reviewer_id"`
+	Reason        domain.AssignmentReason `json:"reason"`
+	Actor         domain.UserID           `json:"actor_id,omitempty"`
+}
+
+type prMergedPayload struct {
+	PullRequestID domain.PullRequestID `json:"pull_request_id"`
+	MergedAt      time.Time            `json:"merged_at"`
+}
+
+type prStatusChangedPayload struct {
+	PullRequestID domain.PullRequestID `json:"pull_request_id"`
+	From          domain.PRStatus      `json:"from"`
+	To            domain.PRStatus      `json:"to"`
+	ChangedAt     time.Time            `json:"changed_at"`
+}
+
+type userStatusChangedPayload struct {
+	UserID    domain.UserID `json:"user_id"`
+	IsActive  bool          `json:"is_active"`
+	Actor     domain.UserID `json:"actor_id,omitempty"`
+	ChangedAt time.Time     `json:"changed_at"`
+}
+
+func newOutboxEvent(t domain.OutboxEventType, payload any, at time.Time) (domain.OutboxEvent, error) {
+	data, err := json.Marshal(payload)
+	if err != nil {
+		return domain.OutboxEvent{}, err
+	}
+	return domain.OutboxEvent{Type: t, Payload: data, CreatedAt: at}, nil
+}
+
+// emit stores events in the outbox of the current unit of work. It is a no-op
+// when the service runs without an outbox.
+func emit(ctx context.Context, repos domain.Repositories, events ...domain.OutboxEvent) error {
+	if repos.Outbox == nil || len(events) == 0 {
+		return nil
+	}
+	return repos.Outbox.AppendOutbox(ctx, events)
+}
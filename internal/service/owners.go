@@ -0,0 +1,120 @@
+package service
+
+import (
+	"context"
+	"strings"
+
+	"pr-reviewer-service/internal/codeowners"
+	"pr-reviewer-service/internal/domain"
+)
+
+// ownerPools narrows each pool down to candidates owning one of the changed
+// files and reports the rule that matched each of them.
+func ownerPools(
+	pools []candidatePool,
+	rules codeowners.Ruleset,
+	files []string,
+) ([]candidatePool, map[domain.UserID]string) {
+	var matched []codeowners.Rule
+	for _, f := range files {
+		if rule, ok := rules.Match(f); ok && len(rule.Owners) > 0 {
+			matched = append(matched, rule)
+		}
+	}
+	if len(matched) == 0 {
+		return nil, nil
+	}
+
+	owners := make(map[domain.UserID]string)
+	res := make([]candidatePool, 0, len(pools))
+	for _, p := range pools {
+		owned := candidatePool{team: p.team, fallback: p.fallback}
+		for _, u := range p.users {
+			if rule, ok := ruleForUser(u, matched); ok {
+				owned.users = append(owned.users, u)
+				owners[u.ID] = rule.Pattern
+			}
+		}
+		res = append(res, owned)
+	}
+
+	return res, owners
+}
+
+func ruleForUser(u domain.User, rules []codeowners.Rule) (codeowners.Rule, bool) {
+	for _, rule := range rules {
+		for _, owner := range rule.Owners {
+			if ownsAs(u, owner) {
+				return rule, true
+			}
+		}
+	}
+	return codeowners.Rule{}, false
+}
+
+// ownsAs resolves @user-id, @username and @org/team owners; e-mail owners
+// never match since users carry no address.
+func ownsAs(u domain.User, owner string) bool {
+	if !strings.HasPrefix(owner, "@") {
+		return false
+	}
+	name := strings.TrimPrefix(owner, "@")
+	if i := strings.LastIndex(name, "/"); i >= 0 {
+		return domain.TeamName(name[i+1:]) == u.TeamName
+	}
+	return domain.UserID(name) == u.ID || strings.EqualFold(name, u.Username)
+}
+
+// pickWithOwners fills slots with code owners first and the rest of the
+// slots from the regular pools.
+func pickWithOwners(
+	ctx context.Context,
+	selector ReviewerSelector,
+	pools []candidatePool,
+	rules codeowners.Ruleset,
+	files []string,
+	limit int,
+) ([]domain.UserID, map[domain.UserID]domain.TeamName, map[domain.UserID]string, error) {
+	owned, owners := ownerPools(pools, rules, files)
+	if len(owners) == 0 {
+		assigned, fallbacks, err := pickFromPools(ctx, selector, pools, limit)
+		return assigned, fallbacks, nil, err
+	}
+
+	picked, fallbacks, err := pickFromPools(ctx, selector, owned, limit)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+
+	taken := make(map[domain.UserID]struct{}, len(picked))
+	matched := make(map[domain.UserID]string, len(picked))
+	for _, id := range picked {
+		taken[id] = struct{}{}
+		matched[id] = owners[id]
+	}
+
+	rest := make([]candidatePool, 0, len(pools))
+	for _, p := range pools {
+		left := candidatePool{team: p.team, fallback: p.fallback}
+		for _, u := range p.users {
+			if _, ok := taken[u.ID]; !ok {
+				left.users = append(left.users, u)
+			}
+		}
+		rest = append(rest, left)
+	}
+
+	more, moreFallbacks, err := pickFromPools(ctx, selector, rest, limit-len(picked))
+	if err != nil {
+		return nil, nil, nil, err
+	}
+
+	for id, team := range moreFallbacks {
+		if fallbacks == nil {
+			fallbacks = make(map[domain.UserID]domain.TeamName)
+		}
+		fallbacks[id] = team
+	}
+
+	return append(picked, more...), fallbacks, matched, nil
+}
@@ -0,0 +1,105 @@
+package service
+
+import (
+	"context"
+
+	"pr-reviewer-service/internal/domain"
+)
+
+type candidatePool struct {
+	team     domain.TeamName
+	fallback bool
+	users    []domain.User
+}
+
+// loadPools collects active candidates from the team itself and then from its
+// fallback teams in order, until at least need candidates are available.
+// Users at their review capacity are skipped; if that leaves nobody at all,
+// ErrAllReviewersAtCapacity is returned.
+func loadPools(
+	ctx context.Context,
+	repos domain.Repositories,
+	settings domain.TeamSettings,
+	need int,
+	exclude map[domain.UserID]struct{},
+) ([]candidatePool, int, error) {
+	teams := append([]domain.TeamName{settings.TeamName}, settings.FallbackTeams...)
+
+	var pools []candidatePool
+	var load map[domain.UserID]int
+	total, atCapacity := 0, 0
+	for i, team := range teams {
+		if i > 0 && total >= need {
+			break
+		}
+
+		members, err := repos.Users.ListActiveByTeam(ctx, team)
+		if err != nil {
+			return nil, 0, err
+		}
+
+		var filtered []domain.User
+		for _, u := range members {
+			if _, ok := exclude[u.ID]; ok {
+				continue
+			}
+			if u.MaxOpenReviews > 0 && load == nil {
+				if load, err = repos.Prs.OpenAssignmentsByUser(ctx); err != nil {
+					return nil, 0, err
+				}
+			}
+			if u.AtCapacity(load[u.ID]) {
+				atCapacity++
+				continue
+			}
+			filtered = append(filtered, u)
+		}
+
+		pools = append(pools, candidatePool{
+			team:     team,
+			fallback: i > 0,
+			users:    filtered,
+		})
+		total += len(filtered)
+	}
+
+	if need > 0 && total == 0 && atCapacity > 0 {
+		return nil, 0, domain.ErrAllReviewersAtCapacity
+	}
+	return pools, total, nil
+}
+
+func pickFromPools(
+	ctx context.Context,
+	selector ReviewerSelector,
+	pools []candidatePool,
+	limit int,
+) ([]domain.UserID, map[domain.UserID]domain.TeamName, error) {
+	var assigned []domain.UserID
+	var fallbacks map[domain.UserID]domain.TeamName
+
+	for _, p := range pools {
+		missing := limit - len(assigned)
+		if missing <= 0 {
+			break
+		}
+
+		picked, err := selector.Select(ctx, p.users, missing)
+		if err != nil {
+			return nil, nil, err
+		}
+
+		assigned = append(assigned, picked...)
+		if !p.fallback {
+			continue
+		}
+		for _, id := range picked {
+			if fallbacks == nil {
+				fallbacks = make(map[domain.UserID]domain.TeamName)
+			}
+			fallbacks[id] = p.team
+		}
+	}
+
+	return assigned, fallbacks, nil
+}
@@ -3,96 +3,591 @@ package service
 import (
 	"context"
 	"errors"
+	"fmt"
 	"math/rand"
+	"pr-reviewer-service/internal/codeowners"
 	"pr-reviewer-service/internal/domain"
+	"slices"
 	"time"
 )
 
 type PRService struct {
+	Teams domain.TeamRepository
 	Users domain.UserRepository
 	Prs   domain.PullRequestRepository
-	Rand  *rand.Rand
+	// Outbox receives integration events; it may be nil.
+	Outbox domain.OutboxRepository
+	// Tx runs multi-step operations atomically. Without it the repositories
+	// above are used directly.
+	Tx   domain.UnitOfWork
+	Rand *rand.Rand
+	// Selector, when set, overrides the strategy configured in team settings.
+	Selector ReviewerSelector
+	// Notifier, when set, announces new assignments to reviewers.
+	Notifier Notifier
+	// Metrics, when set, counts reassignments and understaffed PRs.
+	Metrics Metrics
 }
 
-func NewPRService(users domain.UserRepository, prs domain.PullRequestRepository) *PRService {
+func NewPRService(teams domain.TeamRepository, users domain.UserRepository, prs domain.PullRequestRepository) *PRService {
 	src := rand.NewSource(time.Now().UnixNano())
 	return &PRService{
+		Teams: teams,
 		Users: users,
 		Prs:   prs,
 		Rand:  rand.New(src),
 	}
 }
 
-func (s *PRService) Create(ctx context.Context, id domain.PullRequestID, name string, authorID domain.UserID) (domain.PullRequest, error) {
-	exists, err := s.Prs.Exists(ctx, id)
+func (s *PRService) withinTx(ctx context.Context, fn func(ctx context.Context, repos domain.Repositories) error) error {
+	if s.Tx == nil {
+		return fn(ctx, domain.Repositories{Teams: s.Teams, Users: s.Users, Prs: s.Prs, Outbox: s.Outbox})
+	}
+	return s.Tx.Do(ctx, fn)
+}
+
+func (s *PRService) selectorFor(strategy domain.ReviewerStrategy, prs domain.PullRequestRepository) (ReviewerSelector, error) {
+	if s.Selector != nil {
+		return s.Selector, nil
+	}
+	return NewReviewerSelector(strategy, prs, s.Rand)
+}
+
+// Create opens a PR and assigns reviewers. When files are given, owners of
+// those paths under the team's CODEOWNERS ruleset are preferred.
+func (s *PRService) Create(
+	ctx context.Context,
+	id domain.PullRequestID,
+	name string,
+	authorID domain.UserID,
+	files []string,
+) (domain.PullRequest, error) {
+	ctx, span := tracer.Start(ctx, "PRService.Create")
+	defer span.End()
+
+	var pr domain.PullRequest
+	err := s.withinTx(ctx, func(ctx context.Context, repos domain.Repositories) error {
+		var err error
+		pr, err = s.create(ctx, repos, id, name, authorID, files, false)
+		return err
+	})
 	if err != nil {
 		return domain.PullRequest{}, err
 	}
-	if exists {
-		return domain.PullRequest{}, domain.ErrPullRequestExists
+
+	s.notifyReviewers(ctx, pr, pr.AssignedReviewers, "", domain.AssignmentReasonCreate)
+	s.recordStaffing(ctx, pr)
+	return pr, nil
+}
+
+// CreateDraft opens a PR in DRAFT status; reviewers are assigned once it is
+// marked ready.
+func (s *PRService) CreateDraft(
+	ctx context.Context,
+	id domain.PullRequestID,
+	name string,
+	authorID domain.UserID,
+) (domain.PullRequest, error) {
+	ctx, span := tracer.Start(ctx, "PRService.CreateDraft")
+	defer span.End()
+
+	var pr domain.PullRequest
+	err := s.withinTx(ctx, func(ctx context.Context, repos domain.Repositories) error {
+		var err error
+		pr, err = s.create(ctx, repos, id, name, authorID, nil, true)
+		return err
+	})
+	if err != nil {
+		return domain.PullRequest{}, err
 	}
 
-	author, err := s.Users.GetByID(ctx, authorID)
+	return pr, nil
+}
+
+func (s *PRService) create(
+	ctx context.Context,
+	repos domain.Repositories,
+	id domain.PullRequestID,
+	name string,
+	authorID domain.UserID,
+	files []string,
+	draft bool,
+) (domain.PullRequest, error) {
+	exists, err := repos.Prs.Exists(ctx, id)
 	if err != nil {
 		return domain.PullRequest{}, err
 	}
+	if exists {
+		return domain.PullRequest{}, domain.ErrPullRequestExists
+	}
 
-	candidates, err := s.Users.ListActiveByTeam(ctx, author.TeamName)
+	author, err := repos.Users.GetByID(ctx, authorID)
 	if err != nil {
 		return domain.PullRequest{}, err
 	}
 
-	var filtered []domain.User
-	for _, u := range candidates {
-		if u.ID == author.ID {
-			continue
+	now := time.Now().UTC()
+	pr := domain.PullRequest{
+		ID:        id,
+		Name:      name,
+		AuthorID:  authorID,
+		Status:    domain.PRStatusDraft,
+		CreatedAt: now,
+		MergedAt:  nil,
+	}
+
+	if !draft {
+		pr.Status = domain.PRStatusOpen
+		pr.AssignedReviewers, pr.FallbackReviewers, pr.MatchedRules, err = s.pickReviewers(ctx, repos, author, files)
+		if err != nil {
+			return domain.PullRequest{}, err
 		}
-		filtered = append(filtered, u)
 	}
 
-	assigned := pickRandomReviewers(filtered, 2, s.Rand)
+	if err := repos.Prs.Create(ctx, pr); err != nil {
+		return domain.PullRequest{}, err
+	}
 
-	now := time.Now().UTC()
-	pr := domain.PullRequest{
-		ID:                id,
-		Name:              name,
+	created, err := newOutboxEvent(domain.OutboxPRCreated, prCreatedPayload{
+		PullRequestID:     id,
+		PullRequestName:   name,
 		AuthorID:          authorID,
-		Status:            domain.PRStatusOpen,
-		AssignedReviewers: assigned,
+		Status:            pr.Status,
+		AssignedReviewers: pr.AssignedReviewers,
 		CreatedAt:         now,
-		MergedAt:          nil,
+	}, now)
+	if err != nil {
+		return domain.PullRequest{}, err
+	}
+	if err := emit(ctx, repos, created); err != nil {
+		return domain.PullRequest{}, err
+	}
+
+	if err := recordAssignments(ctx, repos, pr, domain.AssignmentReasonCreate, now); err != nil {
+		return domain.PullRequest{}, err
+	}
+
+	return pr, nil
+}
+
+// pickReviewers selects the initial reviewers for a PR by author.
+func (s *PRService) pickReviewers(
+	ctx context.Context,
+	repos domain.Repositories,
+	author domain.User,
+	files []string,
+) ([]domain.UserID, map[domain.UserID]domain.TeamName, map[domain.UserID]string, error) {
+	settings, err := repos.Teams.GetSettings(ctx, author.TeamName)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+
+	exclude := map[domain.UserID]struct{}{author.ID: {}}
+	need := max(settings.ReviewersCount, settings.MinPoolSize)
+	pools, total, err := loadPools(ctx, repos, settings, need, exclude)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	if total < settings.MinPoolSize {
+		return nil, nil, nil, domain.ErrPoolTooSmall
+	}
+
+	selector, err := s.selectorFor(settings.Strategy, repos.Prs)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+
+	rules, err := s.loadCodeowners(ctx, repos, author.TeamName, files)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+
+	return pickWithOwners(ctx, selector, pools, rules, files, settings.ReviewersCount)
+}
+
+// recordAssignments appends ASSIGNED history events and outbox events for
+// every reviewer of pr. The author is the actor unless the context names one.
+func recordAssignments(
+	ctx context.Context,
+	repos domain.Repositories,
+	pr domain.PullRequest,
+	reason domain.AssignmentReason,
+	now time.Time,
+) error {
+	actor := domain.ActorFromContext(ctx)
+	if actor == "" {
+		actor = pr.AuthorID
+	}
+
+	events := make([]domain.AssignmentEvent, 0, len(pr.AssignedReviewers))
+	outbox := make([]domain.OutboxEvent, 0, len(pr.AssignedReviewers))
+	for _, reviewerID := range pr.AssignedReviewers {
+		events = append(events, domain.AssignmentEvent{
+			PullRequestID: pr.ID,
+			Type:          domain.AssignmentEventAssigned,
+			UserID:        reviewerID,
+			Actor:         actor,
+			Reason:        reason,
+			CreatedAt:     now,
+		})
+
+		ev, err := newOutboxEvent(domain.OutboxReviewerAssigned, reviewerAssignedPayload{
+			PullRequestID: pr.ID,
+			ReviewerID:    reviewerID,
+			Reason:        reason,
+		}, now)
+		if err != nil {
+			return err
+		}
+		outbox = append(outbox, ev)
+	}
+	if err := repos.Prs.AppendAssignmentEvents(ctx, events); err != nil {
+		return err
+	}
+
+	return emit(ctx, repos, outbox...)
+}
+
+// loadCodeowners returns an empty ruleset when no files are given or the team
+// has none uploaded.
+func (s *PRService) loadCodeowners(
+	ctx context.Context,
+	repos domain.Repositories,
+	team domain.TeamName,
+	files []string,
+) (codeowners.Ruleset, error) {
+	if len(files) == 0 {
+		return codeowners.Ruleset{}, nil
 	}
 
-	if err := s.Prs.Create(ctx, pr); err != nil {
+	c, err := repos.Teams.GetCodeowners(ctx, team)
+	if errors.Is(err, domain.ErrNotFound) {
+		return codeowners.Ruleset{}, nil
+	}
+	if err != nil {
+		return codeowners.Ruleset{}, err
+	}
+
+	rules, err := codeowners.Parse(c.Content)
+	if err != nil {
+		return codeowners.Ruleset{}, fmt.Errorf("%w: %v", domain.ErrInvalidCodeowners, err)
+	}
+	return rules, nil
+}
+
+// Merge marks the PR merged once the author's team approval requirement is
+// met. override skips that check, for admins and for merges that already
+// happened in the VCS.
+func (s *PRService) Merge(ctx context.Context, id domain.PullRequestID, override bool) (domain.PullRequest, error) {
+	ctx, span := tracer.Start(ctx, "PRService.Merge")
+	defer span.End()
+
+	var pr domain.PullRequest
+	err := s.withinTx(ctx, func(ctx context.Context, repos domain.Repositories) error {
+		var err error
+		pr, err = repos.Prs.GetForUpdate(ctx, id)
+		if err != nil {
+			return err
+		}
+
+		if pr.Status == domain.PRStatusMerged {
+			return nil
+		}
+		if !pr.Status.CanTransitionTo(domain.PRStatusMerged) {
+			return domain.ErrInvalidTransition
+		}
+
+		if !override {
+			required, err := requiredApprovals(ctx, repos, pr)
+			if err != nil {
+				return err
+			}
+			if pr.Approvals() < required {
+				return domain.ErrNotEnoughApprovals
+			}
+		}
+
+		now := time.Now().UTC()
+		if err := repos.Prs.MarkMerged(ctx, id, now); err != nil {
+			return err
+		}
+
+		pr.Status = domain.PRStatusMerged
+		pr.MergedAt = &now
+
+		merged, err := newOutboxEvent(domain.OutboxPRMerged, prMergedPayload{
+			PullRequestID: id,
+			MergedAt:      now,
+		}, now)
+		if err != nil {
+			return err
+		}
+		return emit(ctx, repos, merged)
+	})
+	if err != nil {
 		return domain.PullRequest{}, err
 	}
 
 	return pr, nil
 }
 
-func (s *PRService) Merge(ctx context.Context, id domain.PullRequestID) (domain.PullRequest, error) {
-	pr, err := s.Prs.Get(ctx, id)
+// Ready moves a draft PR to OPEN and assigns its reviewers. Files play the
+// same role as in Create.
+func (s *PRService) Ready(ctx context.Context, id domain.PullRequestID, files []string) (domain.PullRequest, error) {
+	ctx, span := tracer.Start(ctx, "PRService.Ready")
+	defer span.End()
+
+	var pr domain.PullRequest
+	var assigned []domain.UserID
+	err := s.withinTx(ctx, func(ctx context.Context, repos domain.Repositories) error {
+		var err error
+		pr, err = repos.Prs.GetForUpdate(ctx, id)
+		if err != nil {
+			return err
+		}
+
+		if pr.Status == domain.PRStatusOpen {
+			return nil
+		}
+		if pr.Status != domain.PRStatusDraft {
+			return domain.ErrInvalidTransition
+		}
+
+		author, err := repos.Users.GetByID(ctx, pr.AuthorID)
+		if err != nil {
+			return err
+		}
+
+		pr.AssignedReviewers, pr.FallbackReviewers, pr.MatchedRules, err = s.pickReviewers(ctx, repos, author, files)
+		if err != nil {
+			return err
+		}
+		if err := repos.Prs.AddReviewers(ctx, pr); err != nil {
+			return err
+		}
+
+		now := time.Now().UTC()
+		if err := transition(ctx, repos, &pr, domain.PRStatusOpen, now); err != nil {
+			return err
+		}
+		assigned = pr.AssignedReviewers
+		return recordAssignments(ctx, repos, pr, domain.AssignmentReasonReadyForReview, now)
+	})
 	if err != nil {
 		return domain.PullRequest{}, err
 	}
 
-	if pr.Status == domain.PRStatusMerged {
-		return pr, nil
+	s.notifyReviewers(ctx, pr, assigned, "", domain.AssignmentReasonReadyForReview)
+	if assigned != nil {
+		s.recordStaffing(ctx, pr)
 	}
+	return pr, nil
+}
 
-	now := time.Now().UTC()
-	if err := s.Prs.MarkMerged(ctx, id, now); err != nil {
+// Close abandons a draft or open PR without merging it.
+func (s *PRService) Close(ctx context.Context, id domain.PullRequestID) (domain.PullRequest, error) {
+	ctx, span := tracer.Start(ctx, "PRService.Close")
+	defer span.End()
+
+	var pr domain.PullRequest
+	err := s.withinTx(ctx, func(ctx context.Context, repos domain.Repositories) error {
+		var err error
+		pr, err = repos.Prs.GetForUpdate(ctx, id)
+		if err != nil {
+			return err
+		}
+
+		if pr.Status == domain.PRStatusClosed {
+			return nil
+		}
+		return transition(ctx, repos, &pr, domain.PRStatusClosed, time.Now().UTC())
+	})
+	if err != nil {
 		return domain.PullRequest{}, err
 	}
 
-	pr.Status = domain.PRStatusMerged
-	pr.MergedAt = &now
+	return pr, nil
+}
+
+// Reopen brings a closed PR back to the status it was closed from, keeping
+// its previous reviewers. PRs closed before that status was recorded go to
+// OPEN if they have reviewers and to DRAFT otherwise.
+func (s *PRService) Reopen(ctx context.Context, id domain.PullRequestID) (domain.PullRequest, error) {
+	ctx, span := tracer.Start(ctx, "PRService.Reopen")
+	defer span.End()
+
+	var pr domain.PullRequest
+	err := s.withinTx(ctx, func(ctx context.Context, repos domain.Repositories) error {
+		var err error
+		pr, err = repos.Prs.GetForUpdate(ctx, id)
+		if err != nil {
+			return err
+		}
+
+		if pr.Status == domain.PRStatusOpen || pr.Status == domain.PRStatusDraft {
+			return nil
+		}
+
+		to := pr.ClosedFrom
+		if to == "" {
+			to = domain.PRStatusOpen
+			if len(pr.AssignedReviewers) == 0 {
+				to = domain.PRStatusDraft
+			}
+		}
+		return transition(ctx, repos, &pr, to, time.Now().UTC())
+	})
+	if err != nil {
+		return domain.PullRequest{}, err
+	}
 
 	return pr, nil
 }
 
+// transition moves pr to status to if the state machine allows it.
+func transition(
+	ctx context.Context,
+	repos domain.Repositories,
+	pr *domain.PullRequest,
+	to domain.PRStatus,
+	now time.Time,
+) error {
+	if !pr.Status.CanTransitionTo(to) {
+		return domain.ErrInvalidTransition
+	}
+
+	var closedAt *time.Time
+	if to == domain.PRStatusClosed {
+		closedAt = &now
+	}
+	if err := repos.Prs.SetStatus(ctx, pr.ID, to, closedAt); err != nil {
+		return err
+	}
+
+	changed, err := newOutboxEvent(domain.OutboxPRStatusChanged, prStatusChangedPayload{
+		PullRequestID: pr.ID,
+		From:          pr.Status,
+		To:            to,
+		ChangedAt:     now,
+	}, now)
+	if err != nil {
+		return err
+	}
+
+	pr.ClosedFrom = ""
+	if to == domain.PRStatusClosed {
+		pr.ClosedFrom = pr.Status
+	}
+	pr.Status = to
+	pr.ClosedAt = closedAt
+	return emit(ctx, repos, changed)
+}
+
+// requiredApprovals reads the gate from the author's team. PRs whose author
+// is unknown to the service have no gate.
+func requiredApprovals(ctx context.Context, repos domain.Repositories, pr domain.PullRequest) (int, error) {
+	author, err := repos.Users.GetByID(ctx, pr.AuthorID)
+	if errors.Is(err, domain.ErrNotFound) {
+		return 0, nil
+	}
+	if err != nil {
+		return 0, err
+	}
+
+	settings, err := repos.Teams.GetSettings(ctx, author.TeamName)
+	if err != nil {
+		return 0, err
+	}
+	return settings.RequiredApprovals, nil
+}
+
+// Review records a reviewer's verdict on an open PR.
+func (s *PRService) Review(
+	ctx context.Context,
+	prID domain.PullRequestID,
+	reviewerID domain.UserID,
+	state domain.ReviewState,
+) (domain.PullRequest, error) {
+	ctx, span := tracer.Start(ctx, "PRService.Review")
+	defer span.End()
+
+	if !state.Valid() {
+		return domain.PullRequest{}, domain.ErrInvalidReview
+	}
+
+	var pr domain.PullRequest
+	err := s.withinTx(ctx, func(ctx context.Context, repos domain.Repositories) error {
+		var err error
+		pr, err = repos.Prs.GetForUpdate(ctx, prID)
+		if err != nil {
+			return err
+		}
+
+		if pr.Status == domain.PRStatusMerged {
+			return domain.ErrPullRequestMerged
+		}
+		if pr.Status == domain.PRStatusClosed {
+			return domain.ErrPullRequestClosed
+		}
+		if !slices.Contains(pr.AssignedReviewers, reviewerID) {
+			return domain.ErrNotAssigned
+		}
+
+		if err := repos.Prs.SetReviewState(ctx, prID, reviewerID, state); err != nil {
+			return err
+		}
+
+		if pr.ReviewStates == nil {
+			pr.ReviewStates = make(map[domain.UserID]domain.ReviewState)
+		}
+		pr.ReviewStates[reviewerID] = state
+		return nil
+	})
+	if err != nil {
+		return domain.PullRequest{}, err
+	}
+
+	return pr, nil
+}
+
+// Reassign replaces oldUserID on the PR. The returned reviewer is empty when
+// the pick turned out to be assigned already and oldUserID was only removed.
 func (s *PRService) Reassign(ctx context.Context, prID domain.PullRequestID, oldUserID domain.UserID) (domain.PullRequest, domain.UserID, error) {
-	pr, err := s.Prs.Get(ctx, prID)
+	ctx, span := tracer.Start(ctx, "PRService.Reassign")
+	defer span.End()
+
+	var pr domain.PullRequest
+	var newReviewer domain.UserID
+	err := s.withinTx(ctx, func(ctx context.Context, repos domain.Repositories) error {
+		var err error
+		pr, newReviewer, err = s.reassign(ctx, repos, prID, oldUserID, domain.AssignmentReasonManualReassign)
+		return err
+	})
+	if err != nil {
+		if noReplacement(err) {
+			s.recordReassignments(domain.AssignmentReasonManualReassign, 0, 1)
+		}
+		return domain.PullRequest{}, "", err
+	}
+	if newReviewer == "" {
+		return pr, "", nil
+	}
+	s.recordReassignments(domain.AssignmentReasonManualReassign, 1, 0)
+
+	s.notifyReviewers(ctx, pr, []domain.UserID{newReviewer}, oldUserID, domain.AssignmentReasonManualReassign)
+	return pr, newReviewer, nil
+}
+
+// reassign expects to run inside a unit of work: the PR row stays locked
+// until it completes, so concurrent reassignments are serialized.
+func (s *PRService) reassign(
+	ctx context.Context,
+	repos domain.Repositories,
+	prID domain.PullRequestID,
+	oldUserID domain.UserID,
+	reason domain.AssignmentReason,
+) (domain.PullRequest, domain.UserID, error) {
+	pr, err := repos.Prs.GetForUpdate(ctx, prID)
 	if err != nil {
 		return domain.PullRequest{}, "", err
 	}
@@ -100,6 +595,9 @@ func (s *PRService) Reassign(ctx context.Context, prID domain.PullRequestID, old
 	if pr.Status == domain.PRStatusMerged {
 		return domain.PullRequest{}, "", domain.ErrPullRequestMerged
 	}
+	if pr.Status == domain.PRStatusClosed {
+		return domain.PullRequest{}, "", domain.ErrPullRequestClosed
+	}
 
 	found := false
 	for _, r := range pr.AssignedReviewers {
@@ -112,45 +610,117 @@ func (s *PRService) Reassign(ctx context.Context, prID domain.PullRequestID, old
 		return domain.PullRequest{}, "", domain.ErrNotAssigned
 	}
 
-	oldUser, err := s.Users.GetByID(ctx, oldUserID)
+	oldUser, err := repos.Users.GetByID(ctx, oldUserID)
 	if err != nil {
 		return domain.PullRequest{}, "", err
 	}
 
-	candidates, err := s.Users.ListActiveByTeam(ctx, oldUser.TeamName)
+	settings, err := repos.Teams.GetSettings(ctx, oldUser.TeamName)
 	if err != nil {
 		return domain.PullRequest{}, "", err
 	}
 
-	assignedSet := make(map[domain.UserID]struct{}, len(pr.AssignedReviewers))
+	exclude := make(map[domain.UserID]struct{}, len(pr.AssignedReviewers)+1)
+	exclude[pr.AuthorID] = struct{}{}
 	for _, r := range pr.AssignedReviewers {
-		assignedSet[r] = struct{}{}
+		exclude[r] = struct{}{}
 	}
 
-	var filtered []domain.User
-	for _, u := range candidates {
-		if u.ID == oldUserID {
-			continue
-		}
-		if u.ID == pr.AuthorID {
-			continue
-		}
-		if _, ok := assignedSet[u.ID]; ok {
-			continue
-		}
-		filtered = append(filtered, u)
+	pools, total, err := loadPools(ctx, repos, settings, max(1, settings.MinPoolSize), exclude)
+	if err != nil {
+		return domain.PullRequest{}, "", err
+	}
+	if total == 0 {
+		return domain.PullRequest{}, "", domain.ErrNoCandidate
+	}
+	if total < settings.MinPoolSize {
+		return domain.PullRequest{}, "", domain.ErrPoolTooSmall
+	}
+
+	selector, err := s.selectorFor(settings.Strategy, repos.Prs)
+	if err != nil {
+		return domain.PullRequest{}, "", err
 	}
 
-	if len(filtered) == 0 {
+	picked, fallbacks, err := pickFromPools(ctx, selector, pools, 1)
+	if err != nil {
+		return domain.PullRequest{}, "", err
+	}
+	if len(picked) == 0 {
 		return domain.PullRequest{}, "", domain.ErrNoCandidate
 	}
+	newReviewer := picked[0]
 
-	newReviewer := pickRandomReviewers(filtered, 1, s.Rand)[0]
+	// A replacement taken from a fallback reviewer's own team stays a fallback slot.
+	fallbackTeam, ok := fallbacks[newReviewer]
+	if !ok {
+		fallbackTeam = pr.FallbackReviewers[oldUserID]
+	}
 
-	if err := s.Prs.ReplaceReviewer(ctx, prID, oldUserID, newReviewer); err != nil {
+	added, err := repos.Prs.ReplaceReviewer(ctx, prID, oldUserID, newReviewer, fallbackTeam)
+	if err != nil {
 		return domain.PullRequest{}, "", err
 	}
 
+	now := time.Now().UTC()
+
+	// The picked reviewer can already hold a slot if the PR changed since it
+	// was read; the old reviewer then leaves without a successor, which is
+	// reported as an empty new reviewer.
+	if !added {
+		if err := repos.Prs.AppendAssignmentEvents(ctx, []domain.AssignmentEvent{{
+			PullRequestID: prID,
+			Type:          domain.AssignmentEventUnassigned,
+			UserID:        oldUserID,
+			Actor:         domain.ActorFromContext(ctx),
+			Reason:        reason,
+			CreatedAt:     now,
+		}}); err != nil {
+			return domain.PullRequest{}, "", err
+		}
+		pr, err = repos.Prs.Get(ctx, prID)
+		if err != nil {
+			return domain.PullRequest{}, "", err
+		}
+		return pr, "", nil
+	}
+
+	if err := repos.Prs.AppendAssignmentEvents(ctx, []domain.AssignmentEvent{{
+		PullRequestID: prID,
+		Type:          domain.AssignmentEventReplaced,
+		UserID:        oldUserID,
+		ReplacedBy:    newReviewer,
+		Actor:         domain.ActorFromContext(ctx),
+		Reason:        reason,
+		CreatedAt:     now,
+	}}); err != nil {
+		return domain.PullRequest{}, "", err
+	}
+
+	replaced, err := newOutboxEvent(domain.OutboxReviewerReplaced, reviewerReplacedPayload{
+		PullRequestID: prID,
+		OldReviewerID: oldUserID,
+		NewReviewerID: newReviewer,
+		Reason:        reason,
+		Actor:         domain.ActorFromContext(ctx),
+	}, now)
+	if err != nil {
+		return domain.PullRequest{}, "", err
+	}
+	if err := emit(ctx, repos, replaced); err != nil {
+		return domain.PullRequest{}, "", err
+	}
+
+	delete(pr.FallbackReviewers, oldUserID)
+	delete(pr.MatchedRules, oldUserID)
+	delete(pr.ReviewStates, oldUserID)
+	if fallbackTeam != "" {
+		if pr.FallbackReviewers == nil {
+			pr.FallbackReviewers = make(map[domain.UserID]domain.TeamName)
+		}
+		pr.FallbackReviewers[newReviewer] = fallbackTeam
+	}
+
 	for i, r := range pr.AssignedReviewers {
 		if r == oldUserID {
 			pr.AssignedReviewers[i] = newReviewer
@@ -161,11 +731,39 @@ func (s *PRService) Reassign(ctx context.Context, prID domain.PullRequestID, old
 	return pr, newReviewer, nil
 }
 
+func (s *PRService) Get(ctx context.Context, id domain.PullRequestID) (domain.PullRequest, error) {
+	ctx, span := tracer.Start(ctx, "PRService.Get")
+	defer span.End()
+
+	return s.Prs.Get(ctx, id)
+}
+
 func (s *PRService) ListByReviewer(ctx context.Context, reviewerID domain.UserID) ([]domain.PullRequestShort, error) {
+	ctx, span := tracer.Start(ctx, "PRService.ListByReviewer")
+	defer span.End()
+
 	return s.Prs.ListByReviewer(ctx, reviewerID)
 }
 
+func (s *PRService) History(ctx context.Context, id domain.PullRequestID) ([]domain.AssignmentEvent, error) {
+	ctx, span := tracer.Start(ctx, "PRService.History")
+	defer span.End()
+
+	exists, err := s.Prs.Exists(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if !exists {
+		return nil, domain.ErrNotFound
+	}
+
+	return s.Prs.ListAssignmentEvents(ctx, id)
+}
+
 func (s *PRService) StatsAssignmentsByUser(ctx context.Context) (map[domain.UserID]int, error) {
+	ctx, span := tracer.Start(ctx, "PRService.StatsAssignmentsByUser")
+	defer span.End()
+
 	return s.Prs.StatsAssignmentsByUser(ctx)
 }
 
@@ -173,38 +771,125 @@ func (s *PRService) BulkDeactivateAndReassign(
 	ctx context.Context,
 	userIDs []domain.UserID,
 ) error {
+	ctx, span := tracer.Start(ctx, "PRService.BulkDeactivateAndReassign")
+	defer span.End()
+
 	for _, uid := range userIDs {
-		_, err := s.Users.GetByID(ctx, uid)
+		var handovers []handover
+		var stuck int
+		err := s.withinTx(ctx, func(ctx context.Context, repos domain.Repositories) error {
+			var err error
+			handovers, stuck, err = s.deactivateAndReassign(ctx, repos, uid)
+			return err
+		})
 		if err != nil {
-			if errors.Is(err, domain.ErrNotFound) {
-				continue
-			}
 			return err
 		}
+		s.recordReassignments(domain.AssignmentReasonBulkDeactivation, len(handovers), stuck)
+		s.notifyHandovers(ctx, handovers, domain.AssignmentReasonBulkDeactivation)
+	}
 
-		if _, err := s.Users.SetIsActive(ctx, uid, false); err != nil {
-			return err
+	return nil
+}
+
+func (s *PRService) deactivateAndReassign(
+	ctx context.Context,
+	repos domain.Repositories,
+	uid domain.UserID,
+) ([]handover, int, error) {
+	_, err := repos.Users.GetByID(ctx, uid)
+	if err != nil {
+		if errors.Is(err, domain.ErrNotFound) {
+			return nil, 0, nil
 		}
+		return nil, 0, err
+	}
+
+	if _, err := repos.Users.SetIsActive(ctx, uid, false, domain.ActorFromContext(ctx)); err != nil {
+		return nil, 0, err
+	}
+
+	return s.reassignOpenReviews(ctx, repos, uid, domain.AssignmentReasonBulkDeactivation)
+}
 
-		prs, err := s.Prs.ListByReviewer(ctx, uid)
+// ReassignUnavailable hands over the open reviews of users whose
+// unavailability period has started and asked for it. A period that fails
+// is left for the next run without holding up the others; the failures are
+// returned together.
+func (s *PRService) ReassignUnavailable(ctx context.Context) error {
+	ctx, span := tracer.Start(ctx, "PRService.ReassignUnavailable")
+	defer span.End()
+
+	now := time.Now().UTC()
+	due, err := s.Users.ListDueReassignments(ctx, now)
+	if err != nil {
+		return err
+	}
+
+	var errs []error
+	for _, u := range due {
+		var handovers []handover
+		var stuck int
+		err := s.withinTx(ctx, func(ctx context.Context, repos domain.Repositories) error {
+			var err error
+			handovers, stuck, err = s.reassignOpenReviews(ctx, repos, u.UserID, domain.AssignmentReasonUnavailable)
+			if err != nil {
+				return err
+			}
+			return repos.Users.MarkReassigned(ctx, u.ID, now)
+		})
 		if err != nil {
-			return err
+			errs = append(errs, fmt.Errorf("unavailability %d of %s: %w", u.ID, u.UserID, err))
+			continue
 		}
+		s.recordReassignments(domain.AssignmentReasonUnavailable, len(handovers), stuck)
+		s.notifyHandovers(ctx, handovers, domain.AssignmentReasonUnavailable)
+	}
 
-		for _, pr := range prs {
-			if pr.Status != domain.PRStatusOpen {
+	return errors.Join(errs...)
+}
+
+// handover is a review moved from one reviewer to another.
+type handover struct {
+	pr       domain.PullRequest
+	from, to domain.UserID
+}
+
+// reassignOpenReviews moves uid off every open PR it reviews, leaving PRs
+// without a suitable replacement untouched. It returns the reviews handed
+// over and how many found no candidate.
+func (s *PRService) reassignOpenReviews(
+	ctx context.Context,
+	repos domain.Repositories,
+	uid domain.UserID,
+	reason domain.AssignmentReason,
+) (handovers []handover, stuck int, err error) {
+	prs, err := repos.Prs.ListByReviewer(ctx, uid)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	for _, pr := range prs {
+		if pr.Status != domain.PRStatusOpen {
+			continue
+		}
+
+		updated, newReviewer, err := s.reassign(ctx, repos, pr.ID, uid, reason)
+		if err != nil {
+			if noReplacement(err) {
+				stuck++
 				continue
 			}
-
-			_, _, err := s.Reassign(ctx, pr.ID, uid)
-			if err != nil {
-				if errors.Is(err, domain.ErrNoCandidate) || errors.Is(err, domain.ErrPullRequestMerged) {
-					continue
-				}
-				return err
+			if errors.Is(err, domain.ErrPullRequestMerged) ||
+				errors.Is(err, domain.ErrPullRequestClosed) {
+				continue
 			}
+			return nil, 0, err
+		}
+		if newReviewer != "" {
+			handovers = append(handovers, handover{pr: updated, from: uid, to: newReviewer})
 		}
 	}
 
-	return nil
+	return handovers, stuck, nil
 }
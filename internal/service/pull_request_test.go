@@ -2,132 +2,84 @@ package service
 
 import (
 	"context"
+	"errors"
 	"math/rand"
-	"pr-reviewer-service/internal/domain"
+	"slices"
+	"strings"
 	"testing"
 	"time"
+
+	"pr-reviewer-service/internal/domain"
+	"pr-reviewer-service/internal/repository/memory"
 )
 
-type fakeUserRepo struct {
-	users map[domain.UserID]domain.User
+// testRepos are memory repositories over one store. The repotest contract
+// suite holds them to the same behaviour as the SQL backends, so service
+// tests need no hand-rolled fakes.
+type testRepos struct {
+	store *memory.Store
+	teams *memory.TeamRepo
+	users *memory.UserRepo
+	prs   *memory.PullRequestRepo
 }
 
-func newFakeUserRepo() *fakeUserRepo {
-	return &fakeUserRepo{
-		users: make(map[domain.UserID]domain.User),
+// newTestRepos stores users, creating their teams first.
+func newTestRepos(t *testing.T, users []domain.User) testRepos {
+	t.Helper()
+	store := memory.NewStore()
+	r := testRepos{
+		store: store,
+		teams: memory.NewTeamRepo(store),
+		users: memory.NewUserRepo(store),
+		prs:   memory.NewPullRequestRepo(store),
 	}
+	r.addUsers(t, users)
+	return r
 }
 
-func (r *fakeUserRepo) UpsertUsers(ctx context.Context, users []domain.User) error {
+func (r testRepos) addUsers(t *testing.T, users []domain.User) {
+	t.Helper()
+	ctx := context.Background()
 	for _, u := range users {
-		r.users[u.ID] = u
-	}
-	return nil
-}
-
-func (r *fakeUserRepo) GetByID(ctx context.Context, id domain.UserID) (domain.User, error) {
-	u, ok := r.users[id]
-	if !ok {
-		return domain.User{}, domain.ErrNotFound
-	}
-	return u, nil
-}
-func (r *fakePRRepo) StatsAssignmentsByUser(
-	ctx context.Context,
-) (map[domain.UserID]int, error) {
-	return map[domain.UserID]int{}, nil
-}
-
-func (r *fakeUserRepo) SetIsActive(ctx context.Context, id domain.UserID, isActive bool) (domain.User, error) {
-	u, ok := r.users[id]
-	if !ok {
-		return domain.User{}, domain.ErrNotFound
-	}
-	u.IsActive = isActive
-	r.users[id] = u
-	return u, nil
-}
-
-func (r *fakeUserRepo) ListActiveByTeam(ctx context.Context, teamName domain.TeamName) ([]domain.User, error) {
-	var res []domain.User
-	for _, u := range r.users {
-		if u.TeamName == teamName && u.IsActive {
-			res = append(res, u)
+		if err := r.teams.CreateTeam(ctx, u.TeamName); err != nil {
+			t.Fatalf("create team %s: %v", u.TeamName, err)
 		}
 	}
-	return res, nil
-}
-
-type fakePRRepo struct {
-	prs map[domain.PullRequestID]domain.PullRequest
-}
-
-func newFakePRRepo() *fakePRRepo {
-	return &fakePRRepo{
-		prs: make(map[domain.PullRequestID]domain.PullRequest),
+	if err := r.users.UpsertUsers(ctx, users); err != nil {
+		t.Fatalf("upsert users: %v", err)
 	}
 }
 
-func (r *fakePRRepo) Create(ctx context.Context, pr domain.PullRequest) error {
-	r.prs[pr.ID] = pr
-	return nil
-}
-
-func (r *fakePRRepo) Exists(ctx context.Context, id domain.PullRequestID) (bool, error) {
-	_, ok := r.prs[id]
-	return ok, nil
-}
-
-func (r *fakePRRepo) Get(ctx context.Context, id domain.PullRequestID) (domain.PullRequest, error) {
-	pr, ok := r.prs[id]
-	if !ok {
-		return domain.PullRequest{}, domain.ErrNotFound
+func (r testRepos) addPR(t *testing.T, pr domain.PullRequest) {
+	t.Helper()
+	if err := r.prs.Create(context.Background(), pr); err != nil {
+		t.Fatalf("create pr %s: %v", pr.ID, err)
 	}
-	return pr, nil
 }
 
-func (r *fakePRRepo) MarkMerged(ctx context.Context, id domain.PullRequestID, mergedAt time.Time) error {
-	pr, ok := r.prs[id]
-	if !ok {
-		return domain.ErrNotFound
+func (r testRepos) setSettings(t *testing.T, settings domain.TeamSettings) {
+	t.Helper()
+	if err := r.teams.UpsertSettings(context.Background(), settings); err != nil {
+		t.Fatalf("upsert settings for %s: %v", settings.TeamName, err)
 	}
-	pr.Status = domain.PRStatusMerged
-	pr.MergedAt = &mergedAt
-	r.prs[id] = pr
-	return nil
 }
 
-func (r *fakePRRepo) ReplaceReviewer(ctx context.Context, prID domain.PullRequestID, oldUserID, newUserID domain.UserID) error {
-	pr, ok := r.prs[prID]
-	if !ok {
-		return domain.ErrNotFound
-	}
-	for i, rID := range pr.AssignedReviewers {
-		if rID == oldUserID {
-			pr.AssignedReviewers[i] = newUserID
-			break
-		}
+func (r testRepos) getPR(t *testing.T, id domain.PullRequestID) domain.PullRequest {
+	t.Helper()
+	pr, err := r.prs.Get(context.Background(), id)
+	if err != nil {
+		t.Fatalf("get pr %s: %v", id, err)
 	}
-	r.prs[prID] = pr
-	return nil
+	return pr
 }
 
-func (r *fakePRRepo) ListByReviewer(ctx context.Context, reviewerID domain.UserID) ([]domain.PullRequestShort, error) {
-	var res []domain.PullRequestShort
-	for _, pr := range r.prs {
-		for _, rID := range pr.AssignedReviewers {
-			if rID == reviewerID {
-				res = append(res, domain.PullRequestShort{
-					ID:       pr.ID,
-					Name:     pr.Name,
-					AuthorID: pr.AuthorID,
-					Status:   pr.Status,
-				})
-				break
-			}
-		}
+func (r testRepos) events(t *testing.T, id domain.PullRequestID) []domain.AssignmentEvent {
+	t.Helper()
+	events, err := r.prs.ListAssignmentEvents(context.Background(), id)
+	if err != nil {
+		t.Fatalf("list events for %s: %v", id, err)
 	}
-	return res, nil
+	return events
 }
 
 func TestPRService_Create_AssignsZeroOneTwoReviewers(t *testing.T) {
@@ -136,27 +88,19 @@ func TestPRService_Create_AssignsZeroOneTwoReviewers(t *testing.T) {
 	team := domain.TeamName("backend")
 	authorID := domain.UserID("u1")
 
-	makeUsers := func(users []domain.User) *fakeUserRepo {
-		repo := newFakeUserRepo()
-		for _, u := range users {
-			repo.users[u.ID] = u
-		}
-		return repo
-	}
-
 	t.Run("no available candidates -> 0 reviewers", func(t *testing.T) {
-		usersRepo := makeUsers([]domain.User{
+		repos := newTestRepos(t, []domain.User{
 			{ID: authorID, Username: "Alice", TeamName: team, IsActive: true},
 		})
-		prRepo := newFakePRRepo()
 
 		svc := &PRService{
-			Users: usersRepo,
-			Prs:   prRepo,
+			Teams: repos.teams,
+			Users: repos.users,
+			Prs:   repos.prs,
 			Rand:  rand.New(rand.NewSource(1)),
 		}
 
-		pr, err := svc.Create(ctx, domain.PullRequestID("pr0"), "PR 0", authorID)
+		pr, err := svc.Create(ctx, domain.PullRequestID("pr0"), "PR 0", authorID, nil)
 		if err != nil {
 			t.Fatalf("Create returned error: %v", err)
 		}
@@ -166,19 +110,19 @@ func TestPRService_Create_AssignsZeroOneTwoReviewers(t *testing.T) {
 	})
 
 	t.Run("one candidate -> 1 reviewer", func(t *testing.T) {
-		usersRepo := makeUsers([]domain.User{
+		repos := newTestRepos(t, []domain.User{
 			{ID: authorID, Username: "Alice", TeamName: team, IsActive: true},
 			{ID: "u2", Username: "Bob", TeamName: team, IsActive: true},
 		})
-		prRepo := newFakePRRepo()
 
 		svc := &PRService{
-			Users: usersRepo,
-			Prs:   prRepo,
+			Teams: repos.teams,
+			Users: repos.users,
+			Prs:   repos.prs,
 			Rand:  rand.New(rand.NewSource(2)),
 		}
 
-		pr, err := svc.Create(ctx, domain.PullRequestID("pr1"), "PR 1", authorID)
+		pr, err := svc.Create(ctx, domain.PullRequestID("pr1"), "PR 1", authorID, nil)
 		if err != nil {
 			t.Fatalf("Create returned error: %v", err)
 		}
@@ -191,7 +135,7 @@ func TestPRService_Create_AssignsZeroOneTwoReviewers(t *testing.T) {
 	})
 
 	t.Run("many candidates -> exactly 2 reviewers, all valid", func(t *testing.T) {
-		usersRepo := makeUsers([]domain.User{
+		repos := newTestRepos(t, []domain.User{
 			{ID: authorID, Username: "Alice", TeamName: team, IsActive: true},
 			{ID: "u2", Username: "Bob", TeamName: team, IsActive: true},
 			{ID: "u3", Username: "Carol", TeamName: team, IsActive: true},
@@ -199,15 +143,15 @@ func TestPRService_Create_AssignsZeroOneTwoReviewers(t *testing.T) {
 			{ID: "u5", Username: "Eve", TeamName: "other", IsActive: true},
 			{ID: "u6", Username: "Frank", TeamName: team, IsActive: false},
 		})
-		prRepo := newFakePRRepo()
 
 		svc := &PRService{
-			Users: usersRepo,
-			Prs:   prRepo,
+			Teams: repos.teams,
+			Users: repos.users,
+			Prs:   repos.prs,
 			Rand:  rand.New(rand.NewSource(3)),
 		}
 
-		pr, err := svc.Create(ctx, domain.PullRequestID("pr2"), "PR 2", authorID)
+		pr, err := svc.Create(ctx, domain.PullRequestID("pr2"), "PR 2", authorID, nil)
 		if err != nil {
 			t.Fatalf("Create returned error: %v", err)
 		}
@@ -220,7 +164,10 @@ func TestPRService_Create_AssignsZeroOneTwoReviewers(t *testing.T) {
 			if rID == authorID {
 				t.Fatalf("author must not be assigned as reviewer")
 			}
-			u := usersRepo.users[rID]
+			u, err := repos.users.GetByID(ctx, rID)
+			if err != nil {
+				t.Fatalf("GetByID returned error: %v", err)
+			}
 			if u.TeamName != team {
 				t.Fatalf("reviewer must be from same team, got team %s", u.TeamName)
 			}
@@ -238,13 +185,15 @@ func TestPRService_Create_AssignsZeroOneTwoReviewers(t *testing.T) {
 func TestPRService_Merge_Idempotent(t *testing.T) {
 	ctx := context.Background()
 
-	usersRepo := newFakeUserRepo()
-	prRepo := newFakePRRepo()
+	repos := newTestRepos(t, []domain.User{
+		{ID: "u1", Username: "Alice", TeamName: "backend", IsActive: true},
+		{ID: "u2", Username: "Bob", TeamName: "backend", IsActive: true},
+	})
 
 	id := domain.PullRequestID("pr-merge")
 	now := time.Now().UTC()
 
-	prRepo.prs[id] = domain.PullRequest{
+	repos.addPR(t, domain.PullRequest{
 		ID:                id,
 		Name:              "PR merge",
 		AuthorID:          domain.UserID("u1"),
@@ -252,15 +201,16 @@ func TestPRService_Merge_Idempotent(t *testing.T) {
 		AssignedReviewers: []domain.UserID{"u2"},
 		CreatedAt:         now,
 		MergedAt:          nil,
-	}
+	})
 
 	svc := &PRService{
-		Users: usersRepo,
-		Prs:   prRepo,
+		Teams: repos.teams,
+		Users: repos.users,
+		Prs:   repos.prs,
 		Rand:  rand.New(rand.NewSource(1)),
 	}
 
-	pr1, err := svc.Merge(ctx, id)
+	pr1, err := svc.Merge(ctx, id, false)
 	if err != nil {
 		t.Fatalf("Merge(1) returned error: %v", err)
 	}
@@ -273,7 +223,7 @@ func TestPRService_Merge_Idempotent(t *testing.T) {
 
 	mergedAt1 := *pr1.MergedAt
 
-	pr2, err := svc.Merge(ctx, id)
+	pr2, err := svc.Merge(ctx, id, false)
 	if err != nil {
 		t.Fatalf("Merge(2) returned error: %v", err)
 	}
@@ -291,9 +241,6 @@ func TestPRService_Merge_Idempotent(t *testing.T) {
 func TestPRService_Reassign_HappyPath(t *testing.T) {
 	ctx := context.Background()
 
-	usersRepo := newFakeUserRepo()
-	prRepo := newFakePRRepo()
-
 	team := domain.TeamName("team-review")
 
 	old := domain.User{ID: "u2", Username: "OldReviewer", TeamName: team, IsActive: true}
@@ -301,24 +248,23 @@ func TestPRService_Reassign_HappyPath(t *testing.T) {
 	new2 := domain.User{ID: "u4", Username: "Candidate2", TeamName: team, IsActive: true}
 	author := domain.User{ID: "u1", Username: "Author", TeamName: "another", IsActive: true}
 
-	usersRepo.users[author.ID] = author
-	usersRepo.users[old.ID] = old
-	usersRepo.users[new1.ID] = new1
-	usersRepo.users[new2.ID] = new2
+	other := domain.User{ID: "u5", Username: "OtherReviewer", TeamName: "another", IsActive: true}
+	repos := newTestRepos(t, []domain.User{author, old, new1, new2, other})
 
 	prID := domain.PullRequestID("pr-reassign")
 
-	prRepo.prs[prID] = domain.PullRequest{
+	repos.addPR(t, domain.PullRequest{
 		ID:                prID,
 		Name:              "PR reassign",
 		AuthorID:          author.ID,
 		Status:            domain.PRStatusOpen,
-		AssignedReviewers: []domain.UserID{old.ID, "u5"},
-	}
+		AssignedReviewers: []domain.UserID{old.ID, other.ID},
+	})
 
 	svc := &PRService{
-		Users: usersRepo,
-		Prs:   prRepo,
+		Teams: repos.teams,
+		Users: repos.users,
+		Prs:   repos.prs,
 		Rand:  rand.New(rand.NewSource(1)),
 	}
 
@@ -344,21 +290,24 @@ func TestPRService_Reassign_HappyPath(t *testing.T) {
 func TestPRService_Reassign_MergedPR(t *testing.T) {
 	ctx := context.Background()
 
-	usersRepo := newFakeUserRepo()
-	prRepo := newFakePRRepo()
+	repos := newTestRepos(t, []domain.User{
+		{ID: "u1", Username: "Alice", TeamName: "t", IsActive: true},
+		{ID: "u2", Username: "Bob", TeamName: "t", IsActive: true},
+	})
 
 	prID := domain.PullRequestID("pr-merged")
-	prRepo.prs[prID] = domain.PullRequest{
+	repos.addPR(t, domain.PullRequest{
 		ID:                prID,
 		Name:              "Merged PR",
 		AuthorID:          "u1",
 		Status:            domain.PRStatusMerged,
 		AssignedReviewers: []domain.UserID{"u2"},
-	}
+	})
 
 	svc := &PRService{
-		Users: usersRepo,
-		Prs:   prRepo,
+		Teams: repos.teams,
+		Users: repos.users,
+		Prs:   repos.prs,
 		Rand:  rand.New(rand.NewSource(1)),
 	}
 
@@ -374,25 +323,26 @@ func TestPRService_Reassign_MergedPR(t *testing.T) {
 func TestPRService_Reassign_NotAssigned(t *testing.T) {
 	ctx := context.Background()
 
-	usersRepo := newFakeUserRepo()
-	prRepo := newFakePRRepo()
-
 	team := domain.TeamName("t")
-	usersRepo.users["u2"] = domain.User{ID: "u2", TeamName: team, IsActive: true}
-	usersRepo.users["u3"] = domain.User{ID: "u3", TeamName: team, IsActive: true}
+	repos := newTestRepos(t, []domain.User{
+		{ID: "u1", TeamName: "other", IsActive: true},
+		{ID: "u2", TeamName: team, IsActive: true},
+		{ID: "u3", TeamName: team, IsActive: true},
+	})
 
 	prID := domain.PullRequestID("pr-not-assigned")
-	prRepo.prs[prID] = domain.PullRequest{
+	repos.addPR(t, domain.PullRequest{
 		ID:                prID,
 		Name:              "PR not assigned",
 		AuthorID:          "u1",
 		Status:            domain.PRStatusOpen,
 		AssignedReviewers: []domain.UserID{"u3"},
-	}
+	})
 
 	svc := &PRService{
-		Users: usersRepo,
-		Prs:   prRepo,
+		Teams: repos.teams,
+		Users: repos.users,
+		Prs:   repos.prs,
 		Rand:  rand.New(rand.NewSource(1)),
 	}
 
@@ -408,25 +358,26 @@ func TestPRService_Reassign_NotAssigned(t *testing.T) {
 func TestPRService_Reassign_NoCandidate(t *testing.T) {
 	ctx := context.Background()
 
-	usersRepo := newFakeUserRepo()
-	prRepo := newFakePRRepo()
-
 	team := domain.TeamName("t")
 	old := domain.User{ID: "u2", TeamName: team, IsActive: true}
-	usersRepo.users[old.ID] = old
+	repos := newTestRepos(t, []domain.User{
+		{ID: "u1", TeamName: team, IsActive: true},
+		old,
+	})
 
 	prID := domain.PullRequestID("pr-no-candidate")
-	prRepo.prs[prID] = domain.PullRequest{
+	repos.addPR(t, domain.PullRequest{
 		ID:                prID,
 		Name:              "PR no candidate",
 		AuthorID:          "u1",
 		Status:            domain.PRStatusOpen,
 		AssignedReviewers: []domain.UserID{old.ID},
-	}
+	})
 
 	svc := &PRService{
-		Users: usersRepo,
-		Prs:   prRepo,
+		Teams: repos.teams,
+		Users: repos.users,
+		Prs:   repos.prs,
 		Rand:  rand.New(rand.NewSource(1)),
 	}
 
@@ -438,3 +389,1005 @@ func TestPRService_Reassign_NoCandidate(t *testing.T) {
 		t.Fatalf("expected ErrNoCandidate, got %v", err)
 	}
 }
+
+func TestPRService_Create_LeastLoadedSelector(t *testing.T) {
+	ctx := context.Background()
+
+	team := domain.TeamName("backend")
+	repos := newTestRepos(t, []domain.User{
+		{ID: "u1", Username: "Alice", TeamName: team, IsActive: true},
+		{ID: "u2", Username: "Bob", TeamName: team, IsActive: true},
+		{ID: "u3", Username: "Carol", TeamName: team, IsActive: true},
+		{ID: "u4", Username: "Dave", TeamName: team, IsActive: true},
+	})
+
+	repos.addPR(t, domain.PullRequest{
+		ID:                "busy-1",
+		AuthorID:          "u1",
+		Status:            domain.PRStatusOpen,
+		AssignedReviewers: []domain.UserID{"u2", "u3"},
+	})
+	repos.addPR(t, domain.PullRequest{
+		ID:                "busy-2",
+		AuthorID:          "u1",
+		Status:            domain.PRStatusOpen,
+		AssignedReviewers: []domain.UserID{"u2"},
+	})
+	repos.addPR(t, domain.PullRequest{
+		ID:                "merged",
+		AuthorID:          "u1",
+		Status:            domain.PRStatusMerged,
+		AssignedReviewers: []domain.UserID{"u4", "u3"},
+	})
+
+	rnd := rand.New(rand.NewSource(1))
+	svc := &PRService{
+		Teams:    repos.teams,
+		Users:    repos.users,
+		Prs:      repos.prs,
+		Rand:     rnd,
+		Selector: LeastLoadedSelector{Prs: repos.prs, Rand: rnd},
+	}
+
+	pr, err := svc.Create(ctx, "pr-balanced", "PR balanced", "u1", nil)
+	if err != nil {
+		t.Fatalf("Create returned error: %v", err)
+	}
+	if len(pr.AssignedReviewers) != 2 {
+		t.Fatalf("expected 2 reviewers, got %d", len(pr.AssignedReviewers))
+	}
+
+	got := map[domain.UserID]bool{}
+	for _, rID := range pr.AssignedReviewers {
+		got[rID] = true
+	}
+	if !got["u4"] || !got["u3"] {
+		t.Fatalf("expected least loaded reviewers u3 and u4, got %v", pr.AssignedReviewers)
+	}
+}
+
+func TestPRService_Create_HonoursTeamSettings(t *testing.T) {
+	ctx := context.Background()
+
+	team := domain.TeamName("platform")
+	repos := newTestRepos(t, []domain.User{
+		{ID: "u1", Username: "Alice", TeamName: team, IsActive: true},
+		{ID: "u2", Username: "Bob", TeamName: team, IsActive: true},
+		{ID: "u3", Username: "Carol", TeamName: team, IsActive: true},
+		{ID: "u4", Username: "Dave", TeamName: team, IsActive: true},
+	})
+
+	repos.setSettings(t, domain.TeamSettings{
+		TeamName:       team,
+		ReviewersCount: 3,
+		Strategy:       domain.ReviewerStrategyRandom,
+	})
+
+	svc := &PRService{
+		Teams: repos.teams,
+		Users: repos.users,
+		Prs:   repos.prs,
+		Rand:  rand.New(rand.NewSource(1)),
+	}
+
+	pr, err := svc.Create(ctx, "pr-three", "PR three", "u1", nil)
+	if err != nil {
+		t.Fatalf("Create returned error: %v", err)
+	}
+	if len(pr.AssignedReviewers) != 3 {
+		t.Fatalf("expected 3 reviewers, got %d", len(pr.AssignedReviewers))
+	}
+
+	repos.setSettings(t, domain.TeamSettings{
+		TeamName:       team,
+		ReviewersCount: 1,
+		Strategy:       domain.ReviewerStrategyRandom,
+		MinPoolSize:    4,
+	})
+
+	_, err = svc.Create(ctx, "pr-small-pool", "PR small pool", "u1", nil)
+	if err != domain.ErrPoolTooSmall {
+		t.Fatalf("expected ErrPoolTooSmall, got %v", err)
+	}
+}
+
+func TestPRService_FallbackTeams(t *testing.T) {
+	ctx := context.Background()
+
+	team := domain.TeamName("mobile")
+	repos := newTestRepos(t, []domain.User{
+		{ID: "m1", Username: "Alice", TeamName: team, IsActive: true},
+		{ID: "m2", Username: "Bob", TeamName: team, IsActive: false},
+		{ID: "w1", Username: "Carol", TeamName: "web", IsActive: false},
+		{ID: "b1", Username: "Dave", TeamName: "backend", IsActive: true},
+		{ID: "b2", Username: "Eve", TeamName: "backend", IsActive: true},
+	})
+
+	repos.setSettings(t, domain.TeamSettings{
+		TeamName:       team,
+		ReviewersCount: 1,
+		Strategy:       domain.ReviewerStrategyRandom,
+		FallbackTeams:  []domain.TeamName{"web", "backend"},
+	})
+
+	svc := &PRService{
+		Teams: repos.teams,
+		Users: repos.users,
+		Prs:   repos.prs,
+		Rand:  rand.New(rand.NewSource(1)),
+	}
+
+	pr, err := svc.Create(ctx, "pr-fallback", "PR fallback", "m1", nil)
+	if err != nil {
+		t.Fatalf("Create returned error: %v", err)
+	}
+	if len(pr.AssignedReviewers) != 1 {
+		t.Fatalf("expected 1 reviewer, got %d", len(pr.AssignedReviewers))
+	}
+
+	first := pr.AssignedReviewers[0]
+	if pr.FallbackReviewers[first] != "backend" {
+		t.Fatalf("expected reviewer %s to come from fallback team backend, got %v", first, pr.FallbackReviewers)
+	}
+
+	updated, second, err := svc.Reassign(ctx, "pr-fallback", first)
+	if err != nil {
+		t.Fatalf("Reassign returned error: %v", err)
+	}
+	if second == first {
+		t.Fatalf("new reviewer must differ from old")
+	}
+	if _, ok := updated.FallbackReviewers[first]; ok {
+		t.Fatalf("old reviewer must not stay marked as fallback")
+	}
+	if updated.FallbackReviewers[second] != "backend" {
+		t.Fatalf("replacement must stay marked as fallback, got %v", updated.FallbackReviewers)
+	}
+}
+
+func TestPRService_History_RecordsAssignments(t *testing.T) {
+	ctx := context.Background()
+
+	team := domain.TeamName("backend")
+	repos := newTestRepos(t, []domain.User{
+		{ID: "u1", Username: "Alice", TeamName: team, IsActive: true},
+		{ID: "u2", Username: "Bob", TeamName: team, IsActive: true},
+		{ID: "u3", Username: "Carol", TeamName: team, IsActive: true},
+		{ID: "u4", Username: "Dave", TeamName: team, IsActive: true},
+	})
+
+	svc := &PRService{
+		Teams: repos.teams,
+		Users: repos.users,
+		Prs:   repos.prs,
+		Rand:  rand.New(rand.NewSource(1)),
+	}
+
+	pr, err := svc.Create(ctx, "pr-history", "PR history", "u1", nil)
+	if err != nil {
+		t.Fatalf("Create returned error: %v", err)
+	}
+	old := pr.AssignedReviewers[0]
+
+	lead := domain.ContextWithActor(ctx, "lead")
+	if _, _, err := svc.Reassign(lead, "pr-history", old); err != nil {
+		t.Fatalf("Reassign returned error: %v", err)
+	}
+
+	events, err := svc.History(ctx, "pr-history")
+	if err != nil {
+		t.Fatalf("History returned error: %v", err)
+	}
+	if len(events) != 3 {
+		t.Fatalf("expected 3 events, got %d", len(events))
+	}
+	for _, e := range events[:2] {
+		if e.Type != domain.AssignmentEventAssigned || e.Reason != domain.AssignmentReasonCreate || e.Actor != "u1" {
+			t.Fatalf("unexpected create event: %+v", e)
+		}
+	}
+
+	replaced := events[2]
+	if replaced.Type != domain.AssignmentEventReplaced || replaced.UserID != old {
+		t.Fatalf("unexpected replace event: %+v", replaced)
+	}
+	if replaced.Reason != domain.AssignmentReasonManualReassign || replaced.Actor != "lead" {
+		t.Fatalf("unexpected replace reason or actor: %+v", replaced)
+	}
+
+	if _, err := svc.History(ctx, "unknown"); err != domain.ErrNotFound {
+		t.Fatalf("expected ErrNotFound, got %v", err)
+	}
+}
+
+// staleReadRepo hides one reviewer from GetForUpdate, as if it was assigned
+// after the PR was read.
+type staleReadRepo struct {
+	*memory.PullRequestRepo
+	hidden domain.UserID
+}
+
+func (r staleReadRepo) GetForUpdate(ctx context.Context, id domain.PullRequestID) (domain.PullRequest, error) {
+	pr, err := r.PullRequestRepo.GetForUpdate(ctx, id)
+	pr.AssignedReviewers = slices.DeleteFunc(pr.AssignedReviewers, func(u domain.UserID) bool { return u == r.hidden })
+	return pr, err
+}
+
+func TestPRService_Reassign_RecordsUnassignWhenSuccessorIsAssigned(t *testing.T) {
+	ctx := context.Background()
+
+	team := domain.TeamName("backend")
+	repos := newTestRepos(t, []domain.User{
+		{ID: "u1", Username: "Alice", TeamName: team, IsActive: true},
+		{ID: "u2", Username: "Bob", TeamName: team, IsActive: true},
+		{ID: "u3", Username: "Carol", TeamName: team, IsActive: true},
+	})
+	repos.addPR(t, domain.PullRequest{
+		ID:                "pr-1",
+		Name:              "Add search",
+		AuthorID:          "u1",
+		Status:            domain.PRStatusOpen,
+		AssignedReviewers: []domain.UserID{"u2", "u3"},
+	})
+	repos.setSettings(t, domain.TeamSettings{
+		TeamName:        team,
+		ReviewersCount:  2,
+		Strategy:        domain.ReviewerStrategyRandom,
+		SlackWebhookURL: "https://hooks.slack.test/backend",
+	})
+
+	outbox := &fakeOutbox{}
+	notifier := &recordingNotifier{}
+	metrics := &fakeMetrics{}
+	svc := &PRService{
+		Teams:    repos.teams,
+		Users:    repos.users,
+		Prs:      staleReadRepo{PullRequestRepo: repos.prs, hidden: "u3"},
+		Outbox:   outbox,
+		Rand:     rand.New(rand.NewSource(1)),
+		Notifier: notifier,
+		Metrics:  metrics,
+	}
+
+	pr, newReviewer, err := svc.Reassign(ctx, "pr-1", "u2")
+	if err != nil {
+		t.Fatalf("Reassign returned error: %v", err)
+	}
+	if newReviewer != "" {
+		t.Fatalf("expected no successor, got %s", newReviewer)
+	}
+	if len(pr.AssignedReviewers) != 1 || pr.AssignedReviewers[0] != "u3" {
+		t.Fatalf("expected only u3 to stay assigned, got %v", pr.AssignedReviewers)
+	}
+	if len(notifier.notices) != 0 || len(outbox.events) != 0 || len(metrics.replaced) != 0 {
+		t.Fatalf("expected no notice, outbox event or replacement, got %+v, %+v, %v",
+			notifier.notices, outbox.events, metrics.replaced)
+	}
+
+	events := repos.events(t, "pr-1")
+	if len(events) != 1 {
+		t.Fatalf("expected 1 event, got %+v", events)
+	}
+	if e := events[0]; e.Type != domain.AssignmentEventUnassigned || e.UserID != "u2" || e.ReplacedBy != "" {
+		t.Fatalf("expected u2 to be recorded as unassigned, got %+v", e)
+	}
+}
+
+// countingUnitOfWork counts the units of work run through it.
+type countingUnitOfWork struct {
+	domain.UnitOfWork
+	calls int
+}
+
+func (u *countingUnitOfWork) Do(ctx context.Context, fn func(ctx context.Context, repos domain.Repositories) error) error {
+	u.calls++
+	return u.UnitOfWork.Do(ctx, fn)
+}
+
+func TestPRService_UsesUnitOfWork(t *testing.T) {
+	ctx := context.Background()
+
+	team := domain.TeamName("backend")
+	repos := newTestRepos(t, []domain.User{
+		{ID: "u1", Username: "Alice", TeamName: team, IsActive: true},
+		{ID: "u2", Username: "Bob", TeamName: team, IsActive: true},
+		{ID: "u3", Username: "Carol", TeamName: team, IsActive: true},
+	})
+
+	// The service's own repositories are empty: everything must go through
+	// the unit of work.
+	outside := newTestRepos(t, nil)
+	uow := &countingUnitOfWork{UnitOfWork: memory.NewUnitOfWork(repos.store)}
+
+	svc := &PRService{
+		Teams: outside.teams,
+		Users: outside.users,
+		Prs:   outside.prs,
+		Tx:    uow,
+		Rand:  rand.New(rand.NewSource(1)),
+	}
+
+	pr, err := svc.Create(ctx, "pr-tx", "PR tx", "u1", nil)
+	if err != nil {
+		t.Fatalf("Create returned error: %v", err)
+	}
+	if ok, _ := repos.prs.Exists(ctx, pr.ID); !ok {
+		t.Fatalf("PR must be stored through the unit of work repositories")
+	}
+
+	if _, _, err := svc.Reassign(ctx, pr.ID, pr.AssignedReviewers[0]); err != domain.ErrNoCandidate {
+		t.Fatalf("expected ErrNoCandidate, got %v", err)
+	}
+
+	if _, err := svc.Create(ctx, "pr-tx", "PR tx", "u1", nil); err != domain.ErrPullRequestExists {
+		t.Fatalf("expected ErrPullRequestExists, got %v", err)
+	}
+
+	if uow.calls != 3 {
+		t.Fatalf("expected 3 units of work, got %d", uow.calls)
+	}
+}
+
+type recordingNotifier struct {
+	notices []domain.ReviewerNotice
+}
+
+func (n *recordingNotifier) NotifyReviewer(ctx context.Context, notice domain.ReviewerNotice) {
+	n.notices = append(n.notices, notice)
+}
+
+func TestPRService_NotifiesAssignedReviewers(t *testing.T) {
+	ctx := context.Background()
+
+	team := domain.TeamName("backend")
+	repos := newTestRepos(t, []domain.User{
+		{ID: "u1", Username: "Alice", TeamName: team, IsActive: true},
+		{ID: "u2", Username: "Bob", TeamName: team, IsActive: true, SlackHandle: "UBOB"},
+		{ID: "u3", Username: "Carol", TeamName: team, IsActive: true, SlackHandle: "UCAROL"},
+	})
+
+	repos.setSettings(t, domain.TeamSettings{
+		TeamName:        team,
+		ReviewersCount:  1,
+		Strategy:        domain.ReviewerStrategyRandom,
+		SlackWebhookURL: "https://hooks.slack.test/backend",
+	})
+
+	notifier := &recordingNotifier{}
+	svc := &PRService{
+		Teams:    repos.teams,
+		Users:    repos.users,
+		Prs:      repos.prs,
+		Rand:     rand.New(rand.NewSource(1)),
+		Notifier: notifier,
+	}
+
+	pr, err := svc.Create(ctx, "pr-1", "Add search", "u1", nil)
+	if err != nil {
+		t.Fatalf("Create returned error: %v", err)
+	}
+	if len(notifier.notices) != 1 {
+		t.Fatalf("expected 1 notice after create, got %d", len(notifier.notices))
+	}
+	first := notifier.notices[0]
+	if first.WebhookURL != "https://hooks.slack.test/backend" || first.Reviewer.ID != pr.AssignedReviewers[0] {
+		t.Fatalf("unexpected create notice: %+v", first)
+	}
+	if first.Reviewer.SlackHandle == "" {
+		t.Fatalf("expected reviewer profile with slack handle, got %+v", first.Reviewer)
+	}
+
+	_, newReviewer, err := svc.Reassign(ctx, "pr-1", first.Reviewer.ID)
+	if err != nil {
+		t.Fatalf("Reassign returned error: %v", err)
+	}
+	if len(notifier.notices) != 2 {
+		t.Fatalf("expected 2 notices after reassign, got %d", len(notifier.notices))
+	}
+	second := notifier.notices[1]
+	if second.Reviewer.ID != newReviewer || second.Replaced != first.Reviewer.ID {
+		t.Fatalf("unexpected reassign notice: %+v", second)
+	}
+}
+
+func TestPRService_NotifiesBulkHandovers(t *testing.T) {
+	ctx := context.Background()
+
+	team := domain.TeamName("backend")
+	repos := newTestRepos(t, []domain.User{
+		{ID: "u1", Username: "Alice", TeamName: team, IsActive: true},
+		{ID: "u2", Username: "Bob", TeamName: team, IsActive: true},
+		{ID: "u3", Username: "Carol", TeamName: team, IsActive: true},
+	})
+	repos.setSettings(t, domain.TeamSettings{
+		TeamName:        team,
+		ReviewersCount:  1,
+		Strategy:        domain.ReviewerStrategyRandom,
+		SlackWebhookURL: "https://hooks.slack.test/backend",
+	})
+	repos.addPR(t, domain.PullRequest{
+		ID:                "pr-1",
+		Name:              "Add search",
+		AuthorID:          "u1",
+		Status:            domain.PRStatusOpen,
+		AssignedReviewers: []domain.UserID{"u2"},
+	})
+
+	notifier := &recordingNotifier{}
+	svc := &PRService{
+		Teams:    repos.teams,
+		Users:    repos.users,
+		Prs:      repos.prs,
+		Rand:     rand.New(rand.NewSource(1)),
+		Notifier: notifier,
+	}
+
+	if err := svc.BulkDeactivateAndReassign(domain.ContextWithActor(ctx, "lead"), []domain.UserID{"u2"}); err != nil {
+		t.Fatalf("BulkDeactivateAndReassign returned error: %v", err)
+	}
+	if changes, _ := repos.users.ListStatusChanges(ctx, "u2"); len(changes) != 1 || changes[0].Actor != "lead" {
+		t.Fatalf("expected the deactivation to be stored with its actor, got %+v", changes)
+	}
+	if len(notifier.notices) != 1 {
+		t.Fatalf("expected 1 notice, got %+v", notifier.notices)
+	}
+	n := notifier.notices[0]
+	if n.Reviewer.ID != "u3" || n.Replaced != "u2" || n.PullRequestID != "pr-1" ||
+		n.Reason != domain.AssignmentReasonBulkDeactivation {
+		t.Fatalf("unexpected notice: %+v", n)
+	}
+}
+
+func TestPRService_SkipsUnavailableReviewers(t *testing.T) {
+	ctx := context.Background()
+
+	team := domain.TeamName("backend")
+	repos := newTestRepos(t, []domain.User{
+		{ID: "u1", Username: "Alice", TeamName: team, IsActive: true},
+		{ID: "u2", Username: "Bob", TeamName: team, IsActive: true},
+		{ID: "u3", Username: "Carol", TeamName: team, IsActive: true},
+	})
+
+	now := time.Now()
+	for _, p := range []domain.Unavailability{
+		{UserID: "u2", StartsAt: now.Add(-time.Hour), EndsAt: now.Add(time.Hour)},
+		{UserID: "u3", StartsAt: now.Add(-48 * time.Hour), EndsAt: now.Add(-24 * time.Hour)},
+	} {
+		if _, err := repos.users.AddUnavailability(ctx, p); err != nil {
+			t.Fatalf("AddUnavailability returned error: %v", err)
+		}
+	}
+
+	svc := &PRService{
+		Teams: repos.teams,
+		Users: repos.users,
+		Prs:   repos.prs,
+		Rand:  rand.New(rand.NewSource(1)),
+	}
+
+	pr, err := svc.Create(ctx, "pr-1", "Add search", "u1", nil)
+	if err != nil {
+		t.Fatalf("Create returned error: %v", err)
+	}
+	if len(pr.AssignedReviewers) != 1 || pr.AssignedReviewers[0] != "u3" {
+		t.Fatalf("expected only u3 to be assigned, got %v", pr.AssignedReviewers)
+	}
+}
+
+func TestPRService_ReassignUnavailable(t *testing.T) {
+	ctx := context.Background()
+
+	team := domain.TeamName("backend")
+	repos := newTestRepos(t, []domain.User{
+		{ID: "u1", Username: "Alice", TeamName: team, IsActive: true},
+		{ID: "u2", Username: "Bob", TeamName: team, IsActive: true},
+		{ID: "u3", Username: "Carol", TeamName: team, IsActive: true},
+	})
+
+	repos.addPR(t, domain.PullRequest{
+		ID:                "pr-1",
+		Name:              "Add search",
+		AuthorID:          "u1",
+		Status:            domain.PRStatusOpen,
+		AssignedReviewers: []domain.UserID{"u2"},
+	})
+
+	now := time.Now()
+	period, err := repos.users.AddUnavailability(ctx, domain.Unavailability{
+		UserID:              "u2",
+		StartsAt:            now.Add(-time.Minute),
+		EndsAt:              now.Add(time.Hour),
+		ReassignOpenReviews: true,
+	})
+	if err != nil {
+		t.Fatalf("AddUnavailability returned error: %v", err)
+	}
+
+	svc := &PRService{
+		Teams: repos.teams,
+		Users: repos.users,
+		Prs:   repos.prs,
+		Rand:  rand.New(rand.NewSource(1)),
+	}
+
+	if err := svc.ReassignUnavailable(ctx); err != nil {
+		t.Fatalf("ReassignUnavailable returned error: %v", err)
+	}
+
+	got := repos.getPR(t, "pr-1").AssignedReviewers
+	if len(got) != 1 || got[0] != "u3" {
+		t.Fatalf("expected u2 to be replaced by u3, got %v", got)
+	}
+	if p, err := repos.users.GetUnavailability(ctx, period.ID); err != nil || p.ReassignedAt == nil {
+		t.Fatalf("expected unavailability to be marked as reassigned, got %+v, %v", p, err)
+	}
+	if events := repos.events(t, "pr-1"); len(events) != 1 || events[0].Reason != domain.AssignmentReasonUnavailable {
+		t.Fatalf("expected one unavailable replacement event, got %+v", events)
+	}
+}
+
+// failingMarkRepo fails to mark one unavailability period as reassigned.
+type failingMarkRepo struct {
+	*memory.UserRepo
+	failID int64
+}
+
+func (r failingMarkRepo) MarkReassigned(ctx context.Context, id int64, at time.Time) error {
+	if id == r.failID {
+		return errors.New("connection reset by peer")
+	}
+	return r.UserRepo.MarkReassigned(ctx, id, at)
+}
+
+func TestPRService_ReassignUnavailable_ContinuesPastFailures(t *testing.T) {
+	ctx := context.Background()
+
+	team := domain.TeamName("backend")
+	repos := newTestRepos(t, []domain.User{
+		{ID: "u1", Username: "Alice", TeamName: team, IsActive: true},
+		{ID: "u2", Username: "Bob", TeamName: team, IsActive: true},
+		{ID: "u3", Username: "Carol", TeamName: team, IsActive: true},
+	})
+
+	now := time.Now()
+	var periods []domain.Unavailability
+	for _, id := range []domain.UserID{"u2", "u3"} {
+		p, err := repos.users.AddUnavailability(ctx, domain.Unavailability{
+			UserID:              id,
+			StartsAt:            now.Add(-time.Minute),
+			EndsAt:              now.Add(time.Hour),
+			ReassignOpenReviews: true,
+		})
+		if err != nil {
+			t.Fatalf("AddUnavailability returned error: %v", err)
+		}
+		periods = append(periods, p)
+	}
+
+	svc := &PRService{
+		Teams: repos.teams,
+		Users: failingMarkRepo{UserRepo: repos.users, failID: periods[0].ID},
+		Prs:   repos.prs,
+		Rand:  rand.New(rand.NewSource(1)),
+	}
+
+	err := svc.ReassignUnavailable(ctx)
+	if err == nil || !strings.Contains(err.Error(), "connection reset by peer") {
+		t.Fatalf("expected the failed period to be reported, got %v", err)
+	}
+
+	first, _ := repos.users.GetUnavailability(ctx, periods[0].ID)
+	second, _ := repos.users.GetUnavailability(ctx, periods[1].ID)
+	if first.ReassignedAt != nil || second.ReassignedAt == nil {
+		t.Fatalf("expected only the second period marked, got %+v and %+v", first, second)
+	}
+}
+
+func TestPRService_RespectsReviewerCapacity(t *testing.T) {
+	ctx := context.Background()
+
+	team := domain.TeamName("backend")
+	repos := newTestRepos(t, []domain.User{
+		{ID: "u1", Username: "Alice", TeamName: team, IsActive: true},
+		{ID: "u2", Username: "Bob", TeamName: team, IsActive: true, MaxOpenReviews: 1},
+		{ID: "u3", Username: "Carol", TeamName: team, IsActive: true, MaxOpenReviews: 2},
+	})
+
+	repos.addPR(t, domain.PullRequest{
+		ID:                "pr-old",
+		AuthorID:          "u1",
+		Status:            domain.PRStatusOpen,
+		AssignedReviewers: []domain.UserID{"u2"},
+	})
+
+	svc := &PRService{
+		Teams: repos.teams,
+		Users: repos.users,
+		Prs:   repos.prs,
+		Rand:  rand.New(rand.NewSource(1)),
+	}
+
+	pr, err := svc.Create(ctx, "pr-1", "First", "u1", nil)
+	if err != nil {
+		t.Fatalf("Create returned error: %v", err)
+	}
+	if len(pr.AssignedReviewers) != 1 || pr.AssignedReviewers[0] != "u3" {
+		t.Fatalf("expected only u3 below capacity, got %v", pr.AssignedReviewers)
+	}
+
+	if _, err := svc.Create(ctx, "pr-2", "Second", "u1", nil); err != nil {
+		t.Fatalf("Create returned error: %v", err)
+	}
+
+	_, err = svc.Create(ctx, "pr-3", "Third", "u1", nil)
+	if err != domain.ErrAllReviewersAtCapacity {
+		t.Fatalf("expected ErrAllReviewersAtCapacity, got %v", err)
+	}
+
+	_, _, err = svc.Reassign(ctx, "pr-1", "u3")
+	if err != domain.ErrAllReviewersAtCapacity {
+		t.Fatalf("expected ErrAllReviewersAtCapacity on reassign, got %v", err)
+	}
+}
+
+func TestPRService_Create_PrefersCodeowners(t *testing.T) {
+	ctx := context.Background()
+
+	team := domain.TeamName("backend")
+	repos := newTestRepos(t, []domain.User{
+		{ID: "u1", Username: "Alice", TeamName: team, IsActive: true},
+		{ID: "u2", Username: "Bob", TeamName: team, IsActive: true},
+		{ID: "u3", Username: "Carol", TeamName: team, IsActive: true},
+		{ID: "u4", Username: "Dave", TeamName: team, IsActive: true},
+	})
+
+	if err := repos.teams.PutCodeowners(ctx, domain.Codeowners{
+		TeamName: team,
+		Content:  "*.go @u3\n/internal/billing/ @dave\n",
+	}); err != nil {
+		t.Fatalf("PutCodeowners returned error: %v", err)
+	}
+
+	svc := &PRService{
+		Teams: repos.teams,
+		Users: repos.users,
+		Prs:   repos.prs,
+		Rand:  rand.New(rand.NewSource(1)),
+	}
+
+	pr, err := svc.Create(ctx, "pr-1", "Billing fix", "u1", []string{"internal/billing/invoice.go"})
+	if err != nil {
+		t.Fatalf("Create returned error: %v", err)
+	}
+	if len(pr.AssignedReviewers) != 2 || pr.AssignedReviewers[0] != "u4" {
+		t.Fatalf("expected owner u4 first, got %v", pr.AssignedReviewers)
+	}
+	if pr.MatchedRules["u4"] != "/internal/billing/" {
+		t.Fatalf("expected u4 matched by /internal/billing/, got %v", pr.MatchedRules)
+	}
+	if _, ok := pr.MatchedRules[pr.AssignedReviewers[1]]; ok {
+		t.Fatalf("expected second slot filled by strategy, got %v", pr.MatchedRules)
+	}
+
+	pr, err = svc.Create(ctx, "pr-2", "Tooling", "u1", []string{"cmd/main.go", "internal/billing/plan.go"})
+	if err != nil {
+		t.Fatalf("Create returned error: %v", err)
+	}
+	if len(pr.MatchedRules) != 2 || pr.MatchedRules["u3"] != "*.go" || pr.MatchedRules["u4"] != "/internal/billing/" {
+		t.Fatalf("expected both owners matched, got %v", pr.MatchedRules)
+	}
+}
+
+func TestPRService_MergeRequiresApprovals(t *testing.T) {
+	ctx := context.Background()
+
+	team := domain.TeamName("backend")
+	repos := newTestRepos(t, []domain.User{
+		{ID: "u1", Username: "Alice", TeamName: team, IsActive: true},
+		{ID: "u2", Username: "Bob", TeamName: team, IsActive: true},
+		{ID: "u3", Username: "Carol", TeamName: team, IsActive: true},
+	})
+
+	repos.setSettings(t, domain.TeamSettings{
+		TeamName:          team,
+		ReviewersCount:    2,
+		Strategy:          domain.ReviewerStrategyRandom,
+		RequiredApprovals: 2,
+	})
+
+	svc := &PRService{
+		Teams: repos.teams,
+		Users: repos.users,
+		Prs:   repos.prs,
+		Rand:  rand.New(rand.NewSource(1)),
+	}
+
+	pr, err := svc.Create(ctx, "pr-1", "Add search", "u1", nil)
+	if err != nil {
+		t.Fatalf("Create returned error: %v", err)
+	}
+
+	if _, err := svc.Review(ctx, "pr-1", "u1", domain.ReviewStateApproved); err != domain.ErrNotAssigned {
+		t.Fatalf("expected ErrNotAssigned for the author, got %v", err)
+	}
+	if _, err := svc.Review(ctx, "pr-1", pr.AssignedReviewers[0], domain.ReviewStatePending); err != domain.ErrInvalidReview {
+		t.Fatalf("expected ErrInvalidReview for PENDING, got %v", err)
+	}
+
+	if _, err := svc.Review(ctx, "pr-1", pr.AssignedReviewers[0], domain.ReviewStateApproved); err != nil {
+		t.Fatalf("Review returned error: %v", err)
+	}
+	reviewed, err := svc.Review(ctx, "pr-1", pr.AssignedReviewers[1], domain.ReviewStateChangesRequested)
+	if err != nil {
+		t.Fatalf("Review returned error: %v", err)
+	}
+	if reviewed.Approvals() != 1 {
+		t.Fatalf("expected 1 approval, got %d", reviewed.Approvals())
+	}
+
+	if _, err := svc.Merge(ctx, "pr-1", false); err != domain.ErrNotEnoughApprovals {
+		t.Fatalf("expected ErrNotEnoughApprovals, got %v", err)
+	}
+
+	if _, err := svc.Review(ctx, "pr-1", pr.AssignedReviewers[1], domain.ReviewStateApproved); err != nil {
+		t.Fatalf("Review returned error: %v", err)
+	}
+	merged, err := svc.Merge(ctx, "pr-1", false)
+	if err != nil {
+		t.Fatalf("Merge returned error: %v", err)
+	}
+	if merged.Status != domain.PRStatusMerged {
+		t.Fatalf("expected MERGED, got %s", merged.Status)
+	}
+
+	if _, err := svc.Create(ctx, "pr-2", "Hotfix", "u1", nil); err != nil {
+		t.Fatalf("Create returned error: %v", err)
+	}
+	if _, err := svc.Merge(ctx, "pr-2", true); err != nil {
+		t.Fatalf("expected override to bypass the gate, got %v", err)
+	}
+}
+
+func TestPRService_Lifecycle(t *testing.T) {
+	ctx := context.Background()
+
+	team := domain.TeamName("backend")
+	repos := newTestRepos(t, []domain.User{
+		{ID: "u1", Username: "Alice", TeamName: team, IsActive: true},
+		{ID: "u2", Username: "Bob", TeamName: team, IsActive: true},
+		{ID: "u3", Username: "Carol", TeamName: team, IsActive: true},
+	})
+
+	svc := &PRService{
+		Teams: repos.teams,
+		Users: repos.users,
+		Prs:   repos.prs,
+		Rand:  rand.New(rand.NewSource(1)),
+	}
+
+	draft, err := svc.CreateDraft(ctx, "pr-1", "WIP", "u1")
+	if err != nil {
+		t.Fatalf("CreateDraft returned error: %v", err)
+	}
+	if draft.Status != domain.PRStatusDraft || len(draft.AssignedReviewers) != 0 {
+		t.Fatalf("expected reviewerless DRAFT, got %s with %v", draft.Status, draft.AssignedReviewers)
+	}
+
+	if _, err := svc.Merge(ctx, "pr-1", true); err != domain.ErrInvalidTransition {
+		t.Fatalf("expected ErrInvalidTransition merging a draft, got %v", err)
+	}
+
+	closed, err := svc.Close(ctx, "pr-1")
+	if err != nil {
+		t.Fatalf("Close returned error: %v", err)
+	}
+	if closed.Status != domain.PRStatusClosed || closed.ClosedAt == nil {
+		t.Fatalf("expected CLOSED with closed_at, got %s", closed.Status)
+	}
+
+	if _, err := svc.Ready(ctx, "pr-1", nil); err != domain.ErrInvalidTransition {
+		t.Fatalf("expected ErrInvalidTransition readying a closed PR, got %v", err)
+	}
+
+	reopened, err := svc.Reopen(ctx, "pr-1")
+	if err != nil {
+		t.Fatalf("Reopen returned error: %v", err)
+	}
+	if reopened.Status != domain.PRStatusDraft || reopened.ClosedAt != nil {
+		t.Fatalf("expected closed draft to reopen as DRAFT, got %s", reopened.Status)
+	}
+
+	ready, err := svc.Ready(ctx, "pr-1", nil)
+	if err != nil {
+		t.Fatalf("Ready returned error: %v", err)
+	}
+	if ready.Status != domain.PRStatusOpen || len(ready.AssignedReviewers) != 2 {
+		t.Fatalf("expected OPEN with 2 reviewers, got %s with %v", ready.Status, ready.AssignedReviewers)
+	}
+	if events := repos.events(t, "pr-1"); len(events) != 2 || events[0].Reason != domain.AssignmentReasonReadyForReview {
+		t.Fatalf("expected ready_for_review assignment events, got %+v", events)
+	}
+
+	if _, err := svc.Close(ctx, "pr-1"); err != nil {
+		t.Fatalf("Close returned error: %v", err)
+	}
+	if _, _, err := svc.Reassign(ctx, "pr-1", ready.AssignedReviewers[0]); err != domain.ErrPullRequestClosed {
+		t.Fatalf("expected ErrPullRequestClosed on reassign, got %v", err)
+	}
+
+	reopened, err = svc.Reopen(ctx, "pr-1")
+	if err != nil {
+		t.Fatalf("Reopen returned error: %v", err)
+	}
+	if reopened.Status != domain.PRStatusOpen || len(reopened.AssignedReviewers) != 2 {
+		t.Fatalf("expected OPEN with reviewers kept, got %s with %v", reopened.Status, reopened.AssignedReviewers)
+	}
+
+	if _, err := svc.Merge(ctx, "pr-1", false); err != nil {
+		t.Fatalf("Merge returned error: %v", err)
+	}
+	if _, err := svc.Close(ctx, "pr-1"); err != domain.ErrInvalidTransition {
+		t.Fatalf("expected ErrInvalidTransition closing a merged PR, got %v", err)
+	}
+	if _, err := svc.Reopen(ctx, "pr-1"); err != domain.ErrInvalidTransition {
+		t.Fatalf("expected ErrInvalidTransition reopening a merged PR, got %v", err)
+	}
+}
+
+func TestPRService_ReopenRestoresOpenWithoutReviewers(t *testing.T) {
+	ctx := context.Background()
+
+	repos := newTestRepos(t, []domain.User{
+		{ID: "u1", Username: "Alice", TeamName: "solo", IsActive: true},
+	})
+
+	svc := &PRService{
+		Teams: repos.teams,
+		Users: repos.users,
+		Prs:   repos.prs,
+		Rand:  rand.New(rand.NewSource(1)),
+	}
+
+	created, err := svc.Create(ctx, "pr-1", "Search", "u1", nil)
+	if err != nil {
+		t.Fatalf("Create returned error: %v", err)
+	}
+	if created.Status != domain.PRStatusOpen || len(created.AssignedReviewers) != 0 {
+		t.Fatalf("expected reviewerless OPEN, got %s with %v", created.Status, created.AssignedReviewers)
+	}
+
+	if _, err := svc.Close(ctx, "pr-1"); err != nil {
+		t.Fatalf("Close returned error: %v", err)
+	}
+	reopened, err := svc.Reopen(ctx, "pr-1")
+	if err != nil {
+		t.Fatalf("Reopen returned error: %v", err)
+	}
+	if reopened.Status != domain.PRStatusOpen || reopened.ClosedFrom != "" {
+		t.Fatalf("expected PR closed while OPEN to reopen as OPEN, got %+v", reopened)
+	}
+	if pr := repos.getPR(t, "pr-1"); pr.Status != domain.PRStatusOpen {
+		t.Fatalf("expected stored status OPEN, got %s", pr.Status)
+	}
+}
+
+type fakeMetrics struct {
+	replaced     []domain.AssignmentReason
+	noCandidate  []domain.AssignmentReason
+	understaffed []domain.TeamName
+}
+
+func (m *fakeMetrics) ReviewerReplaced(reason domain.AssignmentReason) {
+	m.replaced = append(m.replaced, reason)
+}
+
+func (m *fakeMetrics) NoCandidate(reason domain.AssignmentReason) {
+	m.noCandidate = append(m.noCandidate, reason)
+}
+
+func (m *fakeMetrics) Understaffed(team domain.TeamName) {
+	m.understaffed = append(m.understaffed, team)
+}
+
+func TestPRService_RecordsMetrics(t *testing.T) {
+	ctx := context.Background()
+
+	team := domain.TeamName("backend")
+	repos := newTestRepos(t, []domain.User{
+		{ID: "u1", Username: "Alice", TeamName: team, IsActive: true},
+		{ID: "u2", Username: "Bob", TeamName: team, IsActive: true},
+	})
+
+	metrics := &fakeMetrics{}
+	svc := &PRService{
+		Teams:   repos.teams,
+		Users:   repos.users,
+		Prs:     repos.prs,
+		Rand:    rand.New(rand.NewSource(1)),
+		Metrics: metrics,
+	}
+
+	if _, err := svc.Create(ctx, "pr-1", "Search", "u1", nil); err != nil {
+		t.Fatalf("Create returned error: %v", err)
+	}
+	if len(metrics.understaffed) != 1 || metrics.understaffed[0] != team {
+		t.Fatalf("expected one understaffed PR for %s, got %v", team, metrics.understaffed)
+	}
+
+	if _, _, err := svc.Reassign(ctx, "pr-1", "u2"); err != domain.ErrNoCandidate {
+		t.Fatalf("expected ErrNoCandidate, got %v", err)
+	}
+	if len(metrics.noCandidate) != 1 || metrics.noCandidate[0] != domain.AssignmentReasonManualReassign {
+		t.Fatalf("unexpected no-candidate counts: %v", metrics.noCandidate)
+	}
+
+	repos.addUsers(t, []domain.User{{ID: "u3", Username: "Carol", TeamName: team, IsActive: true}})
+	if _, _, err := svc.Reassign(ctx, "pr-1", "u2"); err != nil {
+		t.Fatalf("Reassign returned error: %v", err)
+	}
+	if err := svc.BulkDeactivateAndReassign(ctx, []domain.UserID{"u3"}); err != nil {
+		t.Fatalf("BulkDeactivateAndReassign returned error: %v", err)
+	}
+
+	// u2 is free again and takes over from u3.
+	if len(metrics.replaced) != 2 ||
+		metrics.replaced[0] != domain.AssignmentReasonManualReassign ||
+		metrics.replaced[1] != domain.AssignmentReasonBulkDeactivation {
+		t.Fatalf("unexpected replacements: %v", metrics.replaced)
+	}
+	if len(metrics.noCandidate) != 1 {
+		t.Fatalf("unexpected no-candidate counts: %v", metrics.noCandidate)
+	}
+}
+
+func TestPRService_CountsStuckReassignments(t *testing.T) {
+	ctx := context.Background()
+
+	team := domain.TeamName("backend")
+	repos := newTestRepos(t, []domain.User{
+		{ID: "u1", Username: "Alice", TeamName: team, IsActive: true},
+		{ID: "u2", Username: "Bob", TeamName: team, IsActive: true},
+		{ID: "u3", Username: "Carol", TeamName: team, IsActive: true, MaxOpenReviews: 1},
+	})
+	repos.addPR(t, domain.PullRequest{
+		ID:                "pr-1",
+		AuthorID:          "u1",
+		Status:            domain.PRStatusOpen,
+		AssignedReviewers: []domain.UserID{"u2"},
+	})
+	repos.addPR(t, domain.PullRequest{
+		ID:                "pr-2",
+		AuthorID:          "u1",
+		Status:            domain.PRStatusOpen,
+		AssignedReviewers: []domain.UserID{"u3"},
+	})
+
+	metrics := &fakeMetrics{}
+	svc := &PRService{
+		Teams:   repos.teams,
+		Users:   repos.users,
+		Prs:     repos.prs,
+		Rand:    rand.New(rand.NewSource(1)),
+		Metrics: metrics,
+	}
+
+	// u3, the only candidate, is at capacity.
+	if _, _, err := svc.Reassign(ctx, "pr-1", "u2"); err != domain.ErrAllReviewersAtCapacity {
+		t.Fatalf("expected ErrAllReviewersAtCapacity, got %v", err)
+	}
+
+	repos.setSettings(t, domain.TeamSettings{
+		TeamName:       team,
+		ReviewersCount: 1,
+		Strategy:       domain.ReviewerStrategyRandom,
+		MinPoolSize:    2,
+	})
+	if err := svc.BulkDeactivateAndReassign(ctx, []domain.UserID{"u2"}); err != nil {
+		t.Fatalf("BulkDeactivateAndReassign returned error: %v", err)
+	}
+
+	want := []domain.AssignmentReason{domain.AssignmentReasonManualReassign, domain.AssignmentReasonBulkDeactivation}
+	if !slices.Equal(metrics.noCandidate, want) || len(metrics.replaced) != 0 {
+		t.Fatalf("expected stuck reassignments %v, got %v and replacements %v", want, metrics.noCandidate, metrics.replaced)
+	}
+}
@@ -0,0 +1,76 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"math/rand"
+	"sort"
+
+	"pr-reviewer-service/internal/domain"
+)
+
+type ReviewerSelector interface {
+	Select(ctx context.Context, candidates []domain.User, limit int) ([]domain.UserID, error)
+}
+
+type RandomSelector struct {
+	Rand *rand.Rand
+}
+
+func (s RandomSelector) Select(ctx context.Context, candidates []domain.User, limit int) ([]domain.UserID, error) {
+	return pickRandomReviewers(candidates, limit, s.Rand), nil
+}
+
+// LeastLoadedSelector prefers candidates with the fewest reviews on OPEN PRs,
+// ties are broken randomly.
+type LeastLoadedSelector struct {
+	Prs  domain.PullRequestRepository
+	Rand *rand.Rand
+}
+
+func (s LeastLoadedSelector) Select(ctx context.Context, candidates []domain.User, limit int) ([]domain.UserID, error) {
+	if len(candidates) == 0 || limit <= 0 {
+		return nil, nil
+	}
+
+	load, err := s.Prs.OpenAssignmentsByUser(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	shuffled := make([]domain.User, len(candidates))
+	for i, idx := range s.Rand.Perm(len(candidates)) {
+		shuffled[i] = candidates[idx]
+	}
+
+	sort.SliceStable(shuffled, func(i, j int) bool {
+		return load[shuffled[i].ID] < load[shuffled[j].ID]
+	})
+
+	n := limit
+	if len(shuffled) < limit {
+		n = len(shuffled)
+	}
+
+	res := make([]domain.UserID, 0, n)
+	for i := 0; i < n; i++ {
+		res = append(res, shuffled[i].ID)
+	}
+
+	return res, nil
+}
+
+func NewReviewerSelector(
+	strategy domain.ReviewerStrategy,
+	prs domain.PullRequestRepository,
+	rnd *rand.Rand,
+) (ReviewerSelector, error) {
+	switch strategy {
+	case "", domain.ReviewerStrategyRandom:
+		return RandomSelector{Rand: rnd}, nil
+	case domain.ReviewerStrategyLeastLoaded:
+		return LeastLoadedSelector{Prs: prs, Rand: rnd}, nil
+	default:
+		return nil, fmt.Errorf("unknown reviewer strategy %q", strategy)
+	}
+}
@@ -0,0 +1,212 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"time"
+
+	"pr-reviewer-service/internal/domain"
+	"pr-reviewer-service/internal/snapshot"
+)
+
+type SnapshotService struct {
+	Teams domain.TeamRepository
+	Users domain.UserRepository
+	Prs   domain.PullRequestRepository
+	// Tx makes Restore all-or-nothing. Without it the repositories above are
+	// used directly.
+	Tx domain.UnitOfWork
+}
+
+func NewSnapshotService(teams domain.TeamRepository, users domain.UserRepository, prs domain.PullRequestRepository) *SnapshotService {
+	return &SnapshotService{
+		Teams: teams,
+		Users: users,
+		Prs:   prs,
+	}
+}
+
+// RestoreReport summarises a restore. When Conflicts is non-empty nothing
+// was written.
+type RestoreReport struct {
+	TeamsCreated int                 `json:"teams_created"`
+	TeamsUpdated int                 `json:"teams_updated"`
+	Users        int                 `json:"users"`
+	PullRequests int                 `json:"pull_requests"`
+	Conflicts    []snapshot.Conflict `json:"conflicts,omitempty"`
+}
+
+func (s *SnapshotService) withinTx(ctx context.Context, fn func(ctx context.Context, repos domain.Repositories) error) error {
+	if s.Tx == nil {
+		return fn(ctx, domain.Repositories{Teams: s.Teams, Users: s.Users, Prs: s.Prs})
+	}
+	return s.Tx.Do(ctx, fn)
+}
+
+func (s *SnapshotService) Export(ctx context.Context) (snapshot.Snapshot, error) {
+	snap := snapshot.Snapshot{
+		FormatVersion: snapshot.FormatVersion,
+		CreatedAt:     time.Now().UTC(),
+		Teams:         []snapshot.Team{},
+		Users:         []snapshot.User{},
+		PullRequests:  []snapshot.PullRequest{},
+	}
+
+	names, err := s.Teams.ListTeams(ctx)
+	if err != nil {
+		return snapshot.Snapshot{}, err
+	}
+	for _, name := range names {
+		settings, err := s.Teams.GetSettings(ctx, name)
+		if err != nil {
+			return snapshot.Snapshot{}, fmt.Errorf("team %s settings: %w", name, err)
+		}
+		snap.Teams = append(snap.Teams, snapshot.NewTeam(settings))
+
+		team, err := s.Teams.GetTeam(ctx, name)
+		if err != nil {
+			return snapshot.Snapshot{}, fmt.Errorf("team %s: %w", name, err)
+		}
+		for _, m := range team.Members {
+			m.TeamName = name
+			snap.Users = append(snap.Users, snapshot.NewUser(m))
+		}
+	}
+
+	prs, err := s.Prs.List(ctx)
+	if err != nil {
+		return snapshot.Snapshot{}, err
+	}
+	for _, pr := range prs {
+		snap.PullRequests = append(snap.PullRequests, snapshot.NewPullRequest(pr))
+	}
+
+	return snap, nil
+}
+
+// Restore writes the snapshot in a single unit of work. Teams and users are
+// created or updated; pull requests must not exist yet. References to teams
+// or users outside the snapshot must already exist in the target. Any
+// conflict aborts the restore before anything is written and is returned in
+// the report together with snapshot.ErrConflict.
+func (s *SnapshotService) Restore(ctx context.Context, snap snapshot.Snapshot) (RestoreReport, error) {
+	var report RestoreReport
+
+	conflicts, err := s.check(ctx, snap)
+	if err != nil {
+		return report, err
+	}
+	if len(conflicts) > 0 {
+		report.Conflicts = conflicts
+		return report, snapshot.ErrConflict
+	}
+
+	err = s.withinTx(ctx, func(ctx context.Context, repos domain.Repositories) error {
+		report = RestoreReport{}
+
+		for _, t := range snap.Teams {
+			name := domain.TeamName(t.TeamName)
+			exists, err := repos.Teams.TeamExists(ctx, name)
+			if err != nil {
+				return err
+			}
+			if exists {
+				report.TeamsUpdated++
+				continue
+			}
+			if err := repos.Teams.CreateTeam(ctx, name); err != nil {
+				return fmt.Errorf("team %s: %w", name, err)
+			}
+			report.TeamsCreated++
+		}
+
+		users := make([]domain.User, 0, len(snap.Users))
+		for _, u := range snap.Users {
+			users = append(users, u.Domain())
+		}
+		if len(users) > 0 {
+			if err := repos.Users.UpsertUsers(ctx, users); err != nil {
+				return err
+			}
+		}
+		report.Users = len(users)
+
+		// Settings go after all teams exist so fallback teams resolve.
+		for _, t := range snap.Teams {
+			settings, ok := t.DomainSettings()
+			if !ok {
+				continue
+			}
+			if err := repos.Teams.UpsertSettings(ctx, settings); err != nil {
+				return fmt.Errorf("team %s settings: %w", t.TeamName, err)
+			}
+		}
+
+		for _, p := range snap.PullRequests {
+			pr := p.Domain()
+			if err := repos.Prs.Create(ctx, pr); err != nil {
+				return fmt.Errorf("pull request %s: %w", pr.ID, err)
+			}
+			for id, state := range pr.ReviewStates {
+				if err := repos.Prs.SetReviewState(ctx, pr.ID, id, state); err != nil {
+					return fmt.Errorf("pull request %s reviewer %s: %w", pr.ID, id, err)
+				}
+			}
+			report.PullRequests++
+		}
+		return nil
+	})
+	if err != nil {
+		return RestoreReport{}, err
+	}
+	return report, nil
+}
+
+// check validates the snapshot and resolves references it does not satisfy
+// itself against the target repositories.
+func (s *SnapshotService) check(ctx context.Context, snap snapshot.Snapshot) ([]snapshot.Conflict, error) {
+	conflicts, missing := snapshot.Validate(snap)
+
+	for name, refs := range missing.Teams {
+		exists, err := s.Teams.TeamExists(ctx, name)
+		if err != nil {
+			return nil, err
+		}
+		if !exists {
+			for _, ref := range refs {
+				conflicts = append(conflicts, snapshot.Conflict{Ref: ref, Message: fmt.Sprintf("team %s does not exist", name)})
+			}
+		}
+	}
+
+	for id, refs := range missing.Users {
+		_, err := s.Users.GetByID(ctx, id)
+		if errors.Is(err, domain.ErrNotFound) {
+			for _, ref := range refs {
+				conflicts = append(conflicts, snapshot.Conflict{Ref: ref, Message: fmt.Sprintf("user %s does not exist", id)})
+			}
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	for _, pr := range snap.PullRequests {
+		if pr.PullRequestID == "" {
+			continue
+		}
+		exists, err := s.Prs.Exists(ctx, domain.PullRequestID(pr.PullRequestID))
+		if err != nil {
+			return nil, err
+		}
+		if exists {
+			conflicts = append(conflicts, snapshot.Conflict{Ref: "pull_request " + pr.PullRequestID, Message: "already exists"})
+		}
+	}
+
+	sort.SliceStable(conflicts, func(i, j int) bool { return conflicts[i].Ref < conflicts[j].Ref })
+	return conflicts, nil
+}
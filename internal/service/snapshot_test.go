@@ -0,0 +1,123 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"pr-reviewer-service/internal/domain"
+	"pr-reviewer-service/internal/repository/memory"
+	"pr-reviewer-service/internal/snapshot"
+)
+
+func testSnapshot() snapshot.Snapshot {
+	created := time.Date(2025, 1, 2, 3, 4, 5, 0, time.UTC)
+	return snapshot.Snapshot{
+		FormatVersion: snapshot.FormatVersion,
+		Teams: []snapshot.Team{
+			{TeamName: "backend", Settings: &snapshot.Settings{ReviewersCount: 2, Strategy: "random", FallbackTeams: []string{"platform"}}},
+			{TeamName: "platform"},
+		},
+		Users: []snapshot.User{
+			{UserID: "u1", Username: "Alice", TeamName: "backend", IsActive: true},
+			{UserID: "u2", Username: "Bob", TeamName: "backend", IsActive: true},
+			{UserID: "u3", Username: "Carol", TeamName: "platform", IsActive: true},
+		},
+		PullRequests: []snapshot.PullRequest{{
+			PullRequestID:   "pr-1",
+			PullRequestName: "Add search",
+			AuthorID:        "u1",
+			Status:          "OPEN",
+			CreatedAt:       created,
+			Reviewers: []snapshot.Reviewer{
+				{UserID: "u2", ReviewState: "APPROVED"},
+				{UserID: "u3", FallbackTeam: "platform", ReviewState: "PENDING"},
+			},
+		}},
+	}
+}
+
+func TestSnapshotService_Restore(t *testing.T) {
+	ctx := context.Background()
+
+	repos := newTestRepos(t, nil)
+	uow := &countingUnitOfWork{UnitOfWork: memory.NewUnitOfWork(repos.store)}
+	svc := NewSnapshotService(repos.teams, repos.users, repos.prs)
+	svc.Tx = uow
+
+	report, err := svc.Restore(ctx, testSnapshot())
+	if err != nil {
+		t.Fatalf("Restore returned error: %v", err)
+	}
+	if uow.calls != 1 {
+		t.Fatalf("expected restore to run in one unit of work, got %d", uow.calls)
+	}
+	if report.TeamsCreated != 2 || report.Users != 3 || report.PullRequests != 1 {
+		t.Fatalf("unexpected report: %+v", report)
+	}
+
+	if got, err := repos.teams.GetSettings(ctx, "backend"); err != nil || got.ReviewersCount != 2 || len(got.FallbackTeams) != 1 {
+		t.Fatalf("settings not restored: %+v, %v", got, err)
+	}
+	if u, err := repos.users.GetByID(ctx, "u3"); err != nil || u.TeamName != "platform" {
+		t.Fatalf("user team not restored: %+v, %v", u, err)
+	}
+
+	pr := repos.getPR(t, "pr-1")
+	if len(pr.AssignedReviewers) != 2 || pr.FallbackReviewers["u3"] != "platform" {
+		t.Fatalf("reviewers not restored: %+v", pr)
+	}
+	if pr.ReviewState("u2") != domain.ReviewStateApproved || pr.ReviewState("u3") != domain.ReviewStatePending {
+		t.Fatalf("review states not restored: %+v", pr.ReviewStates)
+	}
+}
+
+func TestSnapshotService_RestoreReportsConflicts(t *testing.T) {
+	ctx := context.Background()
+
+	// pr-1 already exists, written by someone outside the snapshot.
+	repos := newTestRepos(t, []domain.User{{ID: "x1", Username: "Xavier", TeamName: "existing", IsActive: true}})
+	repos.addPR(t, domain.PullRequest{ID: "pr-1", AuthorID: "x1", Status: domain.PRStatusOpen})
+	svc := NewSnapshotService(repos.teams, repos.users, repos.prs)
+
+	snap := testSnapshot()
+	snap.Teams = snap.Teams[:1]
+	snap.Users = snap.Users[:2]
+	snap.PullRequests[0].Reviewers = append(snap.PullRequests[0].Reviewers, snapshot.Reviewer{UserID: "u2"})
+
+	report, err := svc.Restore(ctx, snap)
+	if !errors.Is(err, snapshot.ErrConflict) {
+		t.Fatalf("expected ErrConflict, got %v", err)
+	}
+
+	want := map[string]bool{
+		"pull_request pr-1: already exists":                                         false,
+		"pull_request pr-1 reviewer u2: duplicate reviewer":                         false,
+		"pull_request pr-1 reviewer u3: user u3 does not exist":                     false,
+		"pull_request pr-1 reviewer u3 fallback_team: team platform does not exist": false,
+		"team backend fallback_teams: team platform does not exist":                 false,
+	}
+	for _, c := range report.Conflicts {
+		if _, ok := want[c.String()]; !ok {
+			t.Fatalf("unexpected conflict %q", c)
+		}
+		want[c.String()] = true
+	}
+	for c, seen := range want {
+		if !seen {
+			t.Fatalf("missing conflict %q in %v", c, report.Conflicts)
+		}
+	}
+
+	names, err := repos.teams.ListTeams(ctx)
+	if err != nil {
+		t.Fatalf("ListTeams returned error: %v", err)
+	}
+	if len(names) != 1 {
+		t.Fatalf("nothing must be written on conflict, got teams %v", names)
+	}
+	if _, err := repos.users.GetByID(ctx, "u1"); err != domain.ErrNotFound {
+		t.Fatalf("nothing must be written on conflict, got user u1: %v", err)
+	}
+}
@@ -2,6 +2,10 @@ package service
 
 import (
 	"context"
+	"fmt"
+	"time"
+
+	"pr-reviewer-service/internal/codeowners"
 	"pr-reviewer-service/internal/domain"
 )
 
@@ -18,6 +22,15 @@ func NewTeamService(teams domain.TeamRepository, users domain.UserRepository) *T
 }
 
 func (s *TeamService) AddTeam(ctx context.Context, name domain.TeamName, members []domain.User) (domain.Team, error) {
+	ctx, span := tracer.Start(ctx, "TeamService.AddTeam")
+	defer span.End()
+
+	for _, m := range members {
+		if m.MaxOpenReviews < 0 {
+			return domain.Team{}, domain.ErrInvalidCapacity
+		}
+	}
+
 	exists, err := s.teams.TeamExists(ctx, name)
 	if err != nil {
 		return domain.Team{}, err
@@ -46,10 +59,157 @@ func (s *TeamService) AddTeam(ctx context.Context, name domain.TeamName, members
 	return team, nil
 }
 
+// UpsertTeam creates the team if needed and creates or updates its members.
+// Existing members missing from the list are left untouched.
+func (s *TeamService) UpsertTeam(ctx context.Context, name domain.TeamName, members []domain.User) (domain.Team, error) {
+	ctx, span := tracer.Start(ctx, "TeamService.UpsertTeam")
+	defer span.End()
+
+	for _, m := range members {
+		if m.MaxOpenReviews < 0 {
+			return domain.Team{}, domain.ErrInvalidCapacity
+		}
+	}
+
+	exists, err := s.teams.TeamExists(ctx, name)
+	if err != nil {
+		return domain.Team{}, err
+	}
+	if !exists {
+		if err := s.teams.CreateTeam(ctx, name); err != nil {
+			return domain.Team{}, err
+		}
+	}
+
+	for i := range members {
+		members[i].TeamName = name
+	}
+
+	if err := s.users.UpsertUsers(ctx, members); err != nil {
+		return domain.Team{}, err
+	}
+
+	return s.teams.GetTeam(ctx, name)
+}
+
+func (s *TeamService) ListTeams(ctx context.Context) ([]domain.TeamName, error) {
+	ctx, span := tracer.Start(ctx, "TeamService.ListTeams")
+	defer span.End()
+
+	return s.teams.ListTeams(ctx)
+}
+
 func (s *TeamService) GetTeam(ctx context.Context, name domain.TeamName) (domain.Team, error) {
+	ctx, span := tracer.Start(ctx, "TeamService.GetTeam")
+	defer span.End()
+
 	team, err := s.teams.GetTeam(ctx, name)
 	if err != nil {
 		return domain.Team{}, err
 	}
 	return team, nil
 }
+
+func (s *TeamService) GetSettings(ctx context.Context, name domain.TeamName) (domain.TeamSettings, error) {
+	ctx, span := tracer.Start(ctx, "TeamService.GetSettings")
+	defer span.End()
+
+	return s.teams.GetSettings(ctx, name)
+}
+
+func (s *TeamService) UpdateSettings(ctx context.Context, settings domain.TeamSettings) (domain.TeamSettings, error) {
+	ctx, span := tracer.Start(ctx, "TeamService.UpdateSettings")
+	defer span.End()
+
+	if settings.ReviewersCount < 0 || settings.MinPoolSize < 0 || settings.RequiredApprovals < 0 ||
+		!settings.Strategy.Valid() {
+		return domain.TeamSettings{}, domain.ErrInvalidSettings
+	}
+	if settings.SlackWebhookURL != "" && !isHTTPURL(settings.SlackWebhookURL) {
+		return domain.TeamSettings{}, domain.ErrInvalidSettings
+	}
+
+	exists, err := s.teams.TeamExists(ctx, settings.TeamName)
+	if err != nil {
+		return domain.TeamSettings{}, err
+	}
+	if !exists {
+		return domain.TeamSettings{}, domain.ErrNotFound
+	}
+
+	seen := make(map[domain.TeamName]struct{}, len(settings.FallbackTeams))
+	for _, fallback := range settings.FallbackTeams {
+		if fallback == settings.TeamName {
+			return domain.TeamSettings{}, domain.ErrInvalidSettings
+		}
+		if _, ok := seen[fallback]; ok {
+			return domain.TeamSettings{}, domain.ErrInvalidSettings
+		}
+		seen[fallback] = struct{}{}
+
+		exists, err := s.teams.TeamExists(ctx, fallback)
+		if err != nil {
+			return domain.TeamSettings{}, err
+		}
+		if !exists {
+			return domain.TeamSettings{}, domain.ErrNotFound
+		}
+	}
+
+	if err := s.teams.UpsertSettings(ctx, settings); err != nil {
+		return domain.TeamSettings{}, err
+	}
+
+	return settings, nil
+}
+
+func (s *TeamService) GetCodeowners(ctx context.Context, name domain.TeamName) (domain.Codeowners, codeowners.Ruleset, error) {
+	ctx, span := tracer.Start(ctx, "TeamService.GetCodeowners")
+	defer span.End()
+
+	c, err := s.teams.GetCodeowners(ctx, name)
+	if err != nil {
+		return domain.Codeowners{}, codeowners.Ruleset{}, err
+	}
+
+	rules, err := codeowners.Parse(c.Content)
+	if err != nil {
+		return domain.Codeowners{}, codeowners.Ruleset{}, fmt.Errorf("%w: %v", domain.ErrInvalidCodeowners, err)
+	}
+	return c, rules, nil
+}
+
+// UploadCodeowners replaces the team's ruleset; content must parse as a
+// CODEOWNERS file.
+func (s *TeamService) UploadCodeowners(
+	ctx context.Context,
+	name domain.TeamName,
+	content string,
+) (domain.Codeowners, codeowners.Ruleset, error) {
+	ctx, span := tracer.Start(ctx, "TeamService.UploadCodeowners")
+	defer span.End()
+
+	rules, err := codeowners.Parse(content)
+	if err != nil {
+		return domain.Codeowners{}, codeowners.Ruleset{}, fmt.Errorf("%w: %v", domain.ErrInvalidCodeowners, err)
+	}
+
+	exists, err := s.teams.TeamExists(ctx, name)
+	if err != nil {
+		return domain.Codeowners{}, codeowners.Ruleset{}, err
+	}
+	if !exists {
+		return domain.Codeowners{}, codeowners.Ruleset{}, domain.ErrNotFound
+	}
+
+	c := domain.Codeowners{
+		TeamName:  name,
+		Content:   content,
+		UpdatedAt: time.Now().UTC(),
+	}
+	if err := s.teams.PutCodeowners(ctx, c); err != nil {
+		return domain.Codeowners{}, codeowners.Ruleset{}, err
+	}
+
+	return c, rules, nil
+}
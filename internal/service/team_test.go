@@ -2,75 +2,17 @@ package service
 
 import (
 	"context"
-	"pr-reviewer-service/internal/domain"
+	"reflect"
 	"testing"
-)
-
-type fakeTeamRepo struct {
-	teams map[domain.TeamName]domain.Team
-}
-
-func newFakeTeamRepo() *fakeTeamRepo {
-	return &fakeTeamRepo{
-		teams: make(map[domain.TeamName]domain.Team),
-	}
-}
-
-func (r *fakeTeamRepo) CreateTeam(ctx context.Context, name domain.TeamName) error {
-	if r.teams == nil {
-		r.teams = make(map[domain.TeamName]domain.Team)
-	}
-	r.teams[name] = domain.Team{}
-	return nil
-}
-
-func (r *fakeTeamRepo) GetTeam(ctx context.Context, name domain.TeamName) (domain.Team, error) {
-	t, ok := r.teams[name]
-	if !ok {
-		return domain.Team{}, domain.ErrNotFound
-	}
-	return t, nil
-}
-
-func (r *fakeTeamRepo) TeamExists(ctx context.Context, name domain.TeamName) (bool, error) {
-	_, ok := r.teams[name]
-	return ok, nil
-}
-
-type fakeUserRepoForTeam struct {
-	upserted []domain.User
-}
-
-func newFakeUserRepoForTeam() *fakeUserRepoForTeam {
-	return &fakeUserRepoForTeam{
-		upserted: make([]domain.User, 0),
-	}
-}
-
-func (r *fakeUserRepoForTeam) UpsertUsers(ctx context.Context, users []domain.User) error {
-	r.upserted = append(r.upserted, users...)
-	return nil
-}
-
-func (r *fakeUserRepoForTeam) GetByID(ctx context.Context, id domain.UserID) (domain.User, error) {
-	return domain.User{}, domain.ErrNotFound
-}
 
-func (r *fakeUserRepoForTeam) SetIsActive(ctx context.Context, id domain.UserID, isActive bool) (domain.User, error) {
-	return domain.User{}, domain.ErrNotFound
-}
-
-func (r *fakeUserRepoForTeam) ListActiveByTeam(ctx context.Context, teamName domain.TeamName) ([]domain.User, error) {
-	return nil, nil
-}
+	"pr-reviewer-service/internal/domain"
+)
 
 func TestTeamService_AddTeam_Success(t *testing.T) {
 	ctx := context.Background()
 
-	teamRepo := newFakeTeamRepo()
-	userRepo := newFakeUserRepoForTeam()
-
-	svc := NewTeamService(teamRepo, userRepo)
+	repos := newTestRepos(t, nil)
+	svc := NewTeamService(repos.teams, repos.users)
 
 	teamName := domain.TeamName("backend")
 	members := []domain.User{
@@ -83,19 +25,18 @@ func TestTeamService_AddTeam_Success(t *testing.T) {
 		t.Fatalf("AddTeam returned error: %v", err)
 	}
 
-	if len(userRepo.upserted) != len(members) {
-		t.Fatalf("expected %d upserted users, got %d", len(members), len(userRepo.upserted))
+	team, err := repos.teams.GetTeam(ctx, teamName)
+	if err != nil {
+		t.Fatalf("GetTeam returned error: %v", err)
 	}
-
-	upsertedByID := make(map[domain.UserID]domain.User)
-	for _, u := range userRepo.upserted {
-		upsertedByID[u.ID] = u
+	if len(team.Members) != len(members) {
+		t.Fatalf("expected %d upserted users, got %d", len(members), len(team.Members))
 	}
 
 	for _, orig := range members {
-		u, ok := upsertedByID[orig.ID]
-		if !ok {
-			t.Fatalf("user %s was not upserted", orig.ID)
+		u, err := repos.users.GetByID(ctx, orig.ID)
+		if err != nil {
+			t.Fatalf("user %s was not upserted: %v", orig.ID, err)
 		}
 		if u.TeamName != teamName {
 			t.Fatalf("user %s must have TeamName=%s, got %s", orig.ID, teamName, u.TeamName)
@@ -106,13 +47,13 @@ func TestTeamService_AddTeam_Success(t *testing.T) {
 func TestTeamService_AddTeam_AlreadyExists(t *testing.T) {
 	ctx := context.Background()
 
-	teamRepo := newFakeTeamRepo()
-	userRepo := newFakeUserRepoForTeam()
-
+	repos := newTestRepos(t, nil)
 	teamName := domain.TeamName("backend")
-	teamRepo.teams[teamName] = domain.Team{}
+	if err := repos.teams.CreateTeam(ctx, teamName); err != nil {
+		t.Fatalf("CreateTeam returned error: %v", err)
+	}
 
-	svc := NewTeamService(teamRepo, userRepo)
+	svc := NewTeamService(repos.teams, repos.users)
 
 	_, err := svc.AddTeam(ctx, teamName, nil)
 	if err == nil {
@@ -123,16 +64,52 @@ func TestTeamService_AddTeam_AlreadyExists(t *testing.T) {
 	}
 }
 
-func TestTeamService_GetTeam(t *testing.T) {
+func TestTeamService_UpsertTeam(t *testing.T) {
 	ctx := context.Background()
 
-	teamRepo := newFakeTeamRepo()
-	userRepo := newFakeUserRepoForTeam()
+	repos := newTestRepos(t, nil)
+	teamName := domain.TeamName("backend")
+	if err := repos.teams.CreateTeam(ctx, teamName); err != nil {
+		t.Fatalf("CreateTeam returned error: %v", err)
+	}
+
+	svc := NewTeamService(repos.teams, repos.users)
+
+	if _, err := svc.UpsertTeam(ctx, teamName, []domain.User{{ID: "u1", Username: "Alice", IsActive: true}}); err != nil {
+		t.Fatalf("UpsertTeam on existing team returned error: %v", err)
+	}
+	if _, err := svc.UpsertTeam(ctx, "frontend", []domain.User{{ID: "u2", Username: "Bob", IsActive: true}}); err != nil {
+		t.Fatalf("UpsertTeam on new team returned error: %v", err)
+	}
+
+	if ok, _ := repos.teams.TeamExists(ctx, "frontend"); !ok {
+		t.Fatalf("expected frontend team to be created")
+	}
+	u1, _ := repos.users.GetByID(ctx, "u1")
+	u2, _ := repos.users.GetByID(ctx, "u2")
+	if u1.TeamName != teamName || u2.TeamName != "frontend" {
+		t.Fatalf("unexpected upserted users: %+v, %+v", u1, u2)
+	}
 
+	names, err := svc.ListTeams(ctx)
+	if err != nil {
+		t.Fatalf("ListTeams returned error: %v", err)
+	}
+	if !reflect.DeepEqual(names, []domain.TeamName{"backend", "frontend"}) {
+		t.Fatalf("unexpected teams: %v", names)
+	}
+}
+
+func TestTeamService_GetTeam(t *testing.T) {
+	ctx := context.Background()
+
+	repos := newTestRepos(t, nil)
 	teamName := domain.TeamName("backend")
-	teamRepo.teams[teamName] = domain.Team{}
+	if err := repos.teams.CreateTeam(ctx, teamName); err != nil {
+		t.Fatalf("CreateTeam returned error: %v", err)
+	}
 
-	svc := NewTeamService(teamRepo, userRepo)
+	svc := NewTeamService(repos.teams, repos.users)
 
 	_, err := svc.GetTeam(ctx, teamName)
 	if err != nil {
@@ -147,3 +124,57 @@ func TestTeamService_GetTeam(t *testing.T) {
 		t.Fatalf("expected ErrNotFound, got %v", err)
 	}
 }
+
+func TestTeamService_UpdateSettings(t *testing.T) {
+	ctx := context.Background()
+
+	repos := newTestRepos(t, nil)
+	teamName := domain.TeamName("platform")
+	if err := repos.teams.CreateTeam(ctx, teamName); err != nil {
+		t.Fatalf("CreateTeam returned error: %v", err)
+	}
+
+	svc := NewTeamService(repos.teams, repos.users)
+
+	settings := domain.TeamSettings{
+		TeamName:       teamName,
+		ReviewersCount: 3,
+		Strategy:       domain.ReviewerStrategyLeastLoaded,
+		MinPoolSize:    2,
+	}
+	if _, err := svc.UpdateSettings(ctx, settings); err != nil {
+		t.Fatalf("UpdateSettings returned error: %v", err)
+	}
+
+	got, err := svc.GetSettings(ctx, teamName)
+	if err != nil {
+		t.Fatalf("GetSettings returned error: %v", err)
+	}
+	if !reflect.DeepEqual(got, settings) {
+		t.Fatalf("expected %+v, got %+v", settings, got)
+	}
+
+	invalid := settings
+	invalid.Strategy = "round_robin"
+	if _, err := svc.UpdateSettings(ctx, invalid); err != domain.ErrInvalidSettings {
+		t.Fatalf("expected ErrInvalidSettings, got %v", err)
+	}
+
+	unknown := settings
+	unknown.TeamName = "unknown"
+	if _, err := svc.UpdateSettings(ctx, unknown); err != domain.ErrNotFound {
+		t.Fatalf("expected ErrNotFound, got %v", err)
+	}
+
+	selfFallback := settings
+	selfFallback.FallbackTeams = []domain.TeamName{teamName}
+	if _, err := svc.UpdateSettings(ctx, selfFallback); err != domain.ErrInvalidSettings {
+		t.Fatalf("expected ErrInvalidSettings for self fallback, got %v", err)
+	}
+
+	unknownFallback := settings
+	unknownFallback.FallbackTeams = []domain.TeamName{"unknown"}
+	if _, err := svc.UpdateSettings(ctx, unknownFallback); err != domain.ErrNotFound {
+		t.Fatalf("expected ErrNotFound for unknown fallback, got %v", err)
+	}
+}
@@ -0,0 +1,7 @@
+package service
+
+import "go.opentelemetry.io/otel"
+
+// tracer resolves to the global provider once one is installed, so spans
+// cost nothing until tracing is configured.
+var tracer = otel.Tracer("pr-reviewer-service/internal/service")
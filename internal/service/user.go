@@ -3,10 +3,16 @@ package service
 import (
 	"context"
 	"pr-reviewer-service/internal/domain"
+	"time"
 )
 
 type UserService struct {
 	users domain.UserRepository
+	// Outbox receives integration events; it may be nil.
+	Outbox domain.OutboxRepository
+	// Tx makes a status change and its event atomic. Without it the
+	// repositories above are used directly.
+	Tx domain.UnitOfWork
 }
 
 func NewUserService(users domain.UserRepository) *UserService {
@@ -15,10 +21,78 @@ func NewUserService(users domain.UserRepository) *UserService {
 	}
 }
 
+func (s *UserService) Get(ctx context.Context, id domain.UserID) (domain.User, error) {
+	return s.users.GetByID(ctx, id)
+}
+
+func (s *UserService) withinTx(ctx context.Context, fn func(ctx context.Context, repos domain.Repositories) error) error {
+	if s.Tx == nil {
+		return fn(ctx, domain.Repositories{Users: s.users, Outbox: s.Outbox})
+	}
+	return s.Tx.Do(ctx, fn)
+}
+
+// SetIsActive stores the actor from ctx with the status change, so every
+// backend can tell who made it, and repeats it on the emitted event.
 func (s *UserService) SetIsActive(ctx context.Context, id domain.UserID, isActive bool) (domain.User, error) {
-	user, err := s.users.SetIsActive(ctx, id, isActive)
+	actor := domain.ActorFromContext(ctx)
+
+	var user domain.User
+	err := s.withinTx(ctx, func(ctx context.Context, repos domain.Repositories) error {
+		var err error
+		user, err = repos.Users.SetIsActive(ctx, id, isActive, actor)
+		if err != nil {
+			return err
+		}
+
+		now := time.Now().UTC()
+		changed, err := newOutboxEvent(domain.OutboxUserStatusChanged, userStatusChangedPayload{
+			UserID:    id,
+			IsActive:  isActive,
+			Actor:     actor,
+			ChangedAt: now,
+		}, now)
+		if err != nil {
+			return err
+		}
+		return emit(ctx, repos, changed)
+	})
 	if err != nil {
 		return domain.User{}, err
 	}
 	return user, nil
 }
+
+func (s *UserService) AddUnavailability(ctx context.Context, u domain.Unavailability) (domain.Unavailability, error) {
+	if !u.EndsAt.After(u.StartsAt) {
+		return domain.Unavailability{}, domain.ErrInvalidPeriod
+	}
+
+	if _, err := s.users.GetByID(ctx, u.UserID); err != nil {
+		return domain.Unavailability{}, err
+	}
+
+	return s.users.AddUnavailability(ctx, u)
+}
+
+func (s *UserService) ListUnavailability(ctx context.Context, id domain.UserID) ([]domain.Unavailability, error) {
+	if _, err := s.users.GetByID(ctx, id); err != nil {
+		return nil, err
+	}
+	return s.users.ListUnavailability(ctx, id)
+}
+
+func (s *UserService) GetUnavailability(ctx context.Context, id int64) (domain.Unavailability, error) {
+	return s.users.GetUnavailability(ctx, id)
+}
+
+func (s *UserService) RemoveUnavailability(ctx context.Context, id int64) error {
+	return s.users.DeleteUnavailability(ctx, id)
+}
+
+func (s *UserService) SetMaxOpenReviews(ctx context.Context, id domain.UserID, maxOpenReviews int) (domain.User, error) {
+	if maxOpenReviews < 0 {
+		return domain.User{}, domain.ErrInvalidCapacity
+	}
+	return s.users.SetMaxOpenReviews(ctx, id, maxOpenReviews)
+}
@@ -0,0 +1,70 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"pr-reviewer-service/internal/domain"
+	"testing"
+)
+
+type fakeOutbox struct {
+	events []domain.OutboxEvent
+}
+
+func (o *fakeOutbox) AppendOutbox(ctx context.Context, events []domain.OutboxEvent) error {
+	o.events = append(o.events, events...)
+	return nil
+}
+
+func TestUserService_SetIsActive_RecordsActor(t *testing.T) {
+	ctx := context.Background()
+
+	repos := newTestRepos(t, []domain.User{{ID: "u1", Username: "Alice", TeamName: "backend", IsActive: true}})
+
+	outbox := &fakeOutbox{}
+	svc := NewUserService(repos.users)
+	svc.Outbox = outbox
+
+	user, err := svc.SetIsActive(domain.ContextWithActor(ctx, "lead"), "u1", false)
+	if err != nil {
+		t.Fatalf("SetIsActive returned error: %v", err)
+	}
+	if user.IsActive {
+		t.Fatalf("expected user to be inactive")
+	}
+
+	if len(outbox.events) != 1 || outbox.events[0].Type != domain.OutboxUserStatusChanged {
+		t.Fatalf("unexpected outbox events: %+v", outbox.events)
+	}
+	var payload userStatusChangedPayload
+	if err := json.Unmarshal(outbox.events[0].Payload, &payload); err != nil {
+		t.Fatalf("decode payload: %v", err)
+	}
+	if payload.UserID != "u1" || payload.IsActive || payload.Actor != "lead" {
+		t.Fatalf("unexpected payload: %+v", payload)
+	}
+
+	changes, err := repos.users.ListStatusChanges(ctx, "u1")
+	if err != nil {
+		t.Fatalf("ListStatusChanges returned error: %v", err)
+	}
+	if len(changes) != 1 || changes[0].Actor != "lead" || changes[0].IsActive {
+		t.Fatalf("expected the change to be stored with its actor, got %+v", changes)
+	}
+
+	svc.Outbox = nil
+	if _, err := svc.SetIsActive(domain.ContextWithActor(ctx, "admin"), "u1", true); err != nil {
+		t.Fatalf("SetIsActive without outbox returned error: %v", err)
+	}
+	if changes, _ := repos.users.ListStatusChanges(ctx, "u1"); len(changes) != 2 || changes[1].Actor != "admin" {
+		t.Fatalf("expected the actor to be stored without an outbox, got %+v", changes)
+	}
+	svc.Outbox = outbox
+
+	if _, err := svc.SetIsActive(ctx, "unknown", true); err != domain.ErrNotFound {
+		t.Fatalf("expected ErrNotFound, got %v", err)
+	}
+	if len(outbox.events) != 1 {
+		t.Fatalf("failed change must not emit, got %d events", len(outbox.events))
+	}
+}
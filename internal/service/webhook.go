@@ -0,0 +1,68 @@
+package service
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"time"
+
+	"pr-reviewer-service/internal/domain"
+)
+
+type WebhookService struct {
+	webhooks domain.WebhookRepository
+}
+
+func NewWebhookService(webhooks domain.WebhookRepository) *WebhookService {
+	return &WebhookService{
+		webhooks: webhooks,
+	}
+}
+
+// Subscribe registers a delivery URL. When secret is empty a random one is
+// generated; it is only returned here.
+func (s *WebhookService) Subscribe(
+	ctx context.Context,
+	rawURL, secret string,
+	events []domain.OutboxEventType,
+) (domain.WebhookSubscription, error) {
+	if !isHTTPURL(rawURL) {
+		return domain.WebhookSubscription{}, domain.ErrInvalidWebhook
+	}
+	for _, t := range events {
+		if !t.Valid() {
+			return domain.WebhookSubscription{}, domain.ErrInvalidWebhook
+		}
+	}
+
+	if secret == "" {
+		buf := make([]byte, 32)
+		if _, err := rand.Read(buf); err != nil {
+			return domain.WebhookSubscription{}, err
+		}
+		secret = hex.EncodeToString(buf)
+	}
+
+	return s.webhooks.CreateSubscription(ctx, domain.WebhookSubscription{
+		URL:       rawURL,
+		Secret:    secret,
+		Events:    events,
+		CreatedAt: time.Now().UTC(),
+	})
+}
+
+func (s *WebhookService) List(ctx context.Context) ([]domain.WebhookSubscription, error) {
+	return s.webhooks.ListSubscriptions(ctx)
+}
+
+func (s *WebhookService) Unsubscribe(ctx context.Context, id int64) error {
+	return s.webhooks.DeleteSubscription(ctx, id)
+}
+
+func (s *WebhookService) DeadLetters(ctx context.Context) ([]domain.WebhookDelivery, error) {
+	return s.webhooks.ListDeadLetters(ctx)
+}
+
+func (s *WebhookService) Redrive(ctx context.Context, deliveryID int64) error {
+	return s.webhooks.Redrive(ctx, deliveryID, time.Now().UTC())
+}
@@ -0,0 +1,325 @@
+// Package snapshot defines the versioned JSON format used to move teams,
+// users, pull requests and their reviewer assignments between environments.
+package snapshot
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"time"
+
+	"pr-reviewer-service/internal/domain"
+)
+
+// FormatVersion is written to every snapshot; Decode refuses other versions.
+const FormatVersion = 1
+
+var (
+	ErrUnsupportedVersion = errors.New("unsupported snapshot format version")
+	ErrConflict           = errors.New("snapshot conflicts with itself or the target data")
+)
+
+type Snapshot struct {
+	FormatVersion int           `json:"format_version"`
+	CreatedAt     time.Time     `json:"created_at"`
+	Teams         []Team        `json:"teams"`
+	Users         []User        `json:"users"`
+	PullRequests  []PullRequest `json:"pull_requests"`
+}
+
+type Team struct {
+	TeamName string    `json:"team_name"`
+	Settings *Settings `json:"settings,omitempty"`
+}
+
+type Settings struct {
+	ReviewersCount    int      `json:"reviewers_count"`
+	Strategy          string   `json:"strategy"`
+	MinPoolSize       int      `json:"min_pool_size"`
+	FallbackTeams     []string `json:"fallback_teams,omitempty"`
+	SlackWebhookURL   string   `json:"slack_webhook_url,omitempty"`
+	RequiredApprovals int      `json:"required_approvals,omitempty"`
+}
+
+type User struct {
+	UserID         string `json:"user_id"`
+	Username       string `json:"username"`
+	TeamName       string `json:"team_name"`
+	IsActive       bool   `json:"is_active"`
+	SlackHandle    string `json:"slack_handle,omitempty"`
+	MaxOpenReviews int    `json:"max_open_reviews,omitempty"`
+}
+
+type PullRequest struct {
+	PullRequestID   string     `json:"pull_request_id"`
+	PullRequestName string     `json:"pull_request_name"`
+	AuthorID        string     `json:"author_id"`
+	Status          string     `json:"status"`
+	CreatedAt       time.Time  `json:"created_at"`
+	MergedAt        *time.Time `json:"merged_at,omitempty"`
+	ClosedAt        *time.Time `json:"closed_at,omitempty"`
+	ClosedFrom      string     `json:"closed_from,omitempty"`
+	Reviewers       []Reviewer `json:"reviewers"`
+}
+
+type Reviewer struct {
+	UserID       string `json:"user_id"`
+	FallbackTeam string `json:"fallback_team,omitempty"`
+	MatchedRule  string `json:"matched_rule,omitempty"`
+	ReviewState  string `json:"review_state"`
+}
+
+// Conflict is one problem found while validating or restoring a snapshot.
+type Conflict struct {
+	// Ref points at the offending record, e.g. "pull_request pr-1".
+	Ref     string `json:"ref"`
+	Message string `json:"message"`
+}
+
+func (c Conflict) String() string {
+	return c.Ref + ": " + c.Message
+}
+
+func Encode(w io.Writer, s Snapshot) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(s)
+}
+
+func Decode(r io.Reader) (Snapshot, error) {
+	var s Snapshot
+	if err := json.NewDecoder(r).Decode(&s); err != nil {
+		return Snapshot{}, fmt.Errorf("decode snapshot: %w", err)
+	}
+	if s.FormatVersion != FormatVersion {
+		return Snapshot{}, fmt.Errorf("%w: %d", ErrUnsupportedVersion, s.FormatVersion)
+	}
+	return s, nil
+}
+
+// Validate checks the snapshot on its own: duplicate keys, unknown statuses
+// and references to teams or users missing from it. Callers resolve the
+// returned Missing references against the target before deciding whether
+// they are conflicts.
+func Validate(s Snapshot) ([]Conflict, Missing) {
+	var conflicts []Conflict
+	missing := Missing{Teams: map[domain.TeamName][]string{}, Users: map[domain.UserID][]string{}}
+
+	teams := make(map[string]struct{}, len(s.Teams))
+	for _, t := range s.Teams {
+		ref := "team " + t.TeamName
+		if t.TeamName == "" {
+			conflicts = append(conflicts, Conflict{Ref: "team", Message: "team_name is empty"})
+			continue
+		}
+		if _, ok := teams[t.TeamName]; ok {
+			conflicts = append(conflicts, Conflict{Ref: ref, Message: "duplicate team"})
+		}
+		teams[t.TeamName] = struct{}{}
+	}
+
+	teamRef := func(name, ref string) {
+		if _, ok := teams[name]; !ok {
+			missing.Teams[domain.TeamName(name)] = append(missing.Teams[domain.TeamName(name)], ref)
+		}
+	}
+
+	for _, t := range s.Teams {
+		if t.Settings == nil {
+			continue
+		}
+		ref := "team " + t.TeamName
+		if !domain.ReviewerStrategy(t.Settings.Strategy).Valid() {
+			conflicts = append(conflicts, Conflict{Ref: ref, Message: fmt.Sprintf("unknown strategy %q", t.Settings.Strategy)})
+		}
+		for _, f := range t.Settings.FallbackTeams {
+			teamRef(f, ref+" fallback_teams")
+		}
+	}
+
+	users := make(map[string]struct{}, len(s.Users))
+	for _, u := range s.Users {
+		ref := "user " + u.UserID
+		if u.UserID == "" {
+			conflicts = append(conflicts, Conflict{Ref: "user", Message: "user_id is empty"})
+			continue
+		}
+		if _, ok := users[u.UserID]; ok {
+			conflicts = append(conflicts, Conflict{Ref: ref, Message: "duplicate user"})
+		}
+		users[u.UserID] = struct{}{}
+		teamRef(u.TeamName, ref+" team_name")
+	}
+
+	userRef := func(id, ref string) {
+		if _, ok := users[id]; !ok {
+			missing.Users[domain.UserID(id)] = append(missing.Users[domain.UserID(id)], ref)
+		}
+	}
+
+	prs := make(map[string]struct{}, len(s.PullRequests))
+	for _, pr := range s.PullRequests {
+		ref := "pull_request " + pr.PullRequestID
+		if pr.PullRequestID == "" {
+			conflicts = append(conflicts, Conflict{Ref: "pull_request", Message: "pull_request_id is empty"})
+			continue
+		}
+		if _, ok := prs[pr.PullRequestID]; ok {
+			conflicts = append(conflicts, Conflict{Ref: ref, Message: "duplicate pull request"})
+		}
+		prs[pr.PullRequestID] = struct{}{}
+
+		switch domain.PRStatus(pr.Status) {
+		case domain.PRStatusDraft, domain.PRStatusOpen, domain.PRStatusMerged, domain.PRStatusClosed:
+		default:
+			conflicts = append(conflicts, Conflict{Ref: ref, Message: fmt.Sprintf("unknown status %q", pr.Status)})
+		}
+		userRef(pr.AuthorID, ref+" author_id")
+
+		reviewers := make(map[string]struct{}, len(pr.Reviewers))
+		for _, r := range pr.Reviewers {
+			rref := ref + " reviewer " + r.UserID
+			if _, ok := reviewers[r.UserID]; ok {
+				conflicts = append(conflicts, Conflict{Ref: rref, Message: "duplicate reviewer"})
+			}
+			reviewers[r.UserID] = struct{}{}
+
+			state := domain.ReviewState(r.ReviewState)
+			if state != "" && state != domain.ReviewStatePending && !state.Valid() {
+				conflicts = append(conflicts, Conflict{Ref: rref, Message: fmt.Sprintf("unknown review_state %q", r.ReviewState)})
+			}
+			userRef(r.UserID, rref)
+			if r.FallbackTeam != "" {
+				teamRef(r.FallbackTeam, rref+" fallback_team")
+			}
+		}
+	}
+
+	return conflicts, missing
+}
+
+// Missing lists references to teams and users that are not part of the
+// snapshot, keyed by id, with the records that refer to them.
+type Missing struct {
+	Teams map[domain.TeamName][]string
+	Users map[domain.UserID][]string
+}
+
+func NewTeam(settings domain.TeamSettings) Team {
+	t := Team{
+		TeamName: string(settings.TeamName),
+		Settings: &Settings{
+			ReviewersCount:    settings.ReviewersCount,
+			Strategy:          string(settings.Strategy),
+			MinPoolSize:       settings.MinPoolSize,
+			SlackWebhookURL:   settings.SlackWebhookURL,
+			RequiredApprovals: settings.RequiredApprovals,
+		},
+	}
+	for _, f := range settings.FallbackTeams {
+		t.Settings.FallbackTeams = append(t.Settings.FallbackTeams, string(f))
+	}
+	return t
+}
+
+// DomainSettings returns false when the snapshot leaves settings unchanged.
+func (t Team) DomainSettings() (domain.TeamSettings, bool) {
+	if t.Settings == nil {
+		return domain.TeamSettings{}, false
+	}
+
+	settings := domain.TeamSettings{
+		TeamName:          domain.TeamName(t.TeamName),
+		ReviewersCount:    t.Settings.ReviewersCount,
+		Strategy:          domain.ReviewerStrategy(t.Settings.Strategy),
+		MinPoolSize:       t.Settings.MinPoolSize,
+		SlackWebhookURL:   t.Settings.SlackWebhookURL,
+		RequiredApprovals: t.Settings.RequiredApprovals,
+	}
+	for _, f := range t.Settings.FallbackTeams {
+		settings.FallbackTeams = append(settings.FallbackTeams, domain.TeamName(f))
+	}
+	return settings, true
+}
+
+func NewUser(u domain.User) User {
+	return User{
+		UserID:         string(u.ID),
+		Username:       u.Username,
+		TeamName:       string(u.TeamName),
+		IsActive:       u.IsActive,
+		SlackHandle:    u.SlackHandle,
+		MaxOpenReviews: u.MaxOpenReviews,
+	}
+}
+
+func (u User) Domain() domain.User {
+	return domain.User{
+		ID:             domain.UserID(u.UserID),
+		Username:       u.Username,
+		TeamName:       domain.TeamName(u.TeamName),
+		IsActive:       u.IsActive,
+		SlackHandle:    u.SlackHandle,
+		MaxOpenReviews: u.MaxOpenReviews,
+	}
+}
+
+func NewPullRequest(pr domain.PullRequest) PullRequest {
+	res := PullRequest{
+		PullRequestID:   string(pr.ID),
+		PullRequestName: pr.Name,
+		AuthorID:        string(pr.AuthorID),
+		Status:          string(pr.Status),
+		CreatedAt:       pr.CreatedAt,
+		MergedAt:        pr.MergedAt,
+		ClosedAt:        pr.ClosedAt,
+		ClosedFrom:      string(pr.ClosedFrom),
+		Reviewers:       make([]Reviewer, 0, len(pr.AssignedReviewers)),
+	}
+	for _, id := range pr.AssignedReviewers {
+		res.Reviewers = append(res.Reviewers, Reviewer{
+			UserID:       string(id),
+			FallbackTeam: string(pr.FallbackReviewers[id]),
+			MatchedRule:  pr.MatchedRules[id],
+			ReviewState:  string(pr.ReviewState(id)),
+		})
+	}
+	return res
+}
+
+func (pr PullRequest) Domain() domain.PullRequest {
+	res := domain.PullRequest{
+		ID:         domain.PullRequestID(pr.PullRequestID),
+		Name:       pr.PullRequestName,
+		AuthorID:   domain.UserID(pr.AuthorID),
+		Status:     domain.PRStatus(pr.Status),
+		CreatedAt:  pr.CreatedAt,
+		MergedAt:   pr.MergedAt,
+		ClosedAt:   pr.ClosedAt,
+		ClosedFrom: domain.PRStatus(pr.ClosedFrom),
+	}
+	for _, r := range pr.Reviewers {
+		id := domain.UserID(r.UserID)
+		res.AssignedReviewers = append(res.AssignedReviewers, id)
+		if r.FallbackTeam != "" {
+			if res.FallbackReviewers == nil {
+				res.FallbackReviewers = make(map[domain.UserID]domain.TeamName)
+			}
+			res.FallbackReviewers[id] = domain.TeamName(r.FallbackTeam)
+		}
+		if r.MatchedRule != "" {
+			if res.MatchedRules == nil {
+				res.MatchedRules = make(map[domain.UserID]string)
+			}
+			res.MatchedRules[id] = r.MatchedRule
+		}
+		if state := domain.ReviewState(r.ReviewState); state.Valid() {
+			if res.ReviewStates == nil {
+				res.ReviewStates = make(map[domain.UserID]domain.ReviewState)
+			}
+			res.ReviewStates[id] = state
+		}
+	}
+	return res
+}
@@ -0,0 +1,85 @@
+package snapshot
+
+import (
+	"bytes"
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"pr-reviewer-service/internal/domain"
+)
+
+func TestEncodeDecodeRoundTrip(t *testing.T) {
+	merged := time.Date(2025, 3, 1, 12, 0, 0, 0, time.UTC)
+	pr := domain.PullRequest{
+		ID:                "pr-1",
+		Name:              "Fix login",
+		AuthorID:          "u1",
+		Status:            domain.PRStatusMerged,
+		CreatedAt:         merged.Add(-time.Hour),
+		MergedAt:          &merged,
+		AssignedReviewers: []domain.UserID{"u2", "u3"},
+		FallbackReviewers: map[domain.UserID]domain.TeamName{"u3": "platform"},
+		MatchedRules:      map[domain.UserID]string{"u2": "/auth/"},
+		ReviewStates:      map[domain.UserID]domain.ReviewState{"u2": domain.ReviewStateApproved},
+	}
+	in := Snapshot{FormatVersion: FormatVersion, PullRequests: []PullRequest{NewPullRequest(pr)}}
+
+	var buf bytes.Buffer
+	if err := Encode(&buf, in); err != nil {
+		t.Fatalf("Encode returned error: %v", err)
+	}
+	out, err := Decode(&buf)
+	if err != nil {
+		t.Fatalf("Decode returned error: %v", err)
+	}
+
+	if got := out.PullRequests[0].Domain(); !reflect.DeepEqual(got, pr) {
+		t.Fatalf("round trip mismatch:\n got %+v\nwant %+v", got, pr)
+	}
+}
+
+func TestDecodeRejectsOtherVersions(t *testing.T) {
+	_, err := Decode(strings.NewReader(`{"format_version": 2}`))
+	if !errors.Is(err, ErrUnsupportedVersion) {
+		t.Fatalf("expected ErrUnsupportedVersion, got %v", err)
+	}
+}
+
+func TestValidate(t *testing.T) {
+	s := Snapshot{
+		Teams: []Team{{TeamName: "backend", Settings: &Settings{Strategy: "coin_flip"}}, {TeamName: "backend"}},
+		Users: []User{{UserID: "u1", TeamName: "backend"}, {UserID: "u2", TeamName: "frontend"}},
+		PullRequests: []PullRequest{{
+			PullRequestID: "pr-1",
+			AuthorID:      "u1",
+			Status:        "DONE",
+			Reviewers:     []Reviewer{{UserID: "u9", ReviewState: "LGTM"}},
+		}},
+	}
+
+	conflicts, missing := Validate(s)
+
+	var got []string
+	for _, c := range conflicts {
+		got = append(got, c.String())
+	}
+	want := []string{
+		"team backend: duplicate team",
+		`team backend: unknown strategy "coin_flip"`,
+		`pull_request pr-1: unknown status "DONE"`,
+		`pull_request pr-1 reviewer u9: unknown review_state "LGTM"`,
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("conflicts:\n got %q\nwant %q", got, want)
+	}
+
+	if !reflect.DeepEqual(missing.Teams, map[domain.TeamName][]string{"frontend": {"user u2 team_name"}}) {
+		t.Fatalf("unexpected missing teams: %v", missing.Teams)
+	}
+	if !reflect.DeepEqual(missing.Users, map[domain.UserID][]string{"u9": {"pull_request pr-1 reviewer u9"}}) {
+		t.Fatalf("unexpected missing users: %v", missing.Users)
+	}
+}
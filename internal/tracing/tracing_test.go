@@ -0,0 +1,41 @@
+package tracing
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"go.opentelemetry.io/otel"
+)
+
+func TestSetupConsoleFile(t *testing.T) {
+	ctx := context.Background()
+	path := filepath.Join(t.TempDir(), "traces.json")
+
+	shutdown, err := Setup(ctx, Config{Exporter: ExporterConsole, File: path, ServiceName: "test-service"})
+	if err != nil {
+		t.Fatalf("Setup returned error: %v", err)
+	}
+
+	_, span := otel.Tracer("test").Start(ctx, "work")
+	span.End()
+	if err := shutdown(ctx); err != nil {
+		t.Fatalf("shutdown returned error: %v", err)
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("read traces: %v", err)
+	}
+	if !strings.Contains(string(data), `"Name":"work"`) || !strings.Contains(string(data), "test-service") {
+		t.Fatalf("trace file lacks the span: %s", data)
+	}
+}
+
+func TestSetupRejectsUnknownExporter(t *testing.T) {
+	if _, err := Setup(context.Background(), Config{Exporter: "zipkin"}); err == nil {
+		t.Fatalf("expected an error for an unknown exporter")
+	}
+}
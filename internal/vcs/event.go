@@ -0,0 +1,41 @@
+package vcs
+
+import (
+	"errors"
+
+	"pr-reviewer-service/internal/domain"
+)
+
+var (
+	ErrInvalidSignature = errors.New("invalid webhook signature")
+	ErrUnknownIdentity  = errors.New("vcs login is not mapped to a user")
+	ErrInvalidPayload   = errors.New("invalid webhook payload")
+)
+
+type Provider string
+
+const (
+	ProviderGitHub Provider = "github"
+	ProviderGitLab Provider = "gitlab"
+)
+
+type Action string
+
+const (
+	ActionOpened   Action = "opened"
+	ActionMerged   Action = "merged"
+	ActionClosed   Action = "closed"
+	ActionReopened Action = "reopened"
+	ActionReady    Action = "ready_for_review"
+)
+
+// Event is a provider-agnostic pull request lifecycle event.
+type Event struct {
+	Provider      Provider
+	Action        Action
+	PullRequestID domain.PullRequestID
+	Title         string
+	AuthorID      domain.UserID
+	// Draft is set for opened events of draft pull requests.
+	Draft bool
+}
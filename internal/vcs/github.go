@@ -0,0 +1,90 @@
+package vcs
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"pr-reviewer-service/internal/domain"
+)
+
+type githubPullRequestPayload struct {
+	Action      string `json:"action"`
+	Number      int    `json:"number"`
+	PullRequest struct {
+		Title  string `json:"title"`
+		Merged bool   `json:"merged"`
+		Draft  bool   `json:"draft"`
+		User   struct {
+			Login string `json:"login"`
+		} `json:"user"`
+	} `json:"pull_request"`
+	Repository struct {
+		FullName string `json:"full_name"`
+	} `json:"repository"`
+}
+
+// VerifyGitHubSignature checks the X-Hub-Signature-256 header against body.
+func VerifyGitHubSignature(secret, body []byte, header string) error {
+	sig, ok := strings.CutPrefix(header, "sha256=")
+	if !ok {
+		return ErrInvalidSignature
+	}
+
+	got, err := hex.DecodeString(sig)
+	if err != nil {
+		return ErrInvalidSignature
+	}
+
+	mac := hmac.New(sha256.New, secret)
+	mac.Write(body)
+	if !hmac.Equal(got, mac.Sum(nil)) {
+		return ErrInvalidSignature
+	}
+	return nil
+}
+
+// ParseGitHubPullRequest converts a pull_request webhook payload into an Event.
+// It returns ok=false for actions the service does not react to.
+func ParseGitHubPullRequest(body []byte, ids IdentityMap) (Event, bool, error) {
+	var p githubPullRequestPayload
+	if err := json.Unmarshal(body, &p); err != nil {
+		return Event{}, false, fmt.Errorf("%w: %v", ErrInvalidPayload, err)
+	}
+	if p.Repository.FullName == "" || p.Number == 0 {
+		return Event{}, false, fmt.Errorf("%w: repository and number are required", ErrInvalidPayload)
+	}
+
+	var action Action
+	switch {
+	case p.Action == "opened":
+		action = ActionOpened
+	case p.Action == "closed" && p.PullRequest.Merged:
+		action = ActionMerged
+	case p.Action == "closed":
+		action = ActionClosed
+	case p.Action == "reopened":
+		action = ActionReopened
+	case p.Action == "ready_for_review":
+		action = ActionReady
+	default:
+		return Event{}, false, nil
+	}
+
+	authorID, err := ids.Resolve(ProviderGitHub, p.PullRequest.User.Login)
+	if err != nil {
+		return Event{}, false, err
+	}
+
+	return Event{
+		Provider:      ProviderGitHub,
+		Action:        action,
+		PullRequestID: domain.PullRequestID(fmt.Sprintf("%s#%d", p.Repository.FullName, p.Number)),
+		Title:         p.PullRequest.Title,
+		AuthorID:      authorID,
+		Draft:         action == ActionOpened && p.PullRequest.Draft,
+	}, true, nil
+}
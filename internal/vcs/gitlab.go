@@ -0,0 +1,89 @@
+package vcs
+
+import (
+	"crypto/subtle"
+	"encoding/json"
+	"fmt"
+
+	"pr-reviewer-service/internal/domain"
+)
+
+type gitlabMergeRequestPayload struct {
+	ObjectKind string `json:"object_kind"`
+	User       struct {
+		Username string `json:"username"`
+	} `json:"user"`
+	Project struct {
+		PathWithNamespace string `json:"path_with_namespace"`
+	} `json:"project"`
+	ObjectAttributes struct {
+		IID    int    `json:"iid"`
+		Title  string `json:"title"`
+		Action string `json:"action"`
+		Draft  bool   `json:"draft"`
+	} `json:"object_attributes"`
+	Changes struct {
+		Draft *struct {
+			Previous bool `json:"previous"`
+			Current  bool `json:"current"`
+		} `json:"draft"`
+	} `json:"changes"`
+}
+
+// VerifyGitLabToken checks the X-Gitlab-Token header against the configured secret.
+func VerifyGitLabToken(secret []byte, header string) error {
+	if len(secret) == 0 || subtle.ConstantTimeCompare(secret, []byte(header)) != 1 {
+		return ErrInvalidSignature
+	}
+	return nil
+}
+
+// ParseGitLabMergeRequest converts a Merge Request Hook payload into an Event.
+// It returns ok=false for actions the service does not react to.
+func ParseGitLabMergeRequest(body []byte, ids IdentityMap) (Event, bool, error) {
+	var p gitlabMergeRequestPayload
+	if err := json.Unmarshal(body, &p); err != nil {
+		return Event{}, false, fmt.Errorf("%w: %v", ErrInvalidPayload, err)
+	}
+	if p.ObjectKind != "merge_request" {
+		return Event{}, false, nil
+	}
+	if p.Project.PathWithNamespace == "" || p.ObjectAttributes.IID == 0 {
+		return Event{}, false, fmt.Errorf("%w: project and iid are required", ErrInvalidPayload)
+	}
+
+	ev := Event{
+		Provider:      ProviderGitLab,
+		PullRequestID: domain.PullRequestID(fmt.Sprintf("%s!%d", p.Project.PathWithNamespace, p.ObjectAttributes.IID)),
+		Title:         p.ObjectAttributes.Title,
+	}
+
+	switch p.ObjectAttributes.Action {
+	case "open":
+		// GitLab only reports the numeric author id, but for "open" the
+		// triggering user is the author.
+		authorID, err := ids.Resolve(ProviderGitLab, p.User.Username)
+		if err != nil {
+			return Event{}, false, err
+		}
+		ev.Action = ActionOpened
+		ev.AuthorID = authorID
+		ev.Draft = p.ObjectAttributes.Draft
+	case "merge":
+		ev.Action = ActionMerged
+	case "close":
+		ev.Action = ActionClosed
+	case "reopen":
+		ev.Action = ActionReopened
+	case "update":
+		// Marking a draft ready arrives as a generic update.
+		if d := p.Changes.Draft; d == nil || !d.Previous || d.Current {
+			return Event{}, false, nil
+		}
+		ev.Action = ActionReady
+	default:
+		return Event{}, false, nil
+	}
+
+	return ev, true, nil
+}
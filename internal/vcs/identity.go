@@ -0,0 +1,34 @@
+package vcs
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+
+	"pr-reviewer-service/internal/domain"
+)
+
+// IdentityMap maps provider logins to service users, e.g.
+// {"github": {"octocat": "u1"}}.
+type IdentityMap map[Provider]map[string]domain.UserID
+
+func LoadIdentityMap(path string) (IdentityMap, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("read identity map: %w", err)
+	}
+
+	var m IdentityMap
+	if err := json.Unmarshal(data, &m); err != nil {
+		return nil, fmt.Errorf("parse identity map: %w", err)
+	}
+	return m, nil
+}
+
+func (m IdentityMap) Resolve(provider Provider, login string) (domain.UserID, error) {
+	id, ok := m[provider][login]
+	if !ok {
+		return "", fmt.Errorf("%w: %s login %q", ErrUnknownIdentity, provider, login)
+	}
+	return id, nil
+}
@@ -0,0 +1,160 @@
+package webhooks
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"strconv"
+	"time"
+
+	"pr-reviewer-service/internal/domain"
+)
+
+const (
+	SignatureHeader = "X-Webhook-Signature-256"
+	EventHeader     = "X-Webhook-Event"
+	DeliveryHeader  = "X-Webhook-Delivery"
+)
+
+type envelope struct {
+	ID        int64                  `json:"id"`
+	Type      domain.OutboxEventType `json:"type"`
+	CreatedAt time.Time              `json:"created_at"`
+	Data      json.RawMessage        `json:"data"`
+}
+
+// Dispatcher moves outbox events to subscribers. Failed deliveries are retried
+// with exponential backoff and end up in the dead-letter list after
+// MaxAttempts.
+type Dispatcher struct {
+	Repo        domain.WebhookRepository
+	Client      *http.Client
+	MaxAttempts int
+	BaseBackoff time.Duration
+	MaxBackoff  time.Duration
+	BatchSize   int
+	Lease       time.Duration
+	Now         func() time.Time
+}
+
+func NewDispatcher(repo domain.WebhookRepository) *Dispatcher {
+	return &Dispatcher{
+		Repo:        repo,
+		Client:      &http.Client{Timeout: 10 * time.Second},
+		MaxAttempts: 8,
+		BaseBackoff: 5 * time.Second,
+		MaxBackoff:  time.Hour,
+		BatchSize:   100,
+		Lease:       time.Minute,
+		Now:         func() time.Time { return time.Now().UTC() },
+	}
+}
+
+func (d *Dispatcher) Run(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		if err := d.RunOnce(ctx); err != nil && ctx.Err() == nil {
+			log.Printf("webhook dispatch failed: %v", err)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+func (d *Dispatcher) RunOnce(ctx context.Context) error {
+	if _, err := d.Repo.EnqueueDeliveries(ctx, d.BatchSize); err != nil {
+		return err
+	}
+
+	now := d.Now()
+	due, err := d.Repo.ClaimDueDeliveries(ctx, now, now.Add(d.Lease), d.BatchSize)
+	if err != nil {
+		return err
+	}
+
+	for _, delivery := range due {
+		if err := d.deliver(ctx, delivery); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (d *Dispatcher) deliver(ctx context.Context, delivery domain.WebhookDelivery) error {
+	sendErr := d.send(ctx, delivery)
+	if sendErr == nil {
+		return d.Repo.MarkDelivered(ctx, delivery.ID)
+	}
+
+	attempts := delivery.Attempts + 1
+	dead := attempts >= d.MaxAttempts
+	return d.Repo.MarkFailed(ctx, delivery.ID, attempts, d.Now().Add(d.backoff(attempts)), sendErr.Error(), dead)
+}
+
+func (d *Dispatcher) send(ctx context.Context, delivery domain.WebhookDelivery) error {
+	body, err := json.Marshal(envelope{
+		ID:        delivery.Event.ID,
+		Type:      delivery.Event.Type,
+		CreatedAt: delivery.Event.CreatedAt,
+		Data:      delivery.Event.Payload,
+	})
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, delivery.URL, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(EventHeader, string(delivery.Event.Type))
+	req.Header.Set(DeliveryHeader, strconv.FormatInt(delivery.ID, 10))
+	req.Header.Set(SignatureHeader, Sign([]byte(delivery.Secret), body))
+
+	resp, err := d.Client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		_ = resp.Body.Close()
+	}()
+	_, _ = io.Copy(io.Discard, resp.Body)
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return fmt.Errorf("subscriber responded with status %d", resp.StatusCode)
+	}
+	return nil
+}
+
+// backoff returns BaseBackoff * 2^(attempts-1), capped at MaxBackoff.
+func (d *Dispatcher) backoff(attempts int) time.Duration {
+	delay := d.BaseBackoff
+	for i := 1; i < attempts; i++ {
+		delay *= 2
+		if delay >= d.MaxBackoff {
+			return d.MaxBackoff
+		}
+	}
+	return delay
+}
+
+// Sign returns the signature header value subscribers use to verify a body.
+func Sign(secret, body []byte) string {
+	mac := hmac.New(sha256.New, secret)
+	mac.Write(body)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
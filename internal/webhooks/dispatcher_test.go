@@ -0,0 +1,173 @@
+package webhooks
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"pr-reviewer-service/internal/domain"
+)
+
+type fakeWebhookRepo struct {
+	pending   []domain.WebhookDelivery
+	delivered []int64
+	failed    map[int64]domain.WebhookDelivery
+}
+
+func (r *fakeWebhookRepo) AppendOutbox(ctx context.Context, events []domain.OutboxEvent) error {
+	return nil
+}
+
+func (r *fakeWebhookRepo) CreateSubscription(ctx context.Context, sub domain.WebhookSubscription) (domain.WebhookSubscription, error) {
+	return sub, nil
+}
+
+func (r *fakeWebhookRepo) ListSubscriptions(ctx context.Context) ([]domain.WebhookSubscription, error) {
+	return nil, nil
+}
+
+func (r *fakeWebhookRepo) DeleteSubscription(ctx context.Context, id int64) error {
+	return nil
+}
+
+func (r *fakeWebhookRepo) EnqueueDeliveries(ctx context.Context, limit int) (int, error) {
+	return 0, nil
+}
+
+func (r *fakeWebhookRepo) ClaimDueDeliveries(ctx context.Context, now, leaseUntil time.Time, limit int) ([]domain.WebhookDelivery, error) {
+	due := r.pending
+	r.pending = nil
+	return due, nil
+}
+
+func (r *fakeWebhookRepo) MarkDelivered(ctx context.Context, id int64) error {
+	r.delivered = append(r.delivered, id)
+	return nil
+}
+
+func (r *fakeWebhookRepo) MarkFailed(
+	ctx context.Context,
+	id int64,
+	attempts int,
+	nextAttemptAt time.Time,
+	lastError string,
+	dead bool,
+) error {
+	status := domain.DeliveryPending
+	if dead {
+		status = domain.DeliveryDead
+	}
+	r.failed[id] = domain.WebhookDelivery{
+		ID:            id,
+		Attempts:      attempts,
+		NextAttemptAt: nextAttemptAt,
+		LastError:     lastError,
+		Status:        status,
+	}
+	return nil
+}
+
+func (r *fakeWebhookRepo) ListDeadLetters(ctx context.Context) ([]domain.WebhookDelivery, error) {
+	return nil, nil
+}
+
+func (r *fakeWebhookRepo) Redrive(ctx context.Context, id int64, at time.Time) error {
+	return nil
+}
+
+func TestDispatcher_SignsAndDelivers(t *testing.T) {
+	var gotSignature, gotEvent string
+	var gotBody []byte
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotSignature = r.Header.Get(SignatureHeader)
+		gotEvent = r.Header.Get(EventHeader)
+		gotBody, _ = io.ReadAll(r.Body)
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer srv.Close()
+
+	repo := &fakeWebhookRepo{
+		failed: make(map[int64]domain.WebhookDelivery),
+		pending: []domain.WebhookDelivery{{
+			ID:     1,
+			URL:    srv.URL,
+			Secret: "s3cret",
+			Event: domain.OutboxEvent{
+				ID:      10,
+				Type:    domain.OutboxPRMerged,
+				Payload: []byte(`{"pull_request_id":"pr-1"}`),
+			},
+		}},
+	}
+
+	d := NewDispatcher(repo)
+	d.Client = srv.Client()
+	if err := d.RunOnce(context.Background()); err != nil {
+		t.Fatalf("RunOnce: %v", err)
+	}
+
+	if len(repo.delivered) != 1 || repo.delivered[0] != 1 {
+		t.Fatalf("expected delivery 1 to be marked delivered, got %v", repo.delivered)
+	}
+	if gotEvent != string(domain.OutboxPRMerged) {
+		t.Fatalf("unexpected event header %q", gotEvent)
+	}
+	if want := Sign([]byte("s3cret"), gotBody); gotSignature != want {
+		t.Fatalf("signature mismatch: got %q want %q", gotSignature, want)
+	}
+}
+
+func TestDispatcher_RetriesWithBackoffThenDeadLetters(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusInternalServerError)
+	}))
+	defer srv.Close()
+
+	now := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+	repo := &fakeWebhookRepo{failed: make(map[int64]domain.WebhookDelivery)}
+
+	d := NewDispatcher(repo)
+	d.Client = srv.Client()
+	d.MaxAttempts = 3
+	d.BaseBackoff = time.Second
+	d.Now = func() time.Time { return now }
+
+	delivery := domain.WebhookDelivery{
+		ID:    7,
+		URL:   srv.URL,
+		Event: domain.OutboxEvent{ID: 1, Type: domain.OutboxPRCreated, Payload: []byte(`{}`)},
+	}
+
+	wantBackoff := []time.Duration{time.Second, 2 * time.Second, 4 * time.Second}
+	for i, backoff := range wantBackoff {
+		repo.pending = []domain.WebhookDelivery{delivery}
+		if err := d.RunOnce(context.Background()); err != nil {
+			t.Fatalf("RunOnce: %v", err)
+		}
+
+		got := repo.failed[delivery.ID]
+		if got.Attempts != i+1 {
+			t.Fatalf("attempt %d: expected attempts %d, got %d", i+1, i+1, got.Attempts)
+		}
+		if !got.NextAttemptAt.Equal(now.Add(backoff)) {
+			t.Fatalf("attempt %d: expected next attempt at %v, got %v", i+1, now.Add(backoff), got.NextAttemptAt)
+		}
+
+		wantStatus := domain.DeliveryPending
+		if i == len(wantBackoff)-1 {
+			wantStatus = domain.DeliveryDead
+		}
+		if got.Status != wantStatus {
+			t.Fatalf("attempt %d: expected status %s, got %s", i+1, wantStatus, got.Status)
+		}
+
+		delivery.Attempts = got.Attempts
+	}
+
+	if len(repo.delivered) != 0 {
+		t.Fatalf("expected no successful deliveries, got %v", repo.delivered)
+	}
+}